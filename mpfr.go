@@ -5,22 +5,91 @@
 
 // Package mpfr provides Go bindings for the MPFR multiple-precision
 // floating-point library.
+//
+// A Float must not be used by more than one goroutine at a time. Distinct Floats
+// may be used concurrently only if MPFR was built with thread-local storage; see
+// HasTLS.
 package mpfr
 
 /*
 #cgo LDFLAGS: -lmpfr -lgmp
+#include <stdint.h> // must precede mpfr.h to declare the intmax_t functions
 #include <mpfr.h>
 #include <stdlib.h>
+#include <string.h>
 
 // For now, we’ll call MPFR functions directly
 // from Go via cgo.
+
+// gompfr_add_many sets r = r + xs[0] + ... + xs[n-1], rounding after each addition,
+// so that a whole slice is summed with a single cgo call. xs holds shallow copies
+// of the operands, which share their significands and must not alias r.
+static void gompfr_add_many(mpfr_ptr r, const __mpfr_struct *xs, size_t n, mpfr_rnd_t rnd) {
+	for (size_t i = 0; i < n; i++) {
+		mpfr_add(r, r, &xs[i], rnd);
+	}
+}
+
+// gompfr_mul_many is the multiplicative counterpart of gompfr_add_many.
+static void gompfr_mul_many(mpfr_ptr r, const __mpfr_struct *xs, size_t n, mpfr_rnd_t rnd) {
+	for (size_t i = 0; i < n; i++) {
+		mpfr_mul(r, r, &xs[i], rnd);
+	}
+}
+
+// mpfr_sinpi and mpfr_cospi appeared in MPFR 4.2. With older libraries the
+// wrappers below are never called: SinPi and CosPi fall back to trigPi instead.
+#if MPFR_VERSION >= MPFR_VERSION_NUM(4,2,0)
+#define HAVE_MPFR_TRIGPI 1
+static int gompfr_sinpi(mpfr_ptr r, mpfr_srcptr x, mpfr_rnd_t rnd) { return mpfr_sinpi(r, x, rnd); }
+static int gompfr_cospi(mpfr_ptr r, mpfr_srcptr x, mpfr_rnd_t rnd) { return mpfr_cospi(r, x, rnd); }
+#else
+#define HAVE_MPFR_TRIGPI 0
+static int gompfr_sinpi(mpfr_ptr r, mpfr_srcptr x, mpfr_rnd_t rnd) { abort(); return 0; }
+static int gompfr_cospi(mpfr_ptr r, mpfr_srcptr x, mpfr_rnd_t rnd) { abort(); return 0; }
+#endif
+
+// mpfr.h declares the decimal64 functions only under MPFR_WANT_DECIMAL_FLOATS, and
+// the library defines them only when configured with --enable-decimal-float, so
+// they are declared weak here and gompfr_have_decimal64 checks that they were linked.
+// The value crosses into Go as its 64-bit encoding.
+#if defined(__DEC64_MANT_DIG__)
+int mpfr_set_decimal64(mpfr_ptr, _Decimal64, mpfr_rnd_t) __attribute__((weak));
+_Decimal64 mpfr_get_decimal64(mpfr_srcptr, mpfr_rnd_t) __attribute__((weak));
+static int gompfr_have_decimal64(void) {
+	return mpfr_buildopt_decimal_p() && mpfr_set_decimal64 != 0 && mpfr_get_decimal64 != 0;
+}
+static uint64_t gompfr_get_decimal64_bits(mpfr_srcptr x, mpfr_rnd_t rnd) {
+	_Decimal64 d = mpfr_get_decimal64(x, rnd);
+	uint64_t u;
+	memcpy(&u, &d, sizeof u);
+	return u;
+}
+static int gompfr_set_decimal64_bits(mpfr_ptr r, uint64_t u, mpfr_rnd_t rnd) {
+	_Decimal64 d;
+	memcpy(&d, &u, sizeof d);
+	return mpfr_set_decimal64(r, d, rnd);
+}
+#else
+static int gompfr_have_decimal64(void) { return 0; }
+static uint64_t gompfr_get_decimal64_bits(mpfr_srcptr x, mpfr_rnd_t rnd) { abort(); return 0; }
+static int gompfr_set_decimal64_bits(mpfr_ptr r, uint64_t u, mpfr_rnd_t rnd) { abort(); return 0; }
+#endif
 */
 import "C"
 import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
 	"math"
 	"math/big"
+	"math/bits"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"unsafe"
 )
 
@@ -28,10 +97,21 @@ import (
 // The zero value for Float is *not* valid until initialized
 // (similar to how the GMP code works).
 // Float represents a multiple-precision floating-point number.
+//
+// Operands may alias: the receiver may also be passed as an argument (for example
+// f.Add(f) or f.Atan2(f, x)), because MPFR reads all inputs before writing the result.
 type Float struct {
 	mpfr         C.mpfr_t // Use C.mpfr_t directly (array of 1 struct)
 	init         bool
 	RoundingMode Rnd
+	strCache     *stringCache // see CachedString; nil while f is dirty
+}
+
+// stringCache holds the last CachedString rendering and the rounding mode it was
+// rendered with.
+type stringCache struct {
+	s   string
+	rnd Rnd
 }
 
 // finalizer is called by the garbage collector when there are no
@@ -54,13 +134,20 @@ func (f *Float) doinit() {
 	// Initialize the mpfr_t struct
 	C.mpfr_init(&f.mpfr[0])
 
-	// set the default rounding mode
-	f.RoundingMode = RoundToNearest
+	// inherit MPFR's global default rounding mode (RoundToNearest unless changed with SetDefaultRound)
+	f.RoundingMode = Rnd(C.mpfr_get_default_rounding_mode())
 
 	// Set the finalizer to clean up the memory when the object is garbage-collected
 	runtime.SetFinalizer(f, finalizer)
 }
 
+// markDirty records that f's value or precision is about to change by dropping
+// its CachedString rendering. Every method that writes f's mpfr_t calls it, as
+// does every function that writes a caller's Float other than the receiver.
+func (f *Float) markDirty() {
+	f.strCache = nil
+}
+
 // Clear deallocates the native mpfr_t. After calling Clear,
 // the Float must not be used again.
 func (f *Float) Clear() {
@@ -69,6 +156,7 @@ func (f *Float) Clear() {
 	}
 	C.mpfr_clear(&f.mpfr[0]) // Pass a pointer to the first element
 	f.init = false
+	f.strCache = nil
 }
 
 // Rnd is the type for MPFR rounding modes.
@@ -85,6 +173,7 @@ const (
 )
 
 // NewFloat allocates and returns a new Float set to 0.0 with MPFR’s default precision.
+// Its RoundingMode is taken from the global default, see SetDefaultRound.
 func NewFloat() *Float {
 	f := &Float{}
 	f.doinit()
@@ -108,17 +197,96 @@ func (f *Float) GetFloat64() float64 {
 
 // SetString parses a string into f.
 func (f *Float) SetString(s string, base int) error {
+	return f.SetStringRound(s, base, f.RoundingMode)
+}
+
+// SetStringRound parses a string in the given base into f, rounding the result with rnd
+// instead of the receiver's RoundingMode. The receiver's RoundingMode is left unchanged.
+// It returns ErrInvalidString if s is not a valid number in that base.
+func (f *Float) SetStringRound(s string, base int, rnd Rnd) error {
 	f.doinit()
+	f.markDirty()
 	cstr := C.CString(s)
 	defer C.free(unsafe.Pointer(cstr))
-	ret := C.mpfr_set_str(&f.mpfr[0], cstr, C.int(base), C.mpfr_rnd_t(f.RoundingMode))
+	ret := C.mpfr_set_str(&f.mpfr[0], cstr, C.int(base), C.mpfr_rnd_t(rnd))
 	if ret != 0 {
 		return ErrInvalidString
 	}
 	return nil
 }
 
+// ParseFloats parses each string in strs in the given base into a new Float with precision prec,
+// rounding with rnd. The returned Floats keep rnd as their RoundingMode.
+//
+// If an entry cannot be parsed, ParseFloats returns nil and an error wrapping ErrInvalidString
+// that identifies the index of the first bad entry.
+func ParseFloats(strs []string, base int, prec uint, rnd Rnd) ([]*Float, error) {
+	out := make([]*Float, len(strs))
+	for i, s := range strs {
+		f := NewFloatWithPrec(prec)
+		f.SetRoundMode(rnd)
+		if err := f.SetString(s, base); err != nil {
+			return nil, fmt.Errorf("ParseFloats: entry %d (%q): %w", i, s, err)
+		}
+		out[i] = f
+	}
+	return out, nil
+}
+
+// ReadFloats reads whitespace-separated numbers in the given base from r and parses
+// each into a new Float with precision prec, rounding with rnd, as ParseFloats does.
+// Numbers may be split across any mix of spaces, tabs and lines, and each may be
+// arbitrarily long.
+//
+// If a token cannot be parsed, ReadFloats returns nil and an error wrapping
+// ErrInvalidString that identifies the token's index and its 1-based line number.
+// An error from r other than io.EOF is returned as is.
+func ReadFloats(r io.Reader, base int, prec uint, rnd Rnd) ([]*Float, error) {
+	br := bufio.NewReader(r)
+	var out []*Float
+	for line := 1; ; line++ {
+		text, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		for _, tok := range strings.Fields(text) {
+			f := NewFloatWithPrec(prec)
+			f.SetRoundMode(rnd)
+			if perr := f.SetString(tok, base); perr != nil {
+				return nil, fmt.Errorf("ReadFloats: token %d (%q) on line %d: %w", len(out), tok, line, perr)
+			}
+			out = append(out, f)
+		}
+		if err == io.EOF {
+			return out, nil
+		}
+	}
+}
+
+// Parse parses the base-10 string s into a new Float with precision prec, rounded to nearest.
+// It returns ErrInvalidString if s is not a valid number.
+func Parse(s string, prec uint) (*Float, error) {
+	f := NewFloatWithPrec(prec)
+	if err := f.SetString(s, 10); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// MustParse is like Parse but panics if s cannot be parsed. It simplifies
+// initialization of package-level variables holding known-good constants:
+//
+//	var sqrt2 = mpfr.MustParse("1.41421356237309504880168872420969807856967187537694", 170)
+func MustParse(s string, prec uint) *Float {
+	f, err := Parse(s, prec)
+	if err != nil {
+		panic("MustParse(" + strconv.Quote(s) + "): " + err.Error())
+	}
+	return f
+}
+
 // String returns f as a base-10 string representation.
+// All digits needed to represent f at its precision are included; use StringN to limit the output.
 func (f *Float) String() string {
 	f.doinit()
 
@@ -145,14 +313,303 @@ func (f *Float) String() string {
 	return "0." + mantissa
 }
 
+// StringN returns f as a base-10 string with at most digits significant digits,
+// rounded with the receiver's RoundingMode. This keeps logs readable for Floats whose
+// full representation would run to thousands of digits. If digits <= 0, all digits
+// needed at f's precision are included, as in String. Unlike String, StringN places
+// the sign of negative values in front ("-1.50") and writes NaN and the infinities as
+// "NaN", "+Inf" and "-Inf"; the layout of non-negative finite values is the same.
+//
+// Example Usage:
+//
+//	pi := NewFloatWithPrec(10000)
+//	pi.Acos(FromInt(-1))
+//	pi.StringN(10) // "3.141592654"
+func (f *Float) StringN(digits int) string {
+	if digits < 0 {
+		digits = 0
+	}
+	return f.decimalString(digits)
+}
+
+// CachedString returns the same text as String, but remembers it so that
+// formatting an unchanged Float again skips the conversion. Every setter and
+// arithmetic method marks f dirty, dropping the cached text, and so does
+// changing its RoundingMode; the next call renders f afresh.
+//
+// The cache holds one string, about as many bytes as String returns, for as long
+// as f lives or until f next changes. It pays off only for values that are
+// computed once and then formatted many times, as in tables and logs; for a Float
+// that keeps changing, use String.
+//
+// The dirty flag is only set through this package's methods and functions. Code
+// that modifies f's mpfr_t by other means, such as its own cgo calls through an
+// unsafe pointer, must not rely on CachedString.
+func (f *Float) CachedString() string {
+	f.doinit()
+	if c := f.strCache; c != nil && c.rnd == f.RoundingMode {
+		return c.s
+	}
+	s := f.String()
+	f.strCache = &stringCache{s: s, rnd: f.RoundingMode}
+	return s
+}
+
+// CompactString returns the shortest decimal string that reads back as f at f's
+// precision, laid out like strconv.FormatFloat with format 'g' and precision -1:
+// scientific notation ("1e-07", "1e+20", "3.3333333333333335e+06") when the
+// decimal exponent is below -4 or at least 6, and positional notation ("0.0001",
+// "-0.5", "123456.7") otherwise. The digits never carry zero padding, unlike the
+// fixed-point String. Zeros print as "0" or "-0", and infinities and NaN as
+// "+Inf", "-Inf" and "NaN".
+//
+// For a 53-bit Float the result matches strconv.FormatFloat(x, 'g', -1, 64), which
+// makes it a readable default for logs and messages; use String when every digit
+// of the precision is wanted.
+func (f *Float) CompactString() string {
+	f.doinit()
+	neg := C.mpfr_signbit(&f.mpfr[0]) != 0
+	sign := ""
+	if neg {
+		sign = "-"
+	}
+	switch {
+	case C.mpfr_nan_p(&f.mpfr[0]) != 0:
+		return "NaN"
+	case C.mpfr_inf_p(&f.mpfr[0]) != 0:
+		if neg {
+			return "-Inf"
+		}
+		return "+Inf"
+	case C.mpfr_zero_p(&f.mpfr[0]) != 0:
+		return sign + "0"
+	}
+
+	digits, dp := f.shortestDecimal()
+	var sb strings.Builder
+	sb.WriteString(sign)
+	if exp := dp - 1; exp < -4 || exp >= 6 {
+		sb.WriteString(digits[:1])
+		if len(digits) > 1 {
+			sb.WriteByte('.')
+			sb.WriteString(digits[1:])
+		}
+		sb.WriteByte('e')
+		if exp < 0 {
+			sb.WriteByte('-')
+			exp = -exp
+		} else {
+			sb.WriteByte('+')
+		}
+		if exp < 10 {
+			sb.WriteByte('0')
+		}
+		sb.WriteString(strconv.Itoa(exp))
+		return sb.String()
+	}
+	switch {
+	case dp <= 0:
+		sb.WriteString("0.")
+		sb.WriteString(strings.Repeat("0", -dp))
+		sb.WriteString(digits)
+	case dp >= len(digits):
+		sb.WriteString(digits)
+		sb.WriteString(strings.Repeat("0", dp-len(digits)))
+	default:
+		sb.WriteString(digits[:dp])
+		sb.WriteByte('.')
+		sb.WriteString(digits[dp:])
+	}
+	return sb.String()
+}
+
+// shortestDecimal returns the fewest significant decimal digits of the finite,
+// nonzero f that convert back to f at its precision under round-to-nearest, with
+// no sign or trailing zeros, and the position dp of the decimal point, so that
+// |f| ≈ 0.digits × 10^dp. Rounding f to more digits never loses the round trip,
+// so the digit count is found by binary search.
+func (f *Float) shortestDecimal() (digits string, dp int) {
+	t := &Float{}
+	t.doinit()
+	C.mpfr_set_prec(&t.mpfr[0], C.mpfr_get_prec(&f.mpfr[0]))
+
+	get := func(n int) (string, int) {
+		var exp C.mpfr_exp_t
+		cstr := C.mpfr_get_str(nil, &exp, 10, C.size_t(n), &f.mpfr[0], C.MPFR_RNDN)
+		defer C.mpfr_free_str(cstr)
+		return strings.TrimPrefix(C.GoString(cstr), "-"), int(exp)
+	}
+	roundTrips := func(n int) bool {
+		d, e := get(n)
+		cstr := C.CString("0." + d + "e" + strconv.Itoa(e))
+		defer C.free(unsafe.Pointer(cstr))
+		C.mpfr_set_str(&t.mpfr[0], cstr, 10, C.MPFR_RNDN)
+		return C.mpfr_cmpabs(&t.mpfr[0], &f.mpfr[0]) == 0
+	}
+
+	lo, hi := 1, int(C.mpfr_get_str_ndigits(10, C.mpfr_get_prec(&f.mpfr[0])))
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if roundTrips(mid) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	digits, dp = get(lo)
+	return strings.TrimRight(digits, "0"), dp
+}
+
+// decimalString formats f in base 10 with the given number of significant digits,
+// or as many as the precision of f requires if digits is 0.
+func (f *Float) decimalString(digits int) string {
+	var sb strings.Builder
+	if err := f.writeDigits(&sb, 10, digits); err != nil {
+		return "<mpfr_get_str_error>"
+	}
+	return sb.String()
+}
+
+// DigitStream writes f to w in positional notation in the given base (2 to 62),
+// with as many digits as the precision of f requires. For base 10 the output is
+// exactly StringN(0). NaN and infinities are written as "NaN", "+Inf" and "-Inf".
+//
+// The digits are written straight from the buffer MPFR allocates, in chunks, so
+// no Go string of the full result is ever built; this keeps memory flat when
+// writing millions of digits to a file. It returns ErrInvalidBase for an
+// unsupported base and otherwise the first error returned by w.
+func (f *Float) DigitStream(base int, w io.Writer) error {
+	if base < 2 || base > 62 {
+		return ErrInvalidBase
+	}
+	return f.writeDigits(w, base, 0)
+}
+
+// digitChunk is the number of bytes handed to an io.Writer at a time by writeDigits.
+const digitChunk = 64 << 10
+
+// writeDigits writes f to w as described for DigitStream, with the given number of
+// significant digits, or as many as the precision of f requires if digits is 0.
+func (f *Float) writeDigits(w io.Writer, base, digits int) error {
+	f.doinit()
+
+	switch {
+	case C.mpfr_nan_p(&f.mpfr[0]) != 0:
+		_, err := io.WriteString(w, "NaN")
+		return err
+	case C.mpfr_inf_p(&f.mpfr[0]) != 0:
+		if C.mpfr_signbit(&f.mpfr[0]) != 0 {
+			_, err := io.WriteString(w, "-Inf")
+			return err
+		}
+		_, err := io.WriteString(w, "+Inf")
+		return err
+	}
+
+	var exp C.mpfr_exp_t
+	cstr := C.mpfr_get_str(nil, &exp, C.int(base), C.size_t(digits), &f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	if cstr == nil {
+		return &FloatError{Msg: "mpfr_get_str failed"}
+	}
+	defer C.mpfr_free_str(cstr)
+
+	// mantissa aliases the C buffer; it is only read before mpfr_free_str runs.
+	mantissa := unsafe.Slice((*byte)(unsafe.Pointer(cstr)), C.strlen(cstr))
+	sw := &stickyWriter{w: w}
+	if mantissa[0] == '-' {
+		sw.write([]byte("-"))
+		mantissa = mantissa[1:]
+	}
+
+	intExp := int(exp)
+	if intExp >= 0 {
+		if intExp >= len(mantissa) {
+			// All digits precede the point: pad with 0's and end in ".0", never a bare ".".
+			sw.write(mantissa)
+			sw.zeros(intExp - len(mantissa))
+			sw.write([]byte(".0"))
+			return sw.err
+		}
+		sw.write(mantissa[:intExp])
+		sw.write([]byte("."))
+		sw.write(mantissa[intExp:])
+		return sw.err
+	}
+	// pad with 0's
+	sw.write([]byte("0."))
+	sw.zeros(-intExp)
+	sw.write(mantissa)
+	return sw.err
+}
+
+// stickyWriter writes to w in chunks of at most digitChunk bytes and remembers the
+// first error, after which all writes are skipped.
+type stickyWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (sw *stickyWriter) write(b []byte) {
+	for len(b) > 0 && sw.err == nil {
+		n := min(len(b), digitChunk)
+		_, sw.err = sw.w.Write(b[:n])
+		b = b[n:]
+	}
+}
+
+var zeroDigits = []byte(strings.Repeat("0", 4096))
+
+func (sw *stickyWriter) zeros(n int) {
+	for n > 0 && sw.err == nil {
+		k := min(n, len(zeroDigits))
+		sw.write(zeroDigits[:k])
+		n -= k
+	}
+}
+
+// clone returns a new Float with the same precision, rounding mode and value as f.
+func (f *Float) clone() *Float {
+	f.doinit()
+	c := &Float{}
+	c.doinit()
+	C.mpfr_set_prec(&c.mpfr[0], C.mpfr_get_prec(&f.mpfr[0]))
+	C.mpfr_set(&c.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(RoundToNearest))
+	c.RoundingMode = f.RoundingMode
+	return c
+}
+
 // Copy sets f to x, copying the entire mpfr_t.
 func (f *Float) Copy(x *Float) *Float {
 	x.doinit()
 	f.doinit()
+	f.markDirty()
 	C.mpfr_set(&f.mpfr[0], &x.mpfr[0], C.mpfr_rnd_t(RoundToNearest))
 	return f
 }
 
+// SetPrecRnd sets f's precision to prec and its value to x rounded with rnd, and
+// returns f. f's RoundingMode becomes rnd. This is the idiomatic way to take a
+// lower-precision rounded copy of a high-precision intermediate; x is unchanged
+// unless it is f itself. Use SetPrecRndTernary to learn the rounding direction.
+func (f *Float) SetPrecRnd(x *Float, prec uint, rnd Rnd) *Float {
+	f.SetPrecRndTernary(x, prec, rnd)
+	return f
+}
+
+// SetPrecRndTernary is like SetPrecRnd but returns MPFR's ternary value: 0 if f
+// holds x exactly, a positive value if f > x and a negative value if f < x.
+func (f *Float) SetPrecRndTernary(x *Float, prec uint, rnd Rnd) int {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	f.RoundingMode = rnd
+	if f == x {
+		return int(C.mpfr_prec_round(&f.mpfr[0], C.mpfr_prec_t(prec), C.mpfr_rnd_t(rnd)))
+	}
+	C.mpfr_set_prec(&f.mpfr[0], C.mpfr_prec_t(prec))
+	return int(C.mpfr_set(&f.mpfr[0], &x.mpfr[0], C.mpfr_rnd_t(rnd)))
+}
+
 // Add performs sequential addition on the receiver `f` and stores the result:
 //
 //   - If called with one argument (`x`), the function computes f + x, where `f` is the current value
@@ -190,6 +647,7 @@ func (f *Float) Add(args ...*Float) *Float {
 	}
 
 	f.doinit()
+	f.markDirty()
 
 	// Sequentially add the arguments.
 	for _, addend := range args {
@@ -200,10 +658,58 @@ func (f *Float) Add(args ...*Float) *Float {
 	return f
 }
 
-// Add sets f to x + y, with the given rounding mode, and returns f.
+// shallowCopies initializes each x and returns copies of their mpfr structs, which
+// share the significands with xs, so that a slice can be handed to a C helper
+// without pinning every Float. ok is false if any x is f, in which case the helper
+// would read a stale copy of a value it is modifying.
+func shallowCopies(f *Float, xs []*Float) (copies []C.__mpfr_struct, ok bool) {
+	copies = make([]C.__mpfr_struct, len(xs))
+	for i, x := range xs {
+		if x == f {
+			return nil, false
+		}
+		x.doinit()
+		copies[i] = x.mpfr[0]
+	}
+	return copies, true
+}
+
+// AddAll sets f = f + xs[0] + ... + xs[n-1] and returns f.
+//
+// The result is identical to f.Add(xs...), including the rounding after each
+// addition, but the loop runs in C so the whole slice costs one cgo call instead
+// of one per element; over 1e5 operands it runs about three times faster (see
+// BenchmarkAddAll). If f itself appears in xs, AddAll falls back to Add.
+func (f *Float) AddAll(xs []*Float) *Float {
+	f.doinit()
+	f.markDirty()
+	if len(xs) == 0 {
+		return f
+	}
+	copies, ok := shallowCopies(f, xs)
+	if !ok {
+		return f.Add(xs...)
+	}
+	C.gompfr_add_many(&f.mpfr[0], &copies[0], C.size_t(len(copies)), C.mpfr_rnd_t(f.RoundingMode))
+	runtime.KeepAlive(xs)
+	return f
+}
+
+// Add sets x to x + y, rounded with rnd, and returns x. x's RoundingMode is left unchanged.
 func Add(x, y *Float, rnd Rnd) *Float {
-	x.SetRoundMode(rnd)
-	return x.Add(y)
+	return WithRounding(x, rnd, func() *Float { return x.Add(y) })
+}
+
+// Sum returns x + y, rounded with rnd, in a new Float whose precision follows the
+// result precision mode (see SetResultPrecMode). Under PrecMaxOperand the sum of
+// two 200-bit Floats has 200 bits, where NewFloat().Add(x, y) would round it to the
+// default precision.
+func Sum(x, y *Float, rnd Rnd) *Float {
+	x.doinit()
+	y.doinit()
+	f := newResult(rnd, x, y)
+	C.mpfr_add(&f.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.mpfr_rnd_t(rnd))
+	return f
 }
 
 // Sub performs sequential subtraction on the receiver `f` and stores the result:
@@ -241,6 +747,7 @@ func (f *Float) Sub(args ...*Float) *Float {
 		panic("Sub requires at least 1 argument")
 	}
 	f.doinit()
+	f.markDirty()
 
 	// Sequentially subtract the arguments.
 	for _, subtrahend := range args {
@@ -251,9 +758,44 @@ func (f *Float) Sub(args ...*Float) *Float {
 	return f
 }
 
+// Sub sets x to x - y, rounded with rnd, and returns x. x's RoundingMode is left unchanged.
 func Sub(x, y *Float, rnd Rnd) *Float {
-	x.SetRoundMode(rnd)
-	return x.Sub(y)
+	return WithRounding(x, rnd, func() *Float { return x.Sub(y) })
+}
+
+// SubWithCancellation sets f = x - y using f's RoundingMode and returns f together
+// with an estimate of the significant bits lost to cancellation: the number of
+// binary orders of magnitude by which the result is smaller than the larger operand.
+// When x and y agree in their leading k bits, those bits cancel and the result
+// carries k fewer bits of information from the inputs, however precise f is.
+//
+// The estimate is heuristic. It is 0 when there is no cancellation and f's
+// precision when x and y are nonzero and cancel completely; NaN, infinite or zero
+// operands give 0. It says nothing about error already present in x and y, only
+// how much of their relative accuracy the subtraction discards.
+func (f *Float) SubWithCancellation(x, y *Float) (result *Float, lostBits int) {
+	x.doinit()
+	y.doinit()
+	f.doinit()
+	f.markDirty()
+	xRegular := C.mpfr_regular_p(&x.mpfr[0]) != 0
+	yRegular := C.mpfr_regular_p(&y.mpfr[0]) != 0
+	var ex, ey C.mpfr_exp_t
+	if xRegular {
+		ex = C.mpfr_get_exp(&x.mpfr[0])
+	}
+	if yRegular {
+		ey = C.mpfr_get_exp(&y.mpfr[0])
+	}
+	C.mpfr_sub(&f.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	if !xRegular || !yRegular {
+		return f, 0
+	}
+	if C.mpfr_zero_p(&f.mpfr[0]) != 0 {
+		return f, int(f.GetPrec())
+	}
+	lost := int(max(ex, ey)) - int(C.mpfr_get_exp(&f.mpfr[0]))
+	return f, max(lost, 0)
 }
 
 // Mul performs sequential multiplication on the receiver `f` and stores the result:
@@ -294,6 +836,7 @@ func (f *Float) Mul(args ...*Float) *Float {
 
 	// Initialize the receiver.
 	f.doinit()
+	f.markDirty()
 
 	// Sequentially multiply by the arguments.
 	for _, multiplier := range args {
@@ -304,9 +847,30 @@ func (f *Float) Mul(args ...*Float) *Float {
 	return f
 }
 
+// MulAll sets f = f * xs[0] * ... * xs[n-1] and returns f.
+//
+// As with AddAll, the loop runs in C with a single cgo call and the result is
+// identical to f.Mul(xs...). Every product is rounded with f's RoundingMode;
+// there is no way to round individual steps differently. If f itself appears in
+// xs, MulAll falls back to Mul.
+func (f *Float) MulAll(xs []*Float) *Float {
+	f.doinit()
+	f.markDirty()
+	if len(xs) == 0 {
+		return f
+	}
+	copies, ok := shallowCopies(f, xs)
+	if !ok {
+		return f.Mul(xs...)
+	}
+	C.gompfr_mul_many(&f.mpfr[0], &copies[0], C.size_t(len(copies)), C.mpfr_rnd_t(f.RoundingMode))
+	runtime.KeepAlive(xs)
+	return f
+}
+
+// Mul sets x to x * y, rounded with rnd, and returns x. x's RoundingMode is left unchanged.
 func Mul(x, y *Float, rnd Rnd) *Float {
-	x.SetRoundMode(rnd)
-	return x.Mul(y)
+	return WithRounding(x, rnd, func() *Float { return x.Mul(y) })
 }
 
 // Div performs sequential division on the receiver `f` and stores the result:
@@ -346,6 +910,7 @@ func (f *Float) Div(args ...*Float) *Float {
 	}
 
 	f.doinit()
+	f.markDirty()
 
 	// Sequentially divide by the arguments.
 	for _, divisor := range args {
@@ -356,9 +921,344 @@ func (f *Float) Div(args ...*Float) *Float {
 	return f
 }
 
+// Div sets x to x / y, rounded with rnd, and returns x. x's RoundingMode is left unchanged.
 func Div(x, y *Float, rnd Rnd) *Float {
-	x.SetRoundMode(rnd)
-	return x.Div(y)
+	return WithRounding(x, rnd, func() *Float { return x.Div(y) })
+}
+
+// Double sets f = 2 * f and returns f.
+//
+// Scaling by a power of two only changes the exponent, so the result is exact
+// unless it overflows the current exponent range.
+func (f *Float) Double() *Float {
+	f.doinit()
+	f.markDirty()
+	C.mpfr_mul_2si(&f.mpfr[0], &f.mpfr[0], 1, C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// Half sets f = f / 2 and returns f.
+//
+// Scaling by a power of two only changes the exponent, so the result is exact
+// unless it underflows the current exponent range. This makes it the preferred
+// way to take midpoints in bisection.
+func (f *Float) Half() *Float {
+	f.doinit()
+	f.markDirty()
+	C.mpfr_mul_2si(&f.mpfr[0], &f.mpfr[0], -1, C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// Scalbn sets f = x × 2^n using f's RoundingMode and returns f. It matches C's
+// scalbn and is exact unless the result leaves the exponent range or f has fewer
+// bits than x.
+func (f *Float) Scalbn(x *Float, n int) *Float {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	C.mpfr_mul_2si(&f.mpfr[0], &x.mpfr[0], C.long(n), C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// SetPow2 sets f = 2^e and returns f. A power of two needs a single significant
+// bit, so the result is exact at any precision as long as e is within the current
+// exponent range (see GetEmin and GetEmax); outside it, f overflows or underflows
+// according to its RoundingMode.
+func (f *Float) SetPow2(e int) *Float {
+	f.doinit()
+	f.markDirty()
+	C.mpfr_set_ui_2exp(&f.mpfr[0], 1, C.mpfr_exp_t(e), C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// Pow2 returns a new Float of precision prec holding 2^e exactly; see SetPow2.
+func Pow2(e int, prec uint) *Float {
+	return NewFloatWithPrec(prec).SetPow2(e)
+}
+
+// SafeMul returns the product x·y split as m × 2^scale, where m is x·y with the
+// operands' exponents factored out, so |m| lies in [1/4, 1) and cannot overflow or
+// underflow however large or small the true product is. f.Scalbn(m, scale)
+// recovers x·y when it is representable. Products of many factors, as in
+// determinants and norms, can be accumulated this way by multiplying the m values
+// and adding the scales.
+//
+// m has the precision chosen by SetResultPrecMode and is rounded with rnd. If x or
+// y is zero, infinite or NaN, m is the ordinary product and scale is 0.
+func SafeMul(x, y *Float, rnd Rnd) (m *Float, scale int) {
+	x.doinit()
+	y.doinit()
+	m = newResult(rnd, x, y)
+	if C.mpfr_regular_p(&x.mpfr[0]) == 0 || C.mpfr_regular_p(&y.mpfr[0]) == 0 {
+		C.mpfr_mul(&m.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.mpfr_rnd_t(rnd))
+		return m, 0
+	}
+	scale = int(C.mpfr_get_exp(&x.mpfr[0])) + int(C.mpfr_get_exp(&y.mpfr[0]))
+	mx, my := mantissaOf(x), mantissaOf(y)
+	C.mpfr_mul(&m.mpfr[0], &mx.mpfr[0], &my.mpfr[0], C.mpfr_rnd_t(rnd))
+	return m, scale
+}
+
+// mantissaOf returns a copy of the regular x with its exponent set to 0, so that
+// its magnitude lies in [1/2, 1).
+func mantissaOf(x *Float) *Float {
+	t := &Float{}
+	t.doinit()
+	C.mpfr_set_prec(&t.mpfr[0], C.mpfr_get_prec(&x.mpfr[0]))
+	C.mpfr_set(&t.mpfr[0], &x.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_set_exp(&t.mpfr[0], 0)
+	return t
+}
+
+// pow10CacheMax bounds the exponents whose powers of ten are kept in pow10Cache.
+// Larger powers are computed on demand and released after use. Cached powers live
+// until ClearPow10Cache; the full cache holds about 220 KB.
+const pow10CacheMax = 1024
+
+var (
+	pow10Mu    sync.Mutex
+	pow10Cache = map[uint]*C.mpz_t{}
+)
+
+// pow10 returns the exact integer 10^n and whether the caller must clear it.
+// Cached values are shared and must be treated as read-only.
+func pow10(n uint) (z *C.mpz_t, owned bool) {
+	if n > pow10CacheMax {
+		z = new(C.mpz_t)
+		C.mpz_init(&z[0])
+		C.mpz_ui_pow_ui(&z[0], 10, C.ulong(n))
+		return z, true
+	}
+	pow10Mu.Lock()
+	defer pow10Mu.Unlock()
+	if z, ok := pow10Cache[n]; ok {
+		return z, false
+	}
+	z = new(C.mpz_t)
+	C.mpz_init(&z[0])
+	C.mpz_ui_pow_ui(&z[0], 10, C.ulong(n))
+	pow10Cache[n] = z
+	return z, false
+}
+
+// ClearPow10Cache frees the powers of ten cached by MulPow10, DivPow10,
+// RoundDecimal, SetFixed and Fixed; later calls compute them again. The cached
+// values are shared, so ClearPow10Cache must not run concurrently with those
+// methods.
+func ClearPow10Cache() {
+	pow10Mu.Lock()
+	defer pow10Mu.Unlock()
+	for n, z := range pow10Cache {
+		C.mpz_clear(&z[0])
+		delete(pow10Cache, n)
+	}
+}
+
+// absUint returns |n| as a uint, which unlike -n cannot overflow for math.MinInt.
+func absUint(n int) uint {
+	if n < 0 {
+		return -uint(n)
+	}
+	return uint(n)
+}
+
+// MulPow10 sets f = f * 10^n and returns f. A negative n divides by 10^-n.
+//
+// 10^|n| is formed as an exact integer (and cached for moderate |n|), so the
+// result is correctly rounded in f's RoundingMode: it is the representable value
+// nearest to the true product in that direction. It is exact only when the
+// product itself fits in f's precision, e.g. 1.5 * 10^3; dividing by a power of
+// ten is almost never exact.
+func (f *Float) MulPow10(n int) *Float {
+	return f.scalePow10(absUint(n), n < 0)
+}
+
+// DivPow10 sets f = f / 10^n and returns f. It is equivalent to MulPow10(-n)
+// and is likewise correctly rounded rather than exact.
+func (f *Float) DivPow10(n int) *Float {
+	return f.scalePow10(absUint(n), n > 0)
+}
+
+// scalePow10 sets f = f / 10^k if div is set and f = f * 10^k otherwise.
+func (f *Float) scalePow10(k uint, div bool) *Float {
+	f.doinit()
+	f.markDirty()
+	if k == 0 {
+		return f
+	}
+	z, owned := pow10(k)
+	if owned {
+		defer C.mpz_clear(&z[0])
+	}
+	if div {
+		C.mpfr_div_z(&f.mpfr[0], &f.mpfr[0], &z[0], C.mpfr_rnd_t(f.RoundingMode))
+	} else {
+		C.mpfr_mul_z(&f.mpfr[0], &f.mpfr[0], &z[0], C.mpfr_rnd_t(f.RoundingMode))
+	}
+	return f
+}
+
+// RoundDecimal rounds f to places digits after the decimal point and returns f;
+// a negative places rounds to a multiple of 10^-places, e.g. -2 to hundreds.
+//
+// The rounding to a decimal step uses rnd (RoundToNearest breaks ties to even)
+// and is decided exactly: f is scaled by the power of ten without error, so a
+// value near a tie rounds the way its exact binary value says. The float64 nearest
+// 2.345 lies slightly above it and rounds to 2.35. The decimal result is usually not
+// representable in binary, so f is then set to the Float nearest to it at f's
+// precision, the same value SetString would give for its decimal text. NaN, ±Inf
+// and zeros are left unchanged.
+func (f *Float) RoundDecimal(places int, rnd Rnd) *Float {
+	f.doinit()
+	f.markDirty()
+	if C.mpfr_regular_p(&f.mpfr[0]) == 0 {
+		return f
+	}
+	if places >= 0 {
+		// f = m·2^lsb, so f·10^places is already an integer when lsb ≥ -places.
+		lsb := int64(C.mpfr_get_exp(&f.mpfr[0])) - int64(C.mpfr_min_prec(&f.mpfr[0]))
+		if lsb >= -int64(places) {
+			return f
+		}
+		z, owned := pow10(uint(places))
+		if owned {
+			defer C.mpz_clear(&z[0])
+		}
+		t := &Float{}
+		t.doinit()
+		C.mpfr_set_prec(&t.mpfr[0], C.mpfr_get_prec(&f.mpfr[0])+C.mpfr_prec_t(C.mpz_sizeinbase(&z[0], 2)))
+		C.mpfr_mul_z(&t.mpfr[0], &f.mpfr[0], &z[0], C.MPFR_RNDN)
+		C.mpfr_rint(&t.mpfr[0], &t.mpfr[0], C.mpfr_rnd_t(rnd))
+		C.mpfr_div_z(&f.mpfr[0], &t.mpfr[0], &z[0], C.MPFR_RNDN)
+		return f
+	}
+
+	z, owned := pow10(absUint(places))
+	if owned {
+		defer C.mpz_clear(&z[0])
+	}
+	var q C.mpz_t
+	C.mpz_init(&q[0])
+	defer C.mpz_clear(&q[0])
+	f.roundDivPow10(&q[0], &z[0], rnd)
+	neg := C.mpfr_signbit(&f.mpfr[0]) != 0
+	C.mpz_mul(&q[0], &q[0], &z[0])
+	C.mpfr_set_z(&f.mpfr[0], &q[0], C.MPFR_RNDN)
+	if C.mpfr_zero_p(&f.mpfr[0]) != 0 && neg {
+		C.mpfr_neg(&f.mpfr[0], &f.mpfr[0], C.MPFR_RNDN)
+	}
+	return f
+}
+
+// roundDivPow10 sets q to f / z rounded to an integer with rnd, where f is finite and
+// z is a positive power of ten. The rounding decision is made in exact integer arithmetic.
+func (f *Float) roundDivPow10(q, z C.mpz_ptr, rnd Rnd) {
+	// Split f = a + frac with a = floor(f) and divide a by 10^k: the true quotient
+	// is q + (r + frac)/10^k with 0 ≤ r + frac < 10^k.
+	var r C.mpz_t
+	C.mpz_init(&r[0])
+	defer C.mpz_clear(&r[0])
+	C.mpfr_get_z(q, &f.mpfr[0], C.MPFR_RNDD)
+	hasFrac := C.mpfr_integer_p(&f.mpfr[0]) == 0
+	C.mpz_fdiv_qr(q, &r[0], q, z)
+	inexact := hasFrac || C.mpz_size(&r[0]) != 0
+	neg := C.mpfr_signbit(&f.mpfr[0]) != 0
+
+	var up bool
+	switch rnd {
+	case RoundDown:
+		up = false
+	case RoundUp:
+		up = inexact
+	case RoundToward0:
+		up = inexact && neg
+	case RoundAway:
+		up = inexact && !neg
+	default:
+		// 10^k is even, so 2r ≠ 10^k already settles the comparison with a half.
+		C.mpz_mul_2exp(&r[0], &r[0], 1)
+		switch c := C.mpz_cmp(&r[0], z); {
+		case c > 0:
+			up = true
+		case c == 0:
+			up = hasFrac || C.mpz_tstbit(q, 0) != 0
+		}
+	}
+	if up {
+		C.mpz_add_ui(q, q, 1)
+	}
+}
+
+// SetFixed sets f = value·10^-decimals and returns f, so that a fixed-point amount
+// stored as an integer count of 10^-decimals units can be brought into f. The
+// result is rounded once, with f's RoundingMode; it is exact when decimals <= 0 and
+// the scaled integer fits in f's precision. A nil value sets f to zero.
+//
+//	f.SetFixed(big.NewInt(12345), 2) // f is now 123.45, correctly rounded
+func (f *Float) SetFixed(value *big.Int, decimals int) *Float {
+	f.doinit()
+	f.markDirty()
+	if value == nil {
+		C.mpfr_set_zero(&f.mpfr[0], 1)
+		return f
+	}
+	var v C.mpz_t
+	C.mpz_init(&v[0])
+	defer C.mpz_clear(&v[0])
+	bigIntToMpz(&v[0], value)
+
+	z, owned := pow10(absUint(decimals))
+	if owned {
+		defer C.mpz_clear(&z[0])
+	}
+	if decimals <= 0 {
+		C.mpz_mul(&v[0], &v[0], &z[0])
+		C.mpfr_set_z(&f.mpfr[0], &v[0], C.mpfr_rnd_t(f.RoundingMode))
+		return f
+	}
+	t := &Float{}
+	t.doinit()
+	C.mpfr_set_prec(&t.mpfr[0], C.mpfr_prec_t(C.mpz_sizeinbase(&v[0], 2)))
+	C.mpfr_set_z(&t.mpfr[0], &v[0], C.MPFR_RNDN)
+	C.mpfr_div_z(&f.mpfr[0], &t.mpfr[0], &z[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// Fixed returns f·10^decimals rounded to an integer with rnd, the inverse of SetFixed:
+// the value of f as an integer count of 10^-decimals units. The rounding is decided
+// exactly from the binary value of f, so RoundToNearest rounds to the nearest unit
+// with ties to even, RoundDown and RoundUp take the floor and ceiling, and
+// RoundToward0 truncates. Note that a decimal input such as 123.455 is usually not
+// exactly representable, and it is the stored binary value that gets rounded.
+// Fixed returns nil if f is NaN or infinite; f is not modified.
+func (f *Float) Fixed(decimals int, rnd Rnd) *big.Int {
+	f.doinit()
+	if C.mpfr_number_p(&f.mpfr[0]) == 0 {
+		return nil
+	}
+	var q C.mpz_t
+	C.mpz_init(&q[0])
+	defer C.mpz_clear(&q[0])
+
+	z, owned := pow10(absUint(decimals))
+	if owned {
+		defer C.mpz_clear(&z[0])
+	}
+	if decimals >= 0 {
+		// f·10^decimals is formed exactly, so only the conversion to an integer rounds.
+		t := &Float{}
+		t.doinit()
+		C.mpfr_set_prec(&t.mpfr[0], C.mpfr_get_prec(&f.mpfr[0])+C.mpfr_prec_t(C.mpz_sizeinbase(&z[0], 2)))
+		C.mpfr_mul_z(&t.mpfr[0], &f.mpfr[0], &z[0], C.MPFR_RNDN)
+		C.mpfr_get_z(&q[0], &t.mpfr[0], C.mpfr_rnd_t(rnd))
+	} else {
+		f.roundDivPow10(&q[0], &z[0], rnd)
+	}
+	n := mpzToBigInt(&q[0])
+	if C.mpfr_signbit(&f.mpfr[0]) != 0 {
+		n.Neg(n)
+	}
+	return n
 }
 
 // Quo sets f to the quotient of x / y with the specified rounding mode and returns f.
@@ -367,6 +1267,7 @@ func (f *Float) Quo(x, y *Float) *Float {
 	x.doinit()
 	y.doinit()
 	f.doinit()
+	f.markDirty()
 
 	// Check for division by zero
 	if C.mpfr_zero_p(&y.mpfr[0]) != 0 { // mpfr_zero_p returns nonzero if y is zero
@@ -380,8 +1281,7 @@ func (f *Float) Quo(x, y *Float) *Float {
 
 // Quo sets f to the quotient of x / y with the specified rounding mode and returns f.
 func Quo(x, y *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x, y)
 	return f.Quo(x, y)
 }
 
@@ -409,48 +1309,125 @@ func Quo(x, y *Float, rnd Rnd) *Float {
 //	f.Pow(x, y) // f is now 2.0^3.0 = 8.0
 //
 // Notes:
-// - If only one argument is provided, `y` must be initialized before the call.
-// - If two or three arguments are provided, both `x` and `y` must be initialized before the call.
-// - If `rnd` is not provided, the rounding mode of the receiver `f` is used.
-// - The function handles special cases like x = 0 or y = 0 according to the MPFR library rules.
-// - Behavior for non-integer exponents and negative bases depends on MPFR.
+//   - If only one argument is provided, `y` must be initialized before the call.
+//   - If two or three arguments are provided, both `x` and `y` must be initialized before the call.
+//   - If `rnd` is not provided, the rounding mode of the receiver `f` is used.
+//   - Special cases follow the MPFR library (IEEE 754) rules: x^0 = 1 for every x, including 0 and NaN,
+//     and 0^(-n) is ±Inf.
+//   - A negative base with a non-integer exponent, e.g. (-1)^0.5 or (-8)^(1/3), yields NaN.
+//     Use Cbrt or RootUI for real odd roots, or PowReal to get an error instead of NaN.
 //
 // Returns:
 //
 //	A pointer to the modified receiver `f`.
+//
+// Deprecated: the interface{} arguments are checked only at run time and panic on
+// misuse. Use PowSelf or PowOf instead.
 func (f *Float) Pow(args ...interface{}) *Float {
-	if len(args) == 1 {
-		// Compute f^y (receiver raised to the power of y).
+	switch len(args) {
+	case 0:
+		panic("Pow expects at least one argument")
+	case 1:
 		y, yOk := args[0].(*Float)
 		if !yOk {
 			panic("Pow expects a *Float as the single argument")
 		}
-		y.doinit()
-		f.doinit()
-		C.mpfr_pow(&f.mpfr[0], &f.mpfr[0], &y.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
-	} else if len(args) > 1 {
-		// Compute x^y using the receiver's rounding mode.
+		return f.PowSelf(y)
+	default:
 		x, xOk := args[0].(*Float)
 		y, yOk := args[1].(*Float)
 		if !xOk || !yOk {
 			panic("Pow expects two *Float arguments (x, y)")
 		}
-		x.doinit()
-		y.doinit()
-		f.doinit()
-		C.mpfr_pow(&f.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
-	} else {
-		panic("Pow expects at least one argument")
+		return f.PowOf(x, y)
 	}
+}
 
-	return f
+// PowSelf sets f = f^y using f's RoundingMode and returns f.
+// Special cases are as documented on Pow.
+func (f *Float) PowSelf(y *Float) *Float {
+	return f.PowOf(f, y)
 }
 
-// Pow sets f to x^y (x raised to the power y), with the given rounding mode, and returns f.
+// PowOf sets f = x^y using f's RoundingMode and returns f.
+// Special cases are as documented on Pow.
+func (f *Float) PowOf(x, y *Float) *Float {
+	x.doinit()
+	y.doinit()
+	f.doinit()
+	f.markDirty()
+	C.mpfr_pow(&f.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// Pow sets f to x^y (x raised to the power y), with the given rounding mode, and returns f.
 func Pow(x, y *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
-	return f.Pow(x, y)
+	f := newResult(rnd, x, y)
+	return f.PowOf(x, y)
+}
+
+// PowReal returns x^y rounded with rnd, restricted to real results.
+// Where Pow would silently produce NaN from non-NaN operands, such as a negative
+// base raised to a non-integer power, PowReal returns ErrDomain instead.
+// NaN operands propagate to a NaN result without an error.
+func PowReal(x, y *Float, rnd Rnd) (*Float, error) {
+	f := Pow(x, y, rnd)
+	if f.IsNaN() && !x.IsNaN() && !y.IsNaN() {
+		return nil, ErrDomain
+	}
+	return f, nil
+}
+
+// SmartPow sets f = x^y using f's RoundingMode and returns f, dispatching to a
+// specialized routine when y has a special value:
+//
+//   - y == 0.5 uses Sqrt and y == -0.5 uses RecSqrt. Both are correctly rounded, so
+//     the result is identical to Pow; zero and infinite x go to Pow, whose signed-zero
+//     and -Inf rules differ from those of Sqrt.
+//   - y an integer that fits in a long uses mpfr_pow_si, which is correctly rounded
+//     and identical to Pow but avoids the general algorithm.
+//   - y equal to 1/3 rounded to nearest at y's own precision uses Cbrt. 1/3 itself
+//     is never representable, so this is a deliberate reinterpretation: the result is
+//     the correctly rounded cube root of x, not x raised to the rounded y, and a
+//     negative x gives a real root where Pow gives NaN. Values merely close to 1/3
+//     are not matched.
+//
+// Any other y falls back to PowOf.
+func (f *Float) SmartPow(x, y *Float) *Float {
+	x.doinit()
+	y.doinit()
+	f.doinit()
+	f.markDirty()
+	rnd := C.mpfr_rnd_t(f.RoundingMode)
+	// mpfr_cmp_d reports 0 for a NaN y, so NaN must not reach the 0.5 tests.
+	regular := C.mpfr_regular_p(&x.mpfr[0]) != 0 && C.mpfr_nan_p(&y.mpfr[0]) == 0
+	switch {
+	case regular && C.mpfr_cmp_d(&y.mpfr[0], 0.5) == 0:
+		C.mpfr_sqrt(&f.mpfr[0], &x.mpfr[0], rnd)
+	case regular && C.mpfr_cmp_d(&y.mpfr[0], -0.5) == 0:
+		C.mpfr_rec_sqrt(&f.mpfr[0], &x.mpfr[0], rnd)
+	case y.IsInteger() && C.mpfr_fits_slong_p(&y.mpfr[0], C.MPFR_RNDN) != 0:
+		n := C.mpfr_get_si(&y.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_pow_si(&f.mpfr[0], &x.mpfr[0], n, rnd)
+	case isRoundedThird(y):
+		C.mpfr_cbrt(&f.mpfr[0], &x.mpfr[0], rnd)
+	default:
+		C.mpfr_pow(&f.mpfr[0], &x.mpfr[0], &y.mpfr[0], rnd)
+	}
+	return f
+}
+
+// isRoundedThird reports whether y is 1/3 rounded to nearest at y's precision.
+func isRoundedThird(y *Float) bool {
+	if C.mpfr_regular_p(&y.mpfr[0]) == 0 || C.mpfr_get_exp(&y.mpfr[0]) != -1 {
+		return false
+	}
+	t := &Float{}
+	t.doinit()
+	C.mpfr_set_prec(&t.mpfr[0], C.mpfr_get_prec(&y.mpfr[0]))
+	C.mpfr_set_ui(&t.mpfr[0], 1, C.MPFR_RNDN)
+	C.mpfr_div_ui(&t.mpfr[0], &t.mpfr[0], 3, C.MPFR_RNDN)
+	return C.mpfr_equal_p(&t.mpfr[0], &y.mpfr[0]) != 0
 }
 
 // Exp computes the exponential function e^x and stores the result in the receiver `f`.
@@ -485,6 +1462,7 @@ func Pow(x, y *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Exp(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Compute e^f in place.
@@ -500,8 +1478,7 @@ func (f *Float) Exp(args ...*Float) *Float {
 }
 
 func Exp(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Exp(x)
 }
 
@@ -537,6 +1514,7 @@ func Exp(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Log(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Compute ln(f) in place.
@@ -552,8 +1530,7 @@ func (f *Float) Log(args ...*Float) *Float {
 }
 
 func Log(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Log(x)
 }
 
@@ -571,6 +1548,38 @@ func Cmp(x, y *Float) int {
 	return int(C.mpfr_cmp(&x.mpfr[0], &y.mpfr[0]))
 }
 
+// TotalCmp compares f and x under the IEEE 754 totalOrder predicate and returns
+// -1, 0 or +1. Unlike Cmp it orders every value, giving
+//
+//	-NaN < -Inf < negative numbers < -0 < +0 < positive numbers < +Inf < +NaN
+//
+// so it is suitable as a sort comparator. Only identical values compare equal.
+func (f *Float) TotalCmp(x *Float) int {
+	f.doinit()
+	x.doinit()
+	le := C.mpfr_total_order_p(&f.mpfr[0], &x.mpfr[0]) != 0
+	ge := C.mpfr_total_order_p(&x.mpfr[0], &f.mpfr[0]) != 0
+	switch {
+	case le && ge:
+		return 0
+	case le:
+		return -1
+	default:
+		return +1
+	}
+}
+
+// Sort sorts xs in ascending order according to TotalCmp, so NaNs, infinities
+// and signed zeros land in a deterministic position. The sort is not stable.
+func Sort(xs []*Float) {
+	sort.Slice(xs, func(i, j int) bool { return xs[i].TotalCmp(xs[j]) < 0 })
+}
+
+// SortStable is like Sort but keeps equal elements in their original order.
+func SortStable(xs []*Float) {
+	sort.SliceStable(xs, func(i, j int) bool { return xs[i].TotalCmp(xs[j]) < 0 })
+}
+
 // Abs computes the absolute value of a value, |x|, and stores the result in the receiver `f`.
 //
 //   - If called with no arguments, the function computes |f|, where `f` is the current value
@@ -603,6 +1612,7 @@ func Cmp(x, y *Float) int {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Abs(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Compute |f| in place.
@@ -617,9 +1627,17 @@ func (f *Float) Abs(args ...*Float) *Float {
 	return f
 }
 
+// AbsInPlace sets f = |f| and returns f.
+// It is equivalent to f.Abs() but avoids the variadic argument handling, for use in hot loops.
+func (f *Float) AbsInPlace() *Float {
+	f.doinit()
+	f.markDirty()
+	C.mpfr_abs(&f.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
 func Abs(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Abs(x)
 
 }
@@ -657,6 +1675,7 @@ func Abs(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Acos(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Compute arccos(f) in place.
@@ -673,8 +1692,7 @@ func (f *Float) Acos(args ...*Float) *Float {
 
 // Acos sets f = arccos(x) with rounding mode rnd.
 func Acos(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Acos(x)
 }
 
@@ -711,6 +1729,7 @@ func Acos(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Acosh(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Compute arcosh(f) in place.
@@ -727,8 +1746,7 @@ func (f *Float) Acosh(args ...*Float) *Float {
 
 // Acosh sets f = arcosh(x) with rounding mode rnd.
 func Acosh(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Acosh(x)
 }
 
@@ -737,17 +1755,47 @@ func (f *Float) Agm(x, y *Float) *Float {
 	x.doinit()
 	y.doinit()
 	f.doinit()
+	f.markDirty()
 	C.mpfr_agm(&f.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	return f
 }
 
 // Agm returns AGM(x, y) (arithmetic-geometric mean), using rnd.
 func Agm(x, y *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x, y)
 	return f.Agm(x, y)
 }
 
+// AGMStep performs one step of the arithmetic-geometric mean iteration and returns
+// the new pair (a2, b2) = ((a + b) / 2, sqrt(a * b)), each rounded to prec bits with rnd.
+//
+// Both means are computed from the original a and b, which are left unchanged, so the
+// caller does not need a temporary to avoid one update corrupting the other:
+//
+//	a, b := mpfr.FromInt(1), mpfr.FromInt(9)
+//	for i := 0; i < 10; i++ {
+//		a, b = mpfr.AGMStep(a, b, 256, mpfr.RoundToNearest)
+//	}
+func AGMStep(a, b *Float, prec uint, rnd Rnd) (a2, b2 *Float) {
+	a.doinit()
+	b.doinit()
+
+	a2 = NewFloatWithPrec(prec)
+	a2.SetRoundMode(rnd)
+	C.mpfr_add(&a2.mpfr[0], &a.mpfr[0], &b.mpfr[0], C.mpfr_rnd_t(rnd))
+	C.mpfr_div_2ui(&a2.mpfr[0], &a2.mpfr[0], 1, C.mpfr_rnd_t(rnd))
+
+	// The product is held exactly so the square root is the only rounding.
+	prod := NewFloatWithPrec(uint(C.mpfr_get_prec(&a.mpfr[0]) + C.mpfr_get_prec(&b.mpfr[0])))
+	C.mpfr_mul(&prod.mpfr[0], &a.mpfr[0], &b.mpfr[0], C.mpfr_rnd_t(rnd))
+	b2 = NewFloatWithPrec(prec)
+	b2.SetRoundMode(rnd)
+	C.mpfr_sqrt(&b2.mpfr[0], &prod.mpfr[0], C.mpfr_rnd_t(rnd))
+	prod.Clear()
+
+	return a2, b2
+}
+
 // Asin computes the arcsine of a value, arcsin(x), and stores the result in the receiver `f`.
 //
 //   - If called with no arguments, the function computes arcsin(f), where `f` is the current value
@@ -781,6 +1829,7 @@ func Agm(x, y *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Asin(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 	if len(args) == 0 {
 		// Compute arcsin(f) in place.
 		C.mpfr_asin(&f.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
@@ -796,8 +1845,7 @@ func (f *Float) Asin(args ...*Float) *Float {
 
 // Asin returns arcsin(x), using rnd.
 func Asin(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Asin(x)
 }
 
@@ -833,6 +1881,7 @@ func Asin(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Asinh(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Compute arsinh(f) in place.
@@ -849,8 +1898,7 @@ func (f *Float) Asinh(args ...*Float) *Float {
 
 // Asinh returns arsinh(x), using rnd.
 func Asinh(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Asinh(x)
 }
 
@@ -886,6 +1934,7 @@ func Asinh(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Atan(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Compute arctan(f) in place.
@@ -902,8 +1951,7 @@ func (f *Float) Atan(args ...*Float) *Float {
 
 // Atan returns arctan(x), using rnd.
 func Atan(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Atan(x)
 }
 
@@ -912,14 +1960,14 @@ func (f *Float) Atan2(y, x *Float) *Float {
 	y.doinit()
 	x.doinit()
 	f.doinit()
+	f.markDirty()
 	C.mpfr_atan2(&f.mpfr[0], &y.mpfr[0], &x.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	return f
 }
 
 // Atan2 returns arctan2(y, x) = angle whose tangent is y/x, using rnd.
 func Atan2(y, x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, y, x)
 	return f.Atan2(y, x)
 }
 
@@ -955,6 +2003,7 @@ func Atan2(y, x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Atanh(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Compute artanh(f) in place.
@@ -971,8 +2020,7 @@ func (f *Float) Atanh(args ...*Float) *Float {
 
 // Atanh returns artanh(x), using rnd.
 func Atanh(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Atanh(x)
 }
 
@@ -997,6 +2045,7 @@ func Atanh(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Cbrt(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 	if len(args) == 0 {
 		C.mpfr_cbrt(&f.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	} else {
@@ -1009,8 +2058,7 @@ func (f *Float) Cbrt(args ...*Float) *Float {
 
 // Cbrt returns cbrt(x), using rnd.
 func Cbrt(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Cbrt(x)
 }
 
@@ -1034,6 +2082,7 @@ func Cbrt(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Sqrt(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 	if len(args) == 0 {
 		C.mpfr_sqrt(&f.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	} else {
@@ -1045,18 +2094,173 @@ func (f *Float) Sqrt(args ...*Float) *Float {
 	return f
 }
 
+// SqrtInPlace sets f = sqrt(f) and returns f.
+// It is equivalent to f.Sqrt() but avoids the variadic argument handling, for use in hot loops.
+func (f *Float) SqrtInPlace() *Float {
+	f.doinit()
+	f.markDirty()
+	C.mpfr_sqrt(&f.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
 // Sqrt returns sqrt(x), using rnd.
 func Sqrt(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Sqrt(x)
 }
 
+// checkedResult runs op, which computes f from operands, and reports ErrDomain if
+// it turned non-NaN operands into NaN and ErrRange if it overflowed or underflowed.
+// f holds MPFR's result either way.
+func (f *Float) checkedResult(op func(), operands ...*Float) error {
+	over, under := checkRange(op)
+	if C.mpfr_nan_p(&f.mpfr[0]) != 0 {
+		for _, x := range operands {
+			if C.mpfr_nan_p(&x.mpfr[0]) != 0 {
+				return nil
+			}
+		}
+		return ErrDomain
+	}
+	if over || under {
+		return ErrRange
+	}
+	return nil
+}
+
+// QuoErr sets f = x / y using f's RoundingMode. Unlike Quo, it does not panic:
+// it returns ErrDomain if y is zero (leaving f at MPFR's ±Inf or NaN) or the
+// quotient is otherwise undefined, and ErrRange if it overflows or underflows.
+func (f *Float) QuoErr(x, y *Float) error {
+	x.doinit()
+	y.doinit()
+	f.doinit()
+	f.markDirty()
+	err := f.checkedResult(func() {
+		C.mpfr_div(&f.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	}, x, y)
+	if err == nil && C.mpfr_zero_p(&y.mpfr[0]) != 0 && C.mpfr_nan_p(&x.mpfr[0]) == 0 {
+		return ErrDomain
+	}
+	return err
+}
+
+// SqrtChecked sets f = sqrt(x) using f's RoundingMode, returning ErrDomain
+// (with f set to NaN) if x is negative. sqrt(-0) is -0 and not an error.
+func (f *Float) SqrtChecked(x *Float) error {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	return f.checkedResult(func() {
+		C.mpfr_sqrt(&f.mpfr[0], &x.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	}, x)
+}
+
+// AcoshChecked sets f = acosh(x) using f's RoundingMode, returning ErrDomain
+// (with f set to NaN) if x < 1, where the inverse hyperbolic cosine is undefined.
+func (f *Float) AcoshChecked(x *Float) error {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	return f.checkedResult(func() {
+		C.mpfr_acosh(&f.mpfr[0], &x.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	}, x)
+}
+
+// AtanhChecked sets f = atanh(x) using f's RoundingMode, returning ErrDomain
+// (with f set to NaN) if |x| >= 1. That includes the poles x = ±1, where MPFR
+// itself would give ±Inf.
+func (f *Float) AtanhChecked(x *Float) error {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	err := f.checkedResult(func() {
+		C.mpfr_atanh(&f.mpfr[0], &x.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	}, x)
+	if err == nil && C.mpfr_inf_p(&f.mpfr[0]) != 0 {
+		C.mpfr_set_nan(&f.mpfr[0])
+		return ErrDomain
+	}
+	return err
+}
+
+// RootN sets f to the n-th root of x using f's RoundingMode; negative n gives
+// the reciprocal root. It returns ErrDomain if n is 0 or if x is negative and n is
+// even, and ErrRange if the result overflows or underflows, as a negative n can.
+func (f *Float) RootN(x *Float, n int) error {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	return f.checkedResult(func() {
+		C.mpfr_rootn_si(&f.mpfr[0], &x.mpfr[0], C.long(n), C.mpfr_rnd_t(f.RoundingMode))
+	}, x)
+}
+
+// powRationalExactBits bounds the size of x^|p| that PowRational forms exactly;
+// larger powers are rounded to the working precision first.
+const powRationalExactBits = 1 << 16
+
+// PowRational sets f = x^(p/q) using f's RoundingMode and returns f, computed as
+// the q-th root of the integer power x^p rather than by a general pow with the
+// rounded exponent p/q. p/q is first reduced to lowest terms, so a negative x has a
+// real result whenever the reduced q is odd: PowRational(-8, 1, 3) and
+// PowRational(-8, 2, 6) are both -2 and PowRational(-8, 2, 3) is 4, where Pow gives
+// NaN for any fractional exponent.
+//
+// While x^|p| has at most powRationalExactBits significant bits it is formed
+// exactly and the root, taken by mpfr_rootn_si, is the only rounding, so the
+// result is correctly rounded (8^(2/3) is exactly 4). Larger powers are formed
+// with 64 guard bits before the root. It returns ErrDomain, with f set to NaN, if
+// q is 0 or if x is negative and the reduced q is even, ErrDomain with f set to
+// ±Inf if x is ±0 and p is negative (a pole, as in QuoErr), and ErrRange if x^|p| or
+// the result overflows or underflows; since x^|p| is formed first, that can happen
+// even when x^(p/q) itself is in range.
+func (f *Float) PowRational(x *Float, p int, q uint) (*Float, error) {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	if q == 0 {
+		C.mpfr_set_nan(&f.mpfr[0])
+		return f, ErrDomain
+	}
+	n := uint(p)
+	if p < 0 {
+		n = uint(-p)
+	}
+	a, b := n, q
+	for b != 0 {
+		a, b = b, a%b
+	}
+	n, q = n/a, q/a
+
+	t := &Float{}
+	t.doinit()
+	bits := uint64(C.mpfr_min_prec(&x.mpfr[0])) * uint64(n)
+	if bits <= powRationalExactBits {
+		C.mpfr_set_prec(&t.mpfr[0], C.mpfr_prec_t(max(bits, C.MPFR_PREC_MIN)))
+	} else {
+		C.mpfr_set_prec(&t.mpfr[0], C.mpfr_get_prec(&f.mpfr[0])+64)
+	}
+	root := C.long(q)
+	if p < 0 {
+		root = -root
+	}
+	err := f.checkedResult(func() {
+		C.mpfr_pow_ui(&t.mpfr[0], &x.mpfr[0], C.ulong(n), C.MPFR_RNDN)
+		C.mpfr_rootn_si(&f.mpfr[0], &t.mpfr[0], root, C.mpfr_rnd_t(f.RoundingMode))
+	}, x)
+	if err == nil && p < 0 && C.mpfr_zero_p(&x.mpfr[0]) != 0 {
+		return f, ErrDomain
+	}
+	return f, err
+}
+
 // RootUI sets f to the k-th root of x with the specified rounding mode and returns f.
 // If k is zero, it panics with an invalid argument error.
 func (f *Float) RootUI(x *Float, k uint) *Float {
 	x.doinit()
 	f.doinit()
+	f.markDirty()
 
 	if k == 0 {
 		panic("Root: k must be greater than 0")
@@ -1101,6 +2305,7 @@ func (f *Float) RootUI(x *Float, k uint) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Ceil(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 	var x *Float
 	if len(args) > 0 && args[0] != nil {
 		x = args[0]
@@ -1114,8 +2319,7 @@ func (f *Float) Ceil(args ...*Float) *Float {
 
 // Ceil returns ceil(x), using rnd.
 func Ceil(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Ceil(x)
 }
 
@@ -1159,6 +2363,7 @@ func CmpAbs(x, y *Float) int {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Cos(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 	if len(args) == 0 {
 		C.mpfr_cos(&f.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	} else {
@@ -1171,8 +2376,7 @@ func (f *Float) Cos(args ...*Float) *Float {
 
 // Cos returns cos(x), using rnd.
 func Cos(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Cos(x)
 }
 
@@ -1208,6 +2412,7 @@ func Cos(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Cosh(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		C.mpfr_cosh(&f.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
@@ -1221,8 +2426,7 @@ func (f *Float) Cosh(args ...*Float) *Float {
 
 // Cosh returns cosh(x), using rnd.
 func Cosh(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Cosh(x)
 }
 
@@ -1260,6 +2464,7 @@ func Cosh(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Cot(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		C.mpfr_cot(&f.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
@@ -1273,8 +2478,7 @@ func (f *Float) Cot(args ...*Float) *Float {
 
 // Cot returns cot(x), using rnd.
 func Cot(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Cot(x)
 }
 
@@ -1312,6 +2516,7 @@ func Cot(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Coth(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 	if len(args) == 0 {
 		C.mpfr_coth(&f.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	} else {
@@ -1324,8 +2529,7 @@ func (f *Float) Coth(args ...*Float) *Float {
 
 // Coth returns coth(x), using rnd.
 func Coth(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Coth(x)
 }
 
@@ -1363,6 +2567,7 @@ func Coth(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Csc(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 	if len(args) == 0 {
 		C.mpfr_csc(&f.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	} else {
@@ -1375,8 +2580,7 @@ func (f *Float) Csc(args ...*Float) *Float {
 
 // Csc returns csc(x), using rnd.
 func Csc(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Csc(x)
 }
 
@@ -1414,6 +2618,7 @@ func Csc(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Csch(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 	if len(args) == 0 {
 		C.mpfr_csch(&f.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	} else {
@@ -1426,8 +2631,7 @@ func (f *Float) Csch(args ...*Float) *Float {
 
 // Csch returns csch(x), using rnd.
 func Csch(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Csch(x)
 }
 
@@ -1435,14 +2639,14 @@ func Csch(x *Float, rnd Rnd) *Float {
 func (f *Float) Exp10(x *Float) *Float {
 	x.doinit()
 	f.doinit()
+	f.markDirty()
 	C.mpfr_exp10(&f.mpfr[0], &x.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	return f
 }
 
 // Exp10 returns 10^x, using rnd
 func Exp10(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Exp10(x)
 }
 
@@ -1450,17 +2654,158 @@ func Exp10(x *Float, rnd Rnd) *Float {
 func (f *Float) Exp2(x *Float) *Float {
 	x.doinit()
 	f.doinit()
+	f.markDirty()
 	C.mpfr_exp2(&f.mpfr[0], &x.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	return f
 }
 
 // Exp2 returns 2^x, using rnd
 func Exp2(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Exp2(x)
 }
 
+// Expm1 sets f = e^x - 1, computed without the cancellation of Exp(x) - 1 for small x.
+//
+//   - If called with no arguments, the function computes e^f - 1 in place.
+//   - If called with one argument `x`, the function computes e^x - 1 and stores it in `f`.
+//
+// The result is correctly rounded using the receiver's RoundingMode.
+func (f *Float) Expm1(args ...*Float) *Float {
+	f.doinit()
+	f.markDirty()
+	x := f
+	if len(args) > 0 {
+		x = args[0]
+		x.doinit()
+	}
+	C.mpfr_expm1(&f.mpfr[0], &x.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// Expm1 returns e^x - 1, using rnd.
+func Expm1(x *Float, rnd Rnd) *Float {
+	f := newResult(rnd, x)
+	return f.Expm1(x)
+}
+
+// Exp10m1 sets f = 10^x - 1, computed as Expm1(x·ln 10) so that it stays accurate
+// for x near 0, where 10^x is so close to 1 that Exp10(x) - 1 loses most of its digits.
+// This is the natural form for decibel and pH style quantities near a reference value.
+//
+//   - If called with no arguments, the function computes 10^f - 1 in place.
+//   - If called with one argument `x`, the function computes 10^x - 1 and stores it in `f`.
+//
+// The product x·ln 10 is formed with 64 guard bits beyond f's precision, so the
+// result is within one ulp of the exact value but is not guaranteed to be
+// correctly rounded.
+func (f *Float) Exp10m1(args ...*Float) *Float {
+	f.doinit()
+	f.markDirty()
+	x := f
+	if len(args) > 0 {
+		x = args[0]
+		x.doinit()
+	}
+	prec := uint(C.mpfr_get_prec(&f.mpfr[0])) + 64
+	t := NewFloatWithPrec(prec)
+	C.mpfr_log_ui(&t.mpfr[0], 10, C.MPFR_RNDN)
+	C.mpfr_mul(&t.mpfr[0], &t.mpfr[0], &x.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_expm1(&f.mpfr[0], &t.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// Exp10m1 returns 10^x - 1, using rnd.
+func Exp10m1(x *Float, rnd Rnd) *Float {
+	f := newResult(rnd, x)
+	return f.Exp10m1(x)
+}
+
+// Expm1mx sets f = e^x - 1 - x, rounded with f's RoundingMode, and returns f.
+//
+// For small x the result is about x²/2, so Expm1(x) - x cancels the leading term
+// and keeps only about prec - log₂(1/|x|) correct bits: at x = 1e-10 and 53 bits
+// about six significant digits survive. For |x| < 1/2 Expm1mx instead sums the series
+// x²/2! + x³/3! + …, whose terms shrink by at least a factor of 6, and otherwise
+// it subtracts x from Expm1(x), where the cancellation is mild. Either way 64
+// guard bits are used, so the result is within an ulp but not guaranteed to be
+// correctly rounded. It is +0 for a zero x, +Inf for ±Inf and NaN for NaN.
+func (f *Float) Expm1mx(x *Float) *Float {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	switch {
+	case C.mpfr_nan_p(&x.mpfr[0]) != 0:
+		C.mpfr_set_nan(&f.mpfr[0])
+		return f
+	case C.mpfr_inf_p(&x.mpfr[0]) != 0:
+		C.mpfr_set_inf(&f.mpfr[0], 1)
+		return f
+	case C.mpfr_zero_p(&x.mpfr[0]) != 0:
+		C.mpfr_set_zero(&f.mpfr[0], 1)
+		return f
+	}
+
+	wp := C.mpfr_get_prec(&f.mpfr[0]) + 64
+	sum := &Float{}
+	sum.doinit()
+	C.mpfr_set_prec(&sum.mpfr[0], wp)
+	if C.mpfr_get_exp(&x.mpfr[0]) >= 0 {
+		C.mpfr_expm1(&sum.mpfr[0], &x.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_sub(&f.mpfr[0], &sum.mpfr[0], &x.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+		return f
+	}
+
+	term := &Float{}
+	term.doinit()
+	C.mpfr_set_prec(&term.mpfr[0], wp)
+	C.mpfr_sqr(&term.mpfr[0], &x.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_mul_2si(&term.mpfr[0], &term.mpfr[0], -1, C.MPFR_RNDN)
+	C.mpfr_set(&sum.mpfr[0], &term.mpfr[0], C.MPFR_RNDN)
+	for k := 3; ; k++ {
+		C.mpfr_mul(&term.mpfr[0], &term.mpfr[0], &x.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_div_ui(&term.mpfr[0], &term.mpfr[0], C.ulong(k), C.MPFR_RNDN)
+		C.mpfr_add(&sum.mpfr[0], &sum.mpfr[0], &term.mpfr[0], C.MPFR_RNDN)
+		if C.mpfr_zero_p(&term.mpfr[0]) != 0 || C.mpfr_get_exp(&term.mpfr[0]) < C.mpfr_get_exp(&sum.mpfr[0])-C.mpfr_exp_t(wp) {
+			break
+		}
+	}
+	C.mpfr_set(&f.mpfr[0], &sum.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// Expm1mx returns e^x - 1 - x, using rnd.
+func Expm1mx(x *Float, rnd Rnd) *Float {
+	f := newResult(rnd, x)
+	return f.Expm1mx(x)
+}
+
+// Log10 sets f = log₁₀(x).
+//
+//   - If called with no arguments, the function computes log₁₀(f) in place.
+//   - If called with one argument `x`, the function computes log₁₀(x) and stores it in `f`.
+//
+// The result is correctly rounded using the receiver's RoundingMode. In particular
+// Log10 of an exactly representable power of ten, such as Exp10 of a small integer,
+// returns that integer exactly.
+func (f *Float) Log10(args ...*Float) *Float {
+	f.doinit()
+	f.markDirty()
+	x := f
+	if len(args) > 0 {
+		x = args[0]
+		x.doinit()
+	}
+	C.mpfr_log10(&f.mpfr[0], &x.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// Log10 returns log₁₀(x), using rnd.
+func Log10(x *Float, rnd Rnd) *Float {
+	f := newResult(rnd, x)
+	return f.Log10(x)
+}
+
 // Floor computes the floor of a Float and stores the result in the receiver `f`.
 // The floor of a number `x` is the largest integral value less than or equal to `x`.
 //
@@ -1491,6 +2836,7 @@ func Exp2(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Floor(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 	if len(args) == 0 {
 		C.mpfr_floor(&f.mpfr[0], &f.mpfr[0])
 	} else {
@@ -1503,8 +2849,7 @@ func (f *Float) Floor(args ...*Float) *Float {
 
 // Floor returns floor(x), using rnd
 func Floor(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Floor(x)
 }
 
@@ -1514,10 +2859,19 @@ func (f *Float) Fma(x, y, z *Float) *Float {
 	y.doinit()
 	z.doinit()
 	f.doinit()
+	f.markDirty()
 	C.mpfr_fma(&f.mpfr[0], &x.mpfr[0], &y.mpfr[0], &z.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	return f
 }
 
+// MulAddAssign sets f = f*x + c with a single rounding, using f's RoundingMode,
+// and returns f. It is the step of Horner and Clenshaw evaluation; rounding the
+// product and the sum separately can lose every significant bit when f*x and c
+// nearly cancel.
+func (f *Float) MulAddAssign(x, c *Float) *Float {
+	return f.Fma(f, x, c)
+}
+
 // Fmma sets f = (a * b) + (c * d), with the given rounding mode, and returns f.
 func (f *Float) Fmma(a, b, c, d *Float) *Float {
 	a.doinit()
@@ -1525,6 +2879,7 @@ func (f *Float) Fmma(a, b, c, d *Float) *Float {
 	c.doinit()
 	d.doinit()
 	f.doinit()
+	f.markDirty()
 	C.mpfr_fmma(&f.mpfr[0], &a.mpfr[0], &b.mpfr[0], &c.mpfr[0], &d.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	return f
 }
@@ -1536,35 +2891,238 @@ func (f *Float) Fmms(a, b, c, d *Float) *Float {
 	c.doinit()
 	d.doinit()
 	f.doinit()
+	f.markDirty()
 	C.mpfr_fmms(&f.mpfr[0], &a.mpfr[0], &b.mpfr[0], &c.mpfr[0], &d.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	return f
 }
 
+// Fmma returns (a * b) + (c * d), computed with a single rounding using rnd.
+func Fmma(a, b, c, d *Float, rnd Rnd) *Float {
+	f := newResult(rnd, a, b, c, d)
+	return f.Fmma(a, b, c, d)
+}
+
+// Fmms returns (a * b) - (c * d), computed with a single rounding using rnd.
+func Fmms(a, b, c, d *Float, rnd Rnd) *Float {
+	f := newResult(rnd, a, b, c, d)
+	return f.Fmms(a, b, c, d)
+}
+
+// Cross2 returns the 2D cross product ax*by - ay*bx of the vectors (ax, ay) and (bx, by),
+// rounded once with rnd. Because the difference of products is formed exactly before
+// rounding, the sign of the result is always correct, even when the two products nearly cancel.
+func Cross2(ax, ay, bx, by *Float, rnd Rnd) *Float {
+	return Fmms(ax, by, ay, bx, rnd)
+}
+
+// eftPair allocates the two results of an error-free transformation: Floats with the
+// larger precision of a and b, rounding to nearest.
+func eftPair(a, b *Float) (hi, lo *Float) {
+	a.doinit()
+	b.doinit()
+	prec := max(C.mpfr_get_prec(&a.mpfr[0]), C.mpfr_get_prec(&b.mpfr[0]))
+	hi, lo = NewFloat(), NewFloat()
+	for _, r := range []*Float{hi, lo} {
+		r.SetRoundMode(RoundToNearest)
+		C.mpfr_set_prec(&r.mpfr[0], prec)
+	}
+	return hi, lo
+}
+
+// TwoSum returns s = a + b rounded to nearest and the rounding error err, so that
+// a + b = s + err exactly.
+//
+// Both results have the larger precision of a and b; at that precision the error of a
+// nearest-rounded sum is always representable, which is what makes the transformation
+// error-free. It uses Knuth's branch-free six-operation algorithm. TwoSum is the building
+// block of compensated summation and double-double arithmetic. If a or b is infinite or
+// NaN, err is NaN.
+func TwoSum(a, b *Float) (s, err *Float) {
+	s, err = eftPair(a, b)
+	bv := NewFloatWithPrec(s.GetPrec())
+	defer bv.Clear()
+	av := NewFloatWithPrec(s.GetPrec())
+	defer av.Clear()
+	C.mpfr_add(&s.mpfr[0], &a.mpfr[0], &b.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_sub(&bv.mpfr[0], &s.mpfr[0], &a.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_sub(&av.mpfr[0], &s.mpfr[0], &bv.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_sub(&bv.mpfr[0], &b.mpfr[0], &bv.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_sub(&av.mpfr[0], &a.mpfr[0], &av.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_add(&err.mpfr[0], &av.mpfr[0], &bv.mpfr[0], C.MPFR_RNDN)
+	return s, err
+}
+
+// TwoProduct returns p = a * b rounded to nearest and the rounding error err, so that
+// a * b = p + err exactly.
+//
+// Both results have the larger precision of a and b. The error is a*b - p computed by a
+// single fused multiply-subtract, which is exact because the error of a nearest-rounded
+// product is representable at that precision. If a or b is infinite or NaN, err is NaN.
+func TwoProduct(a, b *Float) (p, err *Float) {
+	p, err = eftPair(a, b)
+	C.mpfr_mul(&p.mpfr[0], &a.mpfr[0], &b.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_fms(&err.mpfr[0], &a.mpfr[0], &b.mpfr[0], &p.mpfr[0], C.MPFR_RNDN)
+	return p, err
+}
+
+// orientPrec is enough bits to hold the difference of any two finite float64 values
+// exactly: their exponents span [-1074, 1023], so the difference needs at most 2099 bits.
+const orientPrec = 2112
+
+// Orient2D reports the orientation of the triangle (a, b, c) in the plane:
+// +1 if the points are in counter-clockwise order, -1 if clockwise, and 0 if collinear.
+//
+// It returns the sign of the determinant (bx-ax)*(cy-ay) - (by-ay)*(cx-ax). The coordinate
+// differences are formed exactly and the difference of products is rounded only once by Fmms,
+// so the sign is always correct, even for nearly collinear points where float64 arithmetic
+// returns the wrong answer. All inputs must be finite.
+func Orient2D(ax, ay, bx, by, cx, cy float64) int {
+	diff := func(p, q float64) *Float {
+		d := NewFloatWithPrec(orientPrec).SetFloat64(p)
+		return d.Sub(FromFloat64(q))
+	}
+	abx, aby := diff(bx, ax), diff(by, ay)
+	acx, acy := diff(cx, ax), diff(cy, ay)
+
+	det := NewFloatWithPrec(64)
+	det.Fmms(abx, acy, aby, acx)
+	return det.Sign()
+}
+
 // Fmod sets f to the floating-point remainder of x / y, with the given rounding mode, and returns f.
+// The quotient is truncated toward zero, so f has the sign of x and |f| < |y|, as with
+// C's fmod; ModGo is the same operation under the name Go users look for. Remainder
+// instead rounds the quotient to nearest, giving |f| ≤ |y|/2 with either sign.
 func (f *Float) Fmod(x, y *Float) *Float {
 	x.doinit()
 	y.doinit()
 	f.doinit()
+	f.markDirty()
 	C.mpfr_fmod(&f.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	return f
 }
 
+// ModGo sets f = x mod y exactly as math.Mod defines it and returns f: the result
+// is x - n·y for the integer n = trunc(x/y), so it has the sign of x and magnitude
+// less than |y|. For example ModGo(-5, 3) is -2, where Remainder gives 1. The
+// special cases also match math.Mod: an infinite x, a zero y or a NaN operand
+// gives NaN, and an infinite y returns x. The result is always exact when f is at
+// least as precise as x and y; otherwise it is rounded with f's RoundingMode.
+func (f *Float) ModGo(x, y *Float) *Float {
+	return f.Fmod(x, y)
+}
+
 // Fmodquo computes the remainder (f = x mod y) and also returns the integer quotient via mpfr_fmodquo.
 func (f *Float) Fmodquo(x, y *Float) (int, *Float) {
 	x.doinit()
 	y.doinit()
 	f.doinit()
+	f.markDirty()
 	var q C.long
 	C.mpfr_fmodquo(&f.mpfr[0], &q, &x.mpfr[0], &y.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	return int(q), f
 }
 
+// DivMod performs Euclidean division of x by y: it returns the integer quotient q
+// as a new Float and sets f to the remainder r = x - q·y, with 0 ≤ r < |y|
+// whatever the signs. This floors x/y for positive y and takes the ceiling for
+// negative y; Fmod truncates instead, so the two differ when x is negative, e.g.
+// DivMod(-7, 2) gives q = -4, r = 1.
+//
+// Unlike Fmodquo and Remquo, which return only the low bits of the quotient in an
+// int, q is exact however large it is: its precision is widened beyond the default
+// as needed. The remainder is rounded to f's precision with f's RoundingMode. It is
+// exact when f is at least as precise as x and y, except for a negative x much
+// smaller in magnitude than y, where x + |y| can need more bits. A NaN or infinite
+// operand, or a zero y, sets both results to NaN.
+func (f *Float) DivMod(x, y *Float) (q, r *Float) {
+	x.doinit()
+	y.doinit()
+	f.doinit()
+	f.markDirty()
+	q = NewFloat()
+	if C.mpfr_number_p(&x.mpfr[0]) == 0 || C.mpfr_number_p(&y.mpfr[0]) == 0 || C.mpfr_zero_p(&y.mpfr[0]) != 0 {
+		C.mpfr_set_nan(&q.mpfr[0])
+		C.mpfr_set_nan(&f.mpfr[0])
+		return q, f
+	}
+	if C.mpfr_zero_p(&x.mpfr[0]) != 0 {
+		C.mpfr_set_zero(&q.mpfr[0], 1)
+		C.mpfr_set_zero(&f.mpfr[0], 1)
+		return q, f
+	}
+
+	// The truncated remainder is a multiple of the finer ulp of x and y below |y|,
+	// so it fits in the wider of their precisions.
+	t := &Float{}
+	t.doinit()
+	C.mpfr_set_prec(&t.mpfr[0], max(C.mpfr_get_prec(&x.mpfr[0]), C.mpfr_get_prec(&y.mpfr[0])))
+	C.mpfr_fmod(&t.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.MPFR_RNDN)
+	if C.mpfr_zero_p(&t.mpfr[0]) != 0 {
+		C.mpfr_set_zero(&t.mpfr[0], 1)
+	} else if C.mpfr_sgn(&t.mpfr[0]) < 0 {
+		absY := &Float{}
+		absY.doinit()
+		C.mpfr_set_prec(&absY.mpfr[0], C.mpfr_get_prec(&y.mpfr[0]))
+		C.mpfr_abs(&absY.mpfr[0], &y.mpfr[0], C.MPFR_RNDN)
+		t = exactSum(t, absY, false)
+	}
+
+	// |q| ≤ |x/y| + 1 < 2^(exp x - exp y + 2), and x - r is an exact multiple of y.
+	qPrec := int64(C.mpfr_get_exp(&x.mpfr[0])) - int64(C.mpfr_get_exp(&y.mpfr[0])) + 2
+	if qPrec > int64(C.mpfr_get_prec(&q.mpfr[0])) {
+		C.mpfr_set_prec(&q.mpfr[0], C.mpfr_prec_t(qPrec))
+	}
+	d := exactSum(x, t, true)
+	C.mpfr_div(&q.mpfr[0], &d.mpfr[0], &y.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_set(&f.mpfr[0], &t.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return q, f
+}
+
+// DivMod returns the Euclidean quotient and remainder of x / y, the remainder
+// rounded with rnd. See (*Float).DivMod.
+func DivMod(x, y *Float, rnd Rnd) (q, r *Float) {
+	f := newResult(rnd, x, y)
+	return f.DivMod(x, y)
+}
+
+// exactSum returns x + y, or x - y if sub is set, in a new Float just wide enough
+// to hold it exactly. x and y must be finite.
+func exactSum(x, y *Float, sub bool) *Float {
+	s := &Float{}
+	s.doinit()
+	if C.mpfr_zero_p(&y.mpfr[0]) != 0 {
+		C.mpfr_set_prec(&s.mpfr[0], C.mpfr_get_prec(&x.mpfr[0]))
+		C.mpfr_set(&s.mpfr[0], &x.mpfr[0], C.MPFR_RNDN)
+		return s
+	}
+	if C.mpfr_zero_p(&x.mpfr[0]) != 0 {
+		C.mpfr_set_prec(&s.mpfr[0], C.mpfr_get_prec(&y.mpfr[0]))
+		if sub {
+			C.mpfr_neg(&s.mpfr[0], &y.mpfr[0], C.MPFR_RNDN)
+		} else {
+			C.mpfr_set(&s.mpfr[0], &y.mpfr[0], C.MPFR_RNDN)
+		}
+		return s
+	}
+	ex, ey := int64(C.mpfr_get_exp(&x.mpfr[0])), int64(C.mpfr_get_exp(&y.mpfr[0]))
+	lo := min(ex-int64(C.mpfr_min_prec(&x.mpfr[0])), ey-int64(C.mpfr_min_prec(&y.mpfr[0])))
+	C.mpfr_set_prec(&s.mpfr[0], C.mpfr_prec_t(max(ex, ey)+1-lo))
+	if sub {
+		C.mpfr_sub(&s.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.MPFR_RNDN)
+	} else {
+		C.mpfr_add(&s.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.MPFR_RNDN)
+	}
+	return s
+}
+
 // Fms sets f = (x * y) - z, with the given rounding mode, and returns f.
 func (f *Float) Fms(x, y, z *Float) *Float {
 	x.doinit()
 	y.doinit()
 	z.doinit()
 	f.doinit()
+	f.markDirty()
 	C.mpfr_fms(&f.mpfr[0], &x.mpfr[0], &y.mpfr[0], &z.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	return f
 }
@@ -1603,6 +3161,7 @@ func (f *Float) Fms(x, y, z *Float) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Frac(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 	if len(args) == 0 {
 		C.mpfr_frac(&f.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	} else {
@@ -1654,6 +3213,7 @@ func FreeCache() {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Gamma(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 	if len(args) == 0 {
 		C.mpfr_gamma(&f.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	} else {
@@ -1664,12 +3224,57 @@ func (f *Float) Gamma(args ...*Float) *Float {
 	return f
 }
 
+// RecGamma sets f = 1/Γ(x), or 1/Γ(f) when called without an argument, using f's
+// RoundingMode, and returns f.
+//
+// Unlike Gamma, whose poles at 0, -1, -2, … give ±Inf or NaN, the reciprocal is an
+// entire function: RecGamma returns 0 at the non-positive integers (keeping the
+// sign of a zero argument) and at +Inf, so series with Γ in a denominator can be
+// summed without special-casing the poles. NaN and -Inf give NaN.
+//
+// Γ(x) is computed with 64 guard bits before the division, so the result is
+// accurate to within an ulp but, near the zeros, not guaranteed correctly rounded.
+func (f *Float) RecGamma(args ...*Float) *Float {
+	f.doinit()
+	f.markDirty()
+	x := f
+	if len(args) > 0 {
+		x = args[0]
+		x.doinit()
+	}
+	switch {
+	case C.mpfr_zero_p(&x.mpfr[0]) != 0:
+		sign := C.int(1)
+		if C.mpfr_signbit(&x.mpfr[0]) != 0 {
+			sign = -1
+		}
+		C.mpfr_set_zero(&f.mpfr[0], sign)
+		return f
+	case C.mpfr_integer_p(&x.mpfr[0]) != 0 && C.mpfr_sgn(&x.mpfr[0]) < 0:
+		C.mpfr_set_zero(&f.mpfr[0], 1)
+		return f
+	}
+	t := &Float{}
+	t.doinit()
+	C.mpfr_set_prec(&t.mpfr[0], C.mpfr_get_prec(&f.mpfr[0])+64)
+	C.mpfr_gamma(&t.mpfr[0], &x.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_ui_div(&f.mpfr[0], 1, &t.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// RecGamma returns 1/Γ(x), using rnd.
+func RecGamma(x *Float, rnd Rnd) *Float {
+	f := newResult(rnd, x)
+	return f.RecGamma(x)
+}
+
 // GammaInc sets f = GammaInc(a, x) (the incomplete Gamma function) with
 // rounding mode rnd, and returns f.
 func (f *Float) GammaInc(a, x *Float) *Float {
 	a.doinit()
 	x.doinit()
 	f.doinit()
+	f.markDirty()
 	C.mpfr_gamma_inc(&f.mpfr[0], &a.mpfr[0], &x.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	return f
 }
@@ -1707,31 +3312,145 @@ func (f *Float) Hypot(x, y *Float) *Float {
 	x.doinit()
 	y.doinit()
 	f.doinit()
+	f.markDirty()
 	C.mpfr_hypot(&f.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	return f
 }
 
 // Hypot returns sqrt(x^2 + y^2), using rnd.
 func Hypot(x, y *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x, y)
 	return f.Hypot(x, y)
 }
 
-// IsInf returns true if f is infinite, false otherwise.
-func (f *Float) IsInf() bool {
-	f.doinit()
-	return C.mpfr_inf_p(&f.mpfr[0]) != 0
+// Scratch holds reusable Floats for the package-level helpers with a Scratch
+// variant: HypotScratch, AbsDiffScratch and LogSumExpScratch. The plain helpers
+// allocate a new result Float, with its finalizer and native storage, and any
+// internal temporaries on every call; the Scratch variants reuse the same
+// storage, so once s has been used they allocate nothing. The Hypot and
+// LogSumExp benchmarks report the allocation counts of both forms.
+//
+// A Scratch is not safe for concurrent use: give each goroutine its own.
+type Scratch struct {
+	// RoundingMode is the rounding mode used by operations given this Scratch.
+	RoundingMode Rnd
+
+	prec   uint
+	result *Float
+	tmp    [2]*Float
 }
 
-// IsInf returns true if x is infinite, false otherwise.
-func IsInf(x *Float) bool {
-	x.doinit()
-	return C.mpfr_inf_p(&x.mpfr[0]) != 0
+// NewScratch returns a Scratch whose results have precision prec and are rounded
+// with rnd.
+func NewScratch(prec uint, rnd Rnd) *Scratch {
+	return &Scratch{RoundingMode: rnd, prec: prec}
 }
 
-// J0 computes the Bessel function of the first kind of order 0, J₀(x),
-// and stores the result in the receiver `f`.
+// out returns s's result Float, allocating it on first use.
+func (s *Scratch) out() *Float {
+	if s.result == nil {
+		s.result = NewFloatWithPrec(s.prec)
+	}
+	s.result.RoundingMode = s.RoundingMode
+	return s.result
+}
+
+// temp returns s's i-th temporary with precision prec, allocating it on first use.
+func (s *Scratch) temp(i int, prec uint) *Float {
+	t := s.tmp[i]
+	if t == nil {
+		t = &Float{}
+		t.doinit()
+		s.tmp[i] = t
+	}
+	if C.mpfr_get_prec(&t.mpfr[0]) != C.mpfr_prec_t(prec) {
+		C.mpfr_set_prec(&t.mpfr[0], C.mpfr_prec_t(prec))
+	}
+	return t
+}
+
+// HypotScratch is like Hypot but writes into storage owned by s instead of
+// allocating, rounding to s's precision with s.RoundingMode. The returned Float is
+// overwritten by the next call using s; Copy it to keep the value.
+func HypotScratch(x, y *Float, s *Scratch) *Float {
+	return s.out().Hypot(x, y)
+}
+
+// AbsDiff sets f = |x - y| using f's RoundingMode and returns f. The larger
+// operand is subtracted from, so the result is rounded once and is never negative,
+// even under directed rounding. NaN operands, or equal infinities, give NaN.
+func (f *Float) AbsDiff(x, y *Float) *Float {
+	x.doinit()
+	y.doinit()
+	f.doinit()
+	f.markDirty()
+	if C.mpfr_less_p(&x.mpfr[0], &y.mpfr[0]) != 0 {
+		x, y = y, x
+	}
+	C.mpfr_sub(&f.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// AbsDiff returns |x - y|, using rnd.
+func AbsDiff(x, y *Float, rnd Rnd) *Float {
+	f := newResult(rnd, x, y)
+	return f.AbsDiff(x, y)
+}
+
+// AbsDiffScratch is like AbsDiff but writes into storage owned by s, as
+// HypotScratch does.
+func AbsDiffScratch(x, y *Float, s *Scratch) *Float {
+	return s.out().AbsDiff(x, y)
+}
+
+// IsInf returns true if f is infinite, false otherwise.
+func (f *Float) IsInf() bool {
+	f.doinit()
+	return C.mpfr_inf_p(&f.mpfr[0]) != 0
+}
+
+// IsInf returns true if x is infinite, false otherwise.
+func IsInf(x *Float) bool {
+	x.doinit()
+	return C.mpfr_inf_p(&x.mpfr[0]) != 0
+}
+
+// IsInteger returns true if f holds an exact integer value, including ±0.
+// It is false for ±Inf and NaN.
+func (f *Float) IsInteger() bool {
+	f.doinit()
+	return C.mpfr_integer_p(&f.mpfr[0]) != 0
+}
+
+// IsPowerOfTwo returns true if |f| = 2^k for some integer k, that is, if f is finite,
+// nonzero and its significand is exactly 1. Multiplying or dividing by such a value
+// is an exact exponent shift. It is false for ±0, ±Inf and NaN; -8 and 0.25 are
+// powers of two in this sense.
+func (f *Float) IsPowerOfTwo() bool {
+	f.doinit()
+	return C.mpfr_regular_p(&f.mpfr[0]) != 0 && C.mpfr_min_prec(&f.mpfr[0]) == 1
+}
+
+// IsNaN returns true if f is NaN (not-a-number), false otherwise.
+func (f *Float) IsNaN() bool {
+	f.doinit()
+	return C.mpfr_nan_p(&f.mpfr[0]) != 0
+}
+
+// IsNaN returns true if x is NaN (not-a-number), false otherwise.
+func IsNaN(x *Float) bool {
+	x.doinit()
+	return C.mpfr_nan_p(&x.mpfr[0]) != 0
+}
+
+// Signbit returns true if the sign bit of f is set, including for -0, -Inf and negative NaN.
+func (f *Float) Signbit() bool {
+	f.doinit()
+	return C.mpfr_signbit(&f.mpfr[0]) != 0
+}
+
+// J0 computes the Bessel function of the first kind of order 0, J₀(x),
+// and stores the result in the receiver `f`.
 //
 // The Bessel function of the first kind of order 0, J₀(x), is a solution
 // to the differential equation:
@@ -1768,6 +3487,7 @@ func IsInf(x *Float) bool {
 //	A pointer to the modified receiver `f`.
 func (f *Float) J0(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 	if len(args) == 0 && args[0] != nil {
 		C.mpfr_j0(&f.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	} else {
@@ -1781,8 +3501,7 @@ func (f *Float) J0(args ...*Float) *Float {
 
 // J0 computes the Bessel function of the first kind of order 0, J₀(x),
 func J0(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.J0(x)
 }
 
@@ -1824,6 +3543,7 @@ func J0(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) J1(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 	if len(args) == 0 {
 		C.mpfr_j1(&f.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	} else {
@@ -1837,8 +3557,7 @@ func (f *Float) J1(args ...*Float) *Float {
 
 // J1 computes the Bessel function of the first kind of order 1, J₁(x),
 func J1(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.J1(x)
 }
 
@@ -1846,10 +3565,102 @@ func J1(x *Float, rnd Rnd) *Float {
 func (f *Float) Jn(n int, x *Float) *Float {
 	x.doinit()
 	f.doinit()
+	f.markDirty()
 	C.mpfr_jn(&f.mpfr[0], C.long(n), &x.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	return f
 }
 
+// BesselI sets f = Iₙ(x), the modified Bessel function of the first kind of
+// integer order n, using f's RoundingMode, and returns f. MPFR has no modified
+// Bessel functions, so it sums the power series
+//
+//	Iₙ(x) = Σₘ (x/2)^(2m+n) / (m!·(m+n)!)
+//
+// with 64 guard bits plus the bit length of |x|. Every term has the same sign, so
+// the sum suffers no cancellation and the result is accurate to within an ulp, but
+// the series needs about e·|x|/2 terms before they start to shrink: the cost grows
+// linearly with |x| and arguments beyond about 10^6 are impractical.
+//
+// I₋ₙ = Iₙ and Iₙ(-x) = (-1)ⁿ·Iₙ(x); Iₙ(±Inf) is infinite with that sign rule, and
+// NaN gives NaN. The modified Bessel function of the second kind, Kₙ, is not
+// provided.
+func (f *Float) BesselI(n int, x *Float) *Float {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	if n < 0 {
+		n = -n
+	}
+	negate := x.Signbit() && n%2 == 1
+	switch {
+	case C.mpfr_nan_p(&x.mpfr[0]) != 0:
+		C.mpfr_set_nan(&f.mpfr[0])
+		return f
+	case C.mpfr_inf_p(&x.mpfr[0]) != 0:
+		sign := C.int(1)
+		if negate {
+			sign = -1
+		}
+		C.mpfr_set_inf(&f.mpfr[0], sign)
+		return f
+	case C.mpfr_zero_p(&x.mpfr[0]) != 0:
+		if n == 0 {
+			C.mpfr_set_ui(&f.mpfr[0], 1, C.MPFR_RNDN)
+		} else {
+			C.mpfr_set_zero(&f.mpfr[0], 1)
+		}
+		return f
+	}
+
+	wp := C.mpfr_prec_t(f.GetPrec() + 64)
+	if e := C.mpfr_get_exp(&x.mpfr[0]); e > 0 {
+		// About |x| terms are summed, each adding up to half an ulp of error.
+		wp += C.mpfr_prec_t(e)
+	}
+	newTemp := func() *Float {
+		t := &Float{}
+		t.doinit()
+		C.mpfr_set_prec(&t.mpfr[0], wp)
+		return t
+	}
+	half, q, term, sum := newTemp(), newTemp(), newTemp(), newTemp()
+	C.mpfr_abs(&half.mpfr[0], &x.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_mul_2si(&half.mpfr[0], &half.mpfr[0], -1, C.MPFR_RNDN)
+	C.mpfr_sqr(&q.mpfr[0], &half.mpfr[0], C.MPFR_RNDN)
+
+	// term₀ = (x/2)^n / n!
+	C.mpfr_pow_ui(&term.mpfr[0], &half.mpfr[0], C.ulong(n), C.MPFR_RNDN)
+	C.mpfr_fac_ui(&sum.mpfr[0], C.ulong(n), C.MPFR_RNDN)
+	C.mpfr_div(&term.mpfr[0], &term.mpfr[0], &sum.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_set(&sum.mpfr[0], &term.mpfr[0], C.MPFR_RNDN)
+	for m := 1; ; m++ {
+		C.mpfr_mul(&term.mpfr[0], &term.mpfr[0], &q.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_div_ui(&term.mpfr[0], &term.mpfr[0], C.ulong(m), C.MPFR_RNDN)
+		C.mpfr_div_ui(&term.mpfr[0], &term.mpfr[0], C.ulong(m+n), C.MPFR_RNDN)
+		C.mpfr_add(&sum.mpfr[0], &sum.mpfr[0], &term.mpfr[0], C.MPFR_RNDN)
+		// Once m(m+n) exceeds (x/2)² the terms decrease at least geometrically.
+		if C.mpfr_zero_p(&term.mpfr[0]) != 0 {
+			break
+		}
+		shrinking := C.mpfr_cmp_ui(&q.mpfr[0], C.ulong(m)*C.ulong(m+n)) < 0
+		if shrinking && C.mpfr_get_exp(&term.mpfr[0]) < C.mpfr_get_exp(&sum.mpfr[0])-C.mpfr_exp_t(wp) {
+			break
+		}
+	}
+	if negate {
+		C.mpfr_neg(&sum.mpfr[0], &sum.mpfr[0], C.MPFR_RNDN)
+	}
+	C.mpfr_set(&f.mpfr[0], &sum.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// BesselI returns Iₙ(x), the modified Bessel function of the first kind of order n,
+// using rnd.
+func BesselI(n int, x *Float, rnd Rnd) *Float {
+	f := newResult(rnd, x)
+	return f.BesselI(n, x)
+}
+
 // LessThan returns true if the value of f is less than x, false otherwise.
 func (f *Float) LessThan(x *Float) bool {
 	f.doinit()
@@ -1897,6 +3708,7 @@ func LessGreater(x, y *Float) bool {
 func (f *Float) Lgamma(x *Float) (int, *Float) {
 	x.doinit()
 	f.doinit()
+	f.markDirty()
 	var sign C.int
 	C.mpfr_lgamma(&f.mpfr[0], &sign, &x.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	return int(sign), f
@@ -1938,6 +3750,7 @@ func (f *Float) Lgamma(x *Float) (int, *Float) {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Li2(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 	var x *Float
 	if len(args) > 0 && args[0] != nil {
 		x = args[0]
@@ -1951,8 +3764,7 @@ func (f *Float) Li2(args ...*Float) *Float {
 
 // Li2 computes the dilogarithm function, Li₂(x),
 func Li2(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Li2(x)
 }
 
@@ -1996,6 +3808,7 @@ func Li2(x *Float, rnd Rnd) *Float {
 //	    - *Float: A pointer to the modified receiver `f`, holding the value log|Γ(x)|.
 func (f *Float) Lngamma(args ...*Float) (int, *Float) {
 	f.doinit()
+	f.markDirty()
 	var x *Float
 	if len(args) > 0 && args[0] != nil {
 		x = args[0]
@@ -2009,8 +3822,7 @@ func (f *Float) Lngamma(args ...*Float) (int, *Float) {
 
 // Lngamma computes the natural logarithm of the absolute value of the Gamma function,
 func Lngamma(x *Float, rnd Rnd) (int, *Float) {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Lngamma(x)
 }
 
@@ -2047,6 +3859,7 @@ func Lngamma(x *Float, rnd Rnd) (int, *Float) {
 //	A pointer to the modified receiver `f`, holding the maximum value among all arguments.
 func (f *Float) Max(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	// Iterate through all provided arguments and update `f` with the maximum value.
 	for _, x := range args {
@@ -2059,10 +3872,10 @@ func (f *Float) Max(args ...*Float) *Float {
 	return f
 }
 
-// MaxFloat computes the maximum value among x and y, using the given rounding mode.
+// MaxFloat sets x to the maximum of x and y, using the given rounding mode, and
+// returns x. x's RoundingMode is left unchanged.
 func MaxFloat(x, y *Float, rnd Rnd) *Float {
-	x.SetRoundMode(rnd)
-	return x.Max(y)
+	return WithRounding(x, rnd, func() *Float { return x.Max(y) })
 }
 
 // Min computes the minimum value among the receiver `f` and any number of additional Float arguments.
@@ -2098,6 +3911,7 @@ func MaxFloat(x, y *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`, holding the minimum value among all arguments.
 func (f *Float) Min(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	// Iterate through all provided arguments and update `f` with the minimum value.
 	for _, x := range args {
@@ -2110,10 +3924,10 @@ func (f *Float) Min(args ...*Float) *Float {
 	return f
 }
 
-// MinFloat computes the minimum value among x and y, using the given rounding mode.
+// MinFloat sets x to the minimum of x and y, using the given rounding mode, and
+// returns x. x's RoundingMode is left unchanged.
 func MinFloat(x, y *Float, rnd Rnd) *Float {
-	x.SetRoundMode(rnd)
-	return x.Min(y)
+	return WithRounding(x, rnd, func() *Float { return x.Min(y) })
 }
 
 // MinPrec returns the minimum of the precisions of x and y.
@@ -2130,6 +3944,76 @@ func MinPrec(x, y *Float) uint {
 	return precY
 }
 
+// WillAddBeExact reports whether x + y is representable in prec bits, that is,
+// whether computing the sum into a Float of precision prec involves no rounding in
+// any rounding mode. The predicate is exact rather than conservative: it is true
+// precisely when the sum fits. Exponent-range overflow and underflow are not
+// considered. NaN and infinite operands give true, as no rounding takes place.
+//
+// Most cases are decided from the operands' exponents and their minimal
+// precisions (see mpfr_min_prec); otherwise the sum is formed exactly, in at most
+// a few bits more than the wider operand or prec, and measured.
+func WillAddBeExact(x, y *Float, prec uint) bool {
+	x.doinit()
+	y.doinit()
+	if C.mpfr_number_p(&x.mpfr[0]) == 0 || C.mpfr_number_p(&y.mpfr[0]) == 0 {
+		return true
+	}
+	xZero := C.mpfr_zero_p(&x.mpfr[0]) != 0
+	yZero := C.mpfr_zero_p(&y.mpfr[0]) != 0
+	switch {
+	case xZero && yZero:
+		return true
+	case xZero:
+		return uint(C.mpfr_min_prec(&y.mpfr[0])) <= prec
+	case yZero:
+		return uint(C.mpfr_min_prec(&x.mpfr[0])) <= prec
+	}
+
+	// Each operand is an integer multiple of 2^lsb below 2^exp.
+	ex, ey := int64(C.mpfr_get_exp(&x.mpfr[0])), int64(C.mpfr_get_exp(&y.mpfr[0]))
+	lx := ex - int64(C.mpfr_min_prec(&x.mpfr[0]))
+	ly := ey - int64(C.mpfr_min_prec(&y.mpfr[0]))
+	hi, lo := max(ex, ey), min(lx, ly)
+	if hi+1-lo <= int64(prec) {
+		return true
+	}
+	// With distinct lowest bits the sum keeps the bit at 2^lo, and with exponents at
+	// least 2 apart no cancellation can pull its leading bit below 2^(hi-2).
+	if lx != ly && max(ex-ey, ey-ex) >= 2 && hi-1-lo > int64(prec) {
+		return false
+	}
+	t := &Float{}
+	t.doinit()
+	C.mpfr_set_prec(&t.mpfr[0], C.mpfr_prec_t(hi+1-lo))
+	C.mpfr_add(&t.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.MPFR_RNDN)
+	return C.mpfr_zero_p(&t.mpfr[0]) != 0 || uint(C.mpfr_min_prec(&t.mpfr[0])) <= prec
+}
+
+// WillMulBeExact reports whether x * y is representable in prec bits, with the
+// same exact semantics as WillAddBeExact. The product of significands of m and n
+// significant bits has m+n-1 or m+n bits, so only that boundary case needs the
+// product to be formed.
+func WillMulBeExact(x, y *Float, prec uint) bool {
+	x.doinit()
+	y.doinit()
+	if C.mpfr_regular_p(&x.mpfr[0]) == 0 || C.mpfr_regular_p(&y.mpfr[0]) == 0 {
+		return true
+	}
+	mx, my := uint(C.mpfr_min_prec(&x.mpfr[0])), uint(C.mpfr_min_prec(&y.mpfr[0]))
+	switch {
+	case mx+my <= prec:
+		return true
+	case mx+my-1 > prec:
+		return false
+	}
+	t := &Float{}
+	t.doinit()
+	C.mpfr_set_prec(&t.mpfr[0], C.mpfr_prec_t(mx+my))
+	C.mpfr_mul(&t.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.MPFR_RNDN)
+	return uint(C.mpfr_min_prec(&t.mpfr[0])) <= prec
+}
+
 // Modf splits a value into its integer and fractional parts, with rounding mode specified.
 // The result is:
 //
@@ -2156,8 +4040,8 @@ func MinPrec(x, y *Float) uint {
 //
 //	// Split a specific value using a specified rounding mode:
 //	x := NewFloat().SetFloat64(-2.8)
-//	intPart, fracPart := f.Modf(x, RndDown)
-//	// intPart is -3.0, fracPart is 0.2
+//	intPart, fracPart := f.Modf(x, RoundDown)
+//	// intPart is -2.0, fracPart is -0.8
 //
 //	// Split the receiver's value into integer and fractional parts:
 //	f.SetFloat64(2.5)
@@ -2174,6 +4058,9 @@ func MinPrec(x, y *Float) uint {
 //	Two pointers to `*Float` values: `(intPart, fracPart)`:
 //	    - `intPart`: The integer part of the value.
 //	    - `fracPart`: The fractional part of the value.
+//
+// Deprecated: the interface{} arguments are checked only at run time and panic on
+// misuse. Use ModfSelf, ModfOf or ModfRnd instead.
 func (f *Float) Modf(args ...interface{}) (intPart, fracPart *Float) {
 	intPart = NewFloat()
 	fracPart = NewFloat()
@@ -2213,11 +4100,44 @@ func (f *Float) Modf(args ...interface{}) (intPart, fracPart *Float) {
 
 // Modf splits a value into its integer and fractional parts, with rounding mode specified.
 func Modf(x *Float, rnd Rnd) (intPart, fracPart *Float) {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Modf(x)
 }
 
+// modf splits x into new integer and fractional parts with x's precision, rounding with rnd.
+func modf(x *Float, rnd Rnd) (intPart, fracPart *Float) {
+	x.doinit()
+	prec := uint(C.mpfr_get_prec(&x.mpfr[0]))
+	intPart = NewFloatWithPrec(prec)
+	fracPart = NewFloatWithPrec(prec)
+	intPart.SetRoundMode(rnd)
+	fracPart.SetRoundMode(rnd)
+	C.mpfr_modf(&intPart.mpfr[0], &fracPart.mpfr[0], &x.mpfr[0], C.mpfr_rnd_t(rnd))
+	return intPart, fracPart
+}
+
+// ModfSelf splits f into integer and fractional parts using f's rounding mode.
+//
+// Following MPFR, the integer part is f truncated toward zero and both parts
+// carry the sign of f: 3.7 splits into 3 and 0.7, -2.8 into -2 and -0.8. The
+// parts are new Floats with f's precision; f is not modified.
+func (f *Float) ModfSelf() (intPart, fracPart *Float) {
+	return modf(f, f.RoundingMode)
+}
+
+// ModfOf splits x into integer and fractional parts using f's rounding mode.
+// See ModfSelf for the sign convention. Neither f nor x is modified.
+func (f *Float) ModfOf(x *Float) (intPart, fracPart *Float) {
+	f.doinit()
+	return modf(x, f.RoundingMode)
+}
+
+// ModfRnd splits x into integer and fractional parts rounded with rnd.
+// See ModfSelf for the sign convention.
+func ModfRnd(x *Float, rnd Rnd) (intPart, fracPart *Float) {
+	return modf(x, rnd)
+}
+
 // MPMemoryCleanup releases any memory that MPFR might be caching for internal purposes.
 func MPMemoryCleanup() {
 	C.mpfr_mp_memory_cleanup()
@@ -2261,6 +4181,7 @@ func MPMemoryCleanup() {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Neg(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Negate the receiver's value in place.
@@ -2279,6 +4200,15 @@ func (f *Float) Neg(args ...*Float) *Float {
 	return f
 }
 
+// NegInPlace sets f = -f and returns f.
+// It is equivalent to f.Neg() but avoids the variadic argument handling, for use in hot loops.
+func (f *Float) NegInPlace() *Float {
+	f.doinit()
+	f.markDirty()
+	C.mpfr_neg(&f.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
 // NextAbove sets the receiver `f` to the next representable floating-point value
 // above its current value or the value of `x` (toward +∞).
 //
@@ -2311,6 +4241,7 @@ func (f *Float) Neg(args ...*Float) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) NextAbove(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Increment the receiver's value in place.
@@ -2328,11 +4259,74 @@ func (f *Float) NextAbove(args ...*Float) *Float {
 
 // NextAbove returns the next representable floating-point value above x.
 func NextAbove(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.NextAbove(x)
 }
 
+// Neighbors returns the representable values immediately below (toward -∞) and above
+// (toward +∞) f, at f's precision. f itself is not modified.
+//
+// Example Usage:
+//
+//	x := NewFloat().SetFloat64(1.5)
+//	below, above := x.Neighbors()
+//	// below is 1.5 - 2^-52, above is 1.5 + 2^-52
+func (f *Float) Neighbors() (below, above *Float) {
+	f.doinit()
+	below = f.clone()
+	C.mpfr_nextbelow(&below.mpfr[0])
+	above = f.clone()
+	C.mpfr_nextabove(&above.mpfr[0])
+	return below, above
+}
+
+// EqualULP reports whether f and x are at most maxULP representable steps apart at
+// f's precision, i.e. whether x can be reached from f by at most maxULP calls to
+// NextAbove or NextBelow. x is first rounded to nearest at f's precision if it has
+// more bits.
+//
+// The count is computed from exponents and significands rather than by stepping, so
+// it is cheap for any maxULP. Steps are counted across zero as NextAbove does: the
+// smallest positive value, 2^(emin-1), is one step above ±0. NaN is never equal to
+// anything; an infinity is equal only to the same infinity.
+func (f *Float) EqualULP(x *Float, maxULP uint) bool {
+	f.doinit()
+	x.doinit()
+	if f.IsNaN() || x.IsNaN() {
+		return false
+	}
+	if f.IsInf() || x.IsInf() {
+		return f.Cmp(x) == 0
+	}
+	prec := f.GetPrec()
+	y := NewFloat().SetPrecRnd(x, prec, RoundToNearest)
+	defer y.Clear()
+	d := new(big.Int).Sub(ulpOrdinal(f, prec), ulpOrdinal(y, prec))
+	return d.CmpAbs(new(big.Int).SetUint64(uint64(maxULP))) <= 0
+}
+
+// ulpOrdinal returns the signed index of the finite value v among the values
+// representable with prec bits in the current exponent range, with ±0 at index 0.
+func ulpOrdinal(v *Float, prec uint) *big.Int {
+	neg, m, e, kind := v.Decompose()
+	if kind == KindZero {
+		return new(big.Int)
+	}
+	// Normalize to |v| = m × 2^(E-prec) with 2^(prec-1) <= m < 2^prec; E is MPFR's exponent.
+	shift := int(prec) - m.BitLen()
+	m.Lsh(m, uint(shift))
+	exp := int64(e-shift) + int64(prec)
+
+	// index = (E - emin)·2^(prec-1) + (m - 2^(prec-1)) + 1
+	half := new(big.Int).Lsh(big.NewInt(1), prec-1)
+	idx := new(big.Int).Mul(big.NewInt(exp-int64(GetEmin())), half)
+	idx.Add(idx, m).Sub(idx, half).Add(idx, big.NewInt(1))
+	if neg {
+		idx.Neg(idx)
+	}
+	return idx
+}
+
 // NextBelow sets the receiver `f` to the next representable floating-point value
 // below its current value or the value of `x` (toward -∞).
 //
@@ -2365,6 +4359,7 @@ func NextAbove(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) NextBelow(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Decrement the receiver's value in place.
@@ -2385,8 +4380,7 @@ func (f *Float) NextBelow(args ...*Float) *Float {
 
 // NextBelow returns the next representable floating-point value below x.
 func NextBelow(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.NextBelow(x)
 }
 
@@ -2421,6 +4415,7 @@ func NextBelow(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) NextToward(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 1 && args[0] != nil {
 		// Move `f` one step toward `x`.
@@ -2441,11 +4436,75 @@ func (f *Float) NextToward(args ...*Float) *Float {
 
 // NextToward returns the next representable floating-point value toward x.
 func NextToward(x *Float, y *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x, y)
 	return f.NextToward(x, y)
 }
 
+// monotoneOrder reports whether y may follow x in a sequence that is non-decreasing
+// (increasing) or non-increasing.
+func monotoneOrder(x, y *Float, increasing bool) bool {
+	if increasing {
+		return C.mpfr_lessequal_p(&x.mpfr[0], &y.mpfr[0]) != 0
+	}
+	return C.mpfr_greaterequal_p(&x.mpfr[0], &y.mpfr[0]) != 0
+}
+
+// EnsureMonotone returns the indices i at which xs breaks monotonicity: where
+// xs[i] is below (if increasing) or above (if not) the last earlier element that
+// was itself in order. Equal neighbours are allowed. NaN elements are always
+// reported and are skipped as comparison points. A nil result means xs is monotone.
+//
+// When all elements share one precision the reported entries are exactly those
+// ClampMonotone changes. With mixed precisions ClampMonotone may change more: a
+// clamped entry of lower precision is rounded past the value it copies, which can
+// put a later, previously ordered entry out of order. Call EnsureMonotone again
+// after clamping to confirm the result.
+//
+// Rounding a monotone function to finite precision can produce such violations;
+// a table generator can recompute the reported entries at a higher precision, or
+// call ClampMonotone.
+func EnsureMonotone(xs []*Float, increasing bool) []int {
+	var bad []int
+	var prev *Float
+	for i, x := range xs {
+		x.doinit()
+		switch {
+		case C.mpfr_nan_p(&x.mpfr[0]) != 0:
+			bad = append(bad, i)
+		case prev != nil && !monotoneOrder(prev, x, increasing):
+			bad = append(bad, i)
+		default:
+			prev = x
+		}
+	}
+	return bad
+}
+
+// ClampMonotone makes xs monotone in place by carrying the previous value forward
+// over every violation. An out-of-order xs[i] is
+// set to the preceding (already clamped) element, rounded toward +Inf if increasing
+// and toward -Inf otherwise, so that when xs[i] has less precision it becomes the
+// NextAbove or NextBelow neighbour and order still holds. NaN elements are left
+// unchanged and skipped.
+func ClampMonotone(xs []*Float, increasing bool) {
+	rnd := C.mpfr_rnd_t(C.MPFR_RNDU)
+	if !increasing {
+		rnd = C.MPFR_RNDD
+	}
+	var prev *Float
+	for _, x := range xs {
+		x.doinit()
+		if C.mpfr_nan_p(&x.mpfr[0]) != 0 {
+			continue
+		}
+		if prev != nil && !monotoneOrder(prev, x, increasing) {
+			x.markDirty()
+			C.mpfr_set(&x.mpfr[0], &prev.mpfr[0], rnd)
+		}
+		prev = x
+	}
+}
+
 // RecSqrt computes the reciprocal square root, 1 / sqrt(x), and stores the result in the receiver `f`.
 //
 //   - If called with no arguments, the function computes 1 / sqrt(f), where `f` is the current value
@@ -2478,6 +4537,7 @@ func NextToward(x *Float, y *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) RecSqrt(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Compute 1 / sqrt(f) in place.
@@ -2493,8 +4553,7 @@ func (f *Float) RecSqrt(args ...*Float) *Float {
 
 // RecSqrt computes the reciprocal square root, 1 / sqrt(x),
 func RecSqrt(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.RecSqrt(x)
 }
 
@@ -2517,6 +4576,7 @@ func (f *Float) Reldiff(x, y *Float) *Float {
 	x.doinit()
 	y.doinit()
 	f.doinit()
+	f.markDirty()
 	C.mpfr_reldiff(&f.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	return f
 }
@@ -2524,42 +4584,108 @@ func (f *Float) Reldiff(x, y *Float) *Float {
 // Reldiff sets f = the relative difference between x and y, i.e. |x - y| / max(|x|, |y|),
 // using the specified rounding mode, and returns f.
 func Reldiff(x, y *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x, y)
 	return f.Reldiff(x, y)
 }
 
+// RelError returns the error of f relative to the reference value exact,
+// |f - exact| / |exact|, as a new Float with precision prec. When exact is zero the
+// absolute error |f| is returned instead.
+//
+// Unlike Reldiff, which treats its operands symmetrically, RelError is anchored to
+// exact. Each step rounds away from zero, so the result never understates the error.
+// f and exact are not modified.
+func (f *Float) RelError(exact *Float, prec uint) *Float {
+	f.doinit()
+	exact.doinit()
+	e := NewFloatWithPrec(prec)
+	e.SetRoundMode(RoundUp)
+	C.mpfr_sub(&e.mpfr[0], &f.mpfr[0], &exact.mpfr[0], C.MPFR_RNDA)
+	C.mpfr_abs(&e.mpfr[0], &e.mpfr[0], C.MPFR_RNDU)
+	if C.mpfr_zero_p(&exact.mpfr[0]) == 0 {
+		C.mpfr_div(&e.mpfr[0], &e.mpfr[0], &exact.mpfr[0], C.MPFR_RNDA)
+		C.mpfr_abs(&e.mpfr[0], &e.mpfr[0], C.MPFR_RNDU)
+	}
+	return e
+}
+
+// CorrectBits returns the number of leading bits of approx that agree with exact,
+// ⌊−log₂(|approx − exact| / |exact|)⌋, or the absolute-error form ⌊−log₂|approx|⌋
+// when exact is zero. Because it measures the relative error rather than comparing
+// rounded digits, it does not depend on the rounding mode of either argument, which
+// makes it a convenient figure for convergence tests and benchmarks.
+//
+// It returns math.MaxInt when approx equals exact and 0 when no bits agree,
+// including when either argument is NaN or only one is infinite. The error is
+// rounded upward (see RelError), so the count is never overstated.
+func CorrectBits(approx, exact *Float) int {
+	approx.doinit()
+	exact.doinit()
+	if C.mpfr_equal_p(&approx.mpfr[0], &exact.mpfr[0]) != 0 {
+		return math.MaxInt
+	}
+	r := approx.RelError(exact, 64)
+	defer r.Clear()
+	if C.mpfr_regular_p(&r.mpfr[0]) == 0 {
+		return 0
+	}
+	// r = 0.m × 2^e lies in [2^(e-1), 2^e), so −log₂ r lies in (−e, 1−e] and
+	// reaches 1−e only when r is the power of two 2^(e-1).
+	e := int(C.mpfr_get_exp(&r.mpfr[0]))
+	bits := -e
+	if C.mpfr_cmp_ui_2exp(&r.mpfr[0], 1, C.mpfr_exp_t(e-1)) == 0 {
+		bits++
+	}
+	return max(bits, 0)
+}
+
 // Remainder sets f = x - n * y, where n is an integer chosen so that f is in (-|y|/2, |y|/2].
+// n is x/y rounded to nearest, unlike Fmod and ModGo, which truncate it; so
+// Remainder(-5, 3) is 1 where ModGo(-5, 3) is -2.
 func (f *Float) Remainder(x, y *Float) *Float {
 	x.doinit()
 	y.doinit()
 	f.doinit()
+	f.markDirty()
 	C.mpfr_remainder(&f.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	return f
 }
 
 // Remainder returns x - n * y, where n is an integer chosen so that the returned value is in (-|y|/2, |y|/2],
 func Remainder(x, y *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x, y)
 	return f.Remainder(x, y)
 }
 
 // Remquo sets f = remainder of x / y, and also returns the integer quotient in an int.
 // The remainder is computed such that f is in (-|y|/2, |y|/2] (similar to mpfr_remainder).
+// See Remquo64 for the exact meaning of the returned quotient.
 func (f *Float) Remquo(x, y *Float) (int, *Float) {
+	q, r := f.Remquo64(x, y)
+	return int(q), r
+}
+
+// Remquo64 sets f = x - n * y, where n is x / y rounded to the nearest integer (ties to even),
+// and returns the low bits of n together with f.
+//
+// As with C's remquo, only part of the quotient is returned: the result has the sign of x / y
+// and its magnitude agrees with |n| in the low bits, i.e. it equals |n| modulo 2^(b-1), where b is
+// the width of a C long (63 significant bits on LP64 platforms, 31 on Windows and 32-bit systems).
+// This is enough for argument reduction (e.g. selecting the octant of an angle), but not
+// to recover n when |n| is large.
+func (f *Float) Remquo64(x, y *Float) (int64, *Float) {
 	x.doinit()
 	y.doinit()
 	f.doinit()
+	f.markDirty()
 	var q C.long
 	C.mpfr_remquo(&f.mpfr[0], &q, &x.mpfr[0], &y.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
-	return int(q), f
+	return int64(q), f
 }
 
 // Remquo returns the remainder of x / y, and also returns the integer quotient in an int.
 func Remquo(x, y *Float, rnd Rnd) (int, *Float) {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x, y)
 	return f.Remquo(x, y)
 }
 
@@ -2595,6 +4721,7 @@ func Remquo(x, y *Float, rnd Rnd) (int, *Float) {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Round(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Round the current value of the receiver `f` in place.
@@ -2611,8 +4738,7 @@ func (f *Float) Round(args ...*Float) *Float {
 
 // Round returns the nearest integer value based on the current MPFR rounding mode.
 func Round(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Round(x)
 }
 
@@ -2647,6 +4773,7 @@ func Round(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) RoundEven(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Round the current value of the receiver `f` in place using ties-to-even.
@@ -2663,11 +4790,29 @@ func (f *Float) RoundEven(args ...*Float) *Float {
 
 // RoundEven returns x rounded to the nearest integer, with ties to even.
 func RoundEven(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.RoundEven(x)
 }
 
+// DistToInt returns the signed distance from f to the nearest integer,
+// f - RoundEven(f), as a new Float with f's precision and RoundingMode; f is not
+// modified. The result lies in [-1/2, 1/2]: DistToInt(2.3) is 0.3 and
+// DistToInt(2.7) is -0.3. At a tie the nearest integer is taken to be the even
+// one, as RoundEven does, so DistToInt(2.5) is 0.5 while DistToInt(3.5) is -0.5.
+//
+// The subtraction is exact, since the fractional part of f always fits in f's
+// precision. Integers give +0, and ±Inf and NaN give NaN.
+func (f *Float) DistToInt() *Float {
+	f.doinit()
+	prec := uint(C.mpfr_get_prec(&f.mpfr[0]))
+	n := NewFloatWithPrec(prec).RoundEven(f)
+	defer n.Clear()
+	d := NewFloatWithPrec(prec)
+	d.SetRoundMode(f.RoundingMode)
+	C.mpfr_sub(&d.mpfr[0], &f.mpfr[0], &n.mpfr[0], C.MPFR_RNDN)
+	return d
+}
+
 // Sec computes the secant of a value, sec(x) = 1 / cos(x), and stores the result in the receiver `f`.
 //
 //   - If called with no arguments, the function computes sec(f), where `f` is the current value
@@ -2701,6 +4846,7 @@ func RoundEven(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Sec(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Compute sec(f) in place.
@@ -2717,8 +4863,7 @@ func (f *Float) Sec(args ...*Float) *Float {
 
 // Sec computes the secant of a value, sec(x) = 1 / cos(x).
 func Sec(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Sec(x)
 }
 
@@ -2754,6 +4899,7 @@ func Sec(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Sech(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Compute sech(f) in place.
@@ -2770,15 +4916,16 @@ func (f *Float) Sech(args ...*Float) *Float {
 
 // Sech computes the hyperbolic secant of a value, sech(x) = 1 / cosh(x).
 func Sech(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Sech(x)
 }
 
 // Swap exchanges the contents of f and x (their mantissa, sign, exponent, etc.).
 func (f *Float) Swap(x *Float) {
 	f.doinit()
+	f.markDirty()
 	x.doinit()
+	x.markDirty()
 	C.mpfr_swap(&f.mpfr[0], &x.mpfr[0])
 }
 
@@ -2815,6 +4962,7 @@ func (f *Float) Swap(x *Float) {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Tan(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Compute tan(f) in place.
@@ -2831,11 +4979,81 @@ func (f *Float) Tan(args ...*Float) *Float {
 
 // Tan computes the tangent of a value, tan(x).
 func Tan(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Tan(x)
 }
 
+// reducedTrig sets f = fn(r), where r is x reduced modulo π (or 2π if full) into
+// [-π/2, π/2] (or [-π, π]) with a π carrying prec bits beyond the integer part of x/π.
+func (f *Float) reducedTrig(x *Float, prec uint, full bool, fn func(rop, op *Float)) *Float {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	if C.mpfr_regular_p(&x.mpfr[0]) == 0 {
+		fn(f, x)
+		return f
+	}
+	wp := prec
+	if e := C.mpfr_get_exp(&x.mpfr[0]); e > 0 {
+		wp += uint(e)
+	}
+	// A local π rather than Pi: wp depends on x, and caching every size in
+	// constCache would let it grow without bound.
+	period := &Float{}
+	period.doinit()
+	defer period.Clear()
+	C.mpfr_set_prec(&period.mpfr[0], C.mpfr_prec_t(wp))
+	C.mpfr_const_pi(&period.mpfr[0], C.MPFR_RNDN)
+	if full {
+		C.mpfr_mul_2ui(&period.mpfr[0], &period.mpfr[0], 1, C.MPFR_RNDN)
+	}
+	r := NewFloatWithPrec(wp)
+	defer r.Clear()
+	C.mpfr_remainder(&r.mpfr[0], &x.mpfr[0], &period.mpfr[0], C.MPFR_RNDN)
+	fn(f, r)
+	return f
+}
+
+// TanReduced sets f = tan(x) using f's RoundingMode and returns f, first reducing x
+// modulo π into [-π/2, π/2] with a value of π accurate to prec bits beyond the
+// integer part of x/π. This keeps huge arguments such as 1e18, whose distance to the
+// nearest multiple of π is only determined by π's digits far past the decimal point,
+// accurate. prec should exceed f's precision; 64 guard bits are ample except when x
+// lies extremely close to a multiple of π/2.
+//
+// MPFR's own Tan also reduces its argument exactly, so for large x the two agree;
+// the reduced variants make the reduction step, and its precision, explicit.
+func (f *Float) TanReduced(x *Float, prec uint) *Float {
+	return f.reducedTrig(x, prec, false, func(rop, op *Float) {
+		C.mpfr_tan(&rop.mpfr[0], &op.mpfr[0], C.mpfr_rnd_t(rop.RoundingMode))
+	})
+}
+
+// CotReduced sets f = cot(x) using f's RoundingMode, reducing x modulo π as
+// TanReduced does, and returns f.
+func (f *Float) CotReduced(x *Float, prec uint) *Float {
+	return f.reducedTrig(x, prec, false, func(rop, op *Float) {
+		C.mpfr_cot(&rop.mpfr[0], &op.mpfr[0], C.mpfr_rnd_t(rop.RoundingMode))
+	})
+}
+
+// SecReduced sets f = sec(x) using f's RoundingMode and returns f, first reducing x
+// modulo 2π into [-π, π] with prec bits beyond the integer part of x/2π, as
+// TanReduced does modulo π.
+func (f *Float) SecReduced(x *Float, prec uint) *Float {
+	return f.reducedTrig(x, prec, true, func(rop, op *Float) {
+		C.mpfr_sec(&rop.mpfr[0], &op.mpfr[0], C.mpfr_rnd_t(rop.RoundingMode))
+	})
+}
+
+// CscReduced sets f = csc(x) using f's RoundingMode, reducing x modulo 2π as
+// SecReduced does, and returns f.
+func (f *Float) CscReduced(x *Float, prec uint) *Float {
+	return f.reducedTrig(x, prec, true, func(rop, op *Float) {
+		C.mpfr_csc(&rop.mpfr[0], &op.mpfr[0], C.mpfr_rnd_t(rop.RoundingMode))
+	})
+}
+
 // Tanh computes the hyperbolic tangent of a value, tanh(x), and stores the result in the receiver `f`.
 //
 //   - If called with no arguments, the function computes tanh(f), where `f` is the current value
@@ -2868,6 +5086,7 @@ func Tan(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Tanh(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Compute tanh(f) in place.
@@ -2887,11 +5106,131 @@ func (f *Float) Tanh(args ...*Float) *Float {
 
 // Tanh computes the hyperbolic tangent of a value, tanh(x).
 func Tanh(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Tanh(x)
 }
 
+// Sigmoid sets f = 1/(1+e^-x), the logistic function of x, rounded with f's RoundingMode.
+// For x >= 0 it evaluates 1/(1+e^-x) and for x < 0 the equivalent e^x/(1+e^x), so the
+// exponential never overflows: Sigmoid(1000) is 1 to working precision and Sigmoid(-1000)
+// is the tiny positive value e^-1000 rather than 0 or NaN. The intermediate steps carry
+// 64 guard bits. Sigmoid(+Inf) is 1, Sigmoid(-Inf) is +0 and NaN propagates.
+func (f *Float) Sigmoid(x *Float) *Float {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	if C.mpfr_nan_p(&x.mpfr[0]) != 0 {
+		C.mpfr_set_nan(&f.mpfr[0])
+		return f
+	}
+
+	wp := C.mpfr_get_prec(&f.mpfr[0]) + 64
+	t := &Float{}
+	t.doinit()
+	C.mpfr_set_prec(&t.mpfr[0], wp)
+	d := &Float{}
+	d.doinit()
+	C.mpfr_set_prec(&d.mpfr[0], wp)
+	if C.mpfr_signbit(&x.mpfr[0]) == 0 {
+		C.mpfr_neg(&t.mpfr[0], &x.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_exp(&t.mpfr[0], &t.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_add_ui(&d.mpfr[0], &t.mpfr[0], 1, C.MPFR_RNDN)
+		C.mpfr_ui_div(&f.mpfr[0], 1, &d.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+		return f
+	}
+	C.mpfr_exp(&t.mpfr[0], &x.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_add_ui(&d.mpfr[0], &t.mpfr[0], 1, C.MPFR_RNDN)
+	C.mpfr_div(&f.mpfr[0], &t.mpfr[0], &d.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// Sigmoid returns the logistic function 1/(1+e^-x), using rnd.
+func Sigmoid(x *Float, rnd Rnd) *Float {
+	f := newResult(rnd, x)
+	return f.Sigmoid(x)
+}
+
+// LogSigmoid sets f = log(Sigmoid(x)) = -log(1+e^-x), rounded with f's RoundingMode.
+// It is evaluated as -log1p(e^-x) for x >= 0 and x - log1p(e^x) for x < 0, so large
+// arguments of either sign neither overflow nor lose the small correction term:
+// LogSigmoid(1000) is about -e^-1000 and LogSigmoid(-1000) is about -1000.
+// LogSigmoid(+Inf) is -0, LogSigmoid(-Inf) is -Inf and NaN propagates.
+func (f *Float) LogSigmoid(x *Float) *Float {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	if C.mpfr_nan_p(&x.mpfr[0]) != 0 {
+		C.mpfr_set_nan(&f.mpfr[0])
+		return f
+	}
+
+	wp := C.mpfr_get_prec(&f.mpfr[0]) + 64
+	t := &Float{}
+	t.doinit()
+	C.mpfr_set_prec(&t.mpfr[0], wp)
+	if C.mpfr_signbit(&x.mpfr[0]) == 0 {
+		C.mpfr_neg(&t.mpfr[0], &x.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_exp(&t.mpfr[0], &t.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_log1p(&t.mpfr[0], &t.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_neg(&f.mpfr[0], &t.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+		return f
+	}
+	C.mpfr_exp(&t.mpfr[0], &x.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_log1p(&t.mpfr[0], &t.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_sub(&f.mpfr[0], &x.mpfr[0], &t.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// LogSigmoid returns log(1/(1+e^-x)), using rnd.
+func LogSigmoid(x *Float, rnd Rnd) *Float {
+	f := newResult(rnd, x)
+	return f.LogSigmoid(x)
+}
+
+// SinhCosh computes sinh(x) and cosh(x) together, which is cheaper than two separate calls.
+// The receiver `f` receives sinh(x); cosh(x) is returned in a new Float with the same
+// precision and rounding mode as `f`. Both results are correctly rounded.
+//
+// Example Usage:
+//
+//	x := NewFloat().SetFloat64(1.0)
+//	f := NewFloat()
+//	sinh, cosh := f.SinhCosh(x) // sinh == f
+func (f *Float) SinhCosh(x *Float) (sinh, cosh *Float) {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	cosh = NewFloatWithPrec(f.GetPrec())
+	cosh.SetRoundMode(f.RoundingMode)
+	C.mpfr_sinh_cosh(&f.mpfr[0], &cosh.mpfr[0], &x.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f, cosh
+}
+
+// SinhCoshTanh computes sinh(x), cosh(x) and tanh(x) with a single SinhCosh evaluation and one division.
+// The receiver `f` receives tanh(x); sinh(x) and cosh(x) are returned in new Floats with the
+// same precision and rounding mode as `f`.
+//
+// Notes:
+//   - sinh and cosh are correctly rounded. tanh is computed as sinh / cosh and so carries up to
+//     three roundings (about 1.5 ulp), whereas the Tanh method is correctly rounded; use Tanh when
+//     only tanh is needed or the last bit matters.
+//   - When cosh(x) overflows the exponent range, tanh falls back to the direct computation and is ±1.
+func (f *Float) SinhCoshTanh(x *Float) (sinh, cosh, tanh *Float) {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	sinh = NewFloatWithPrec(f.GetPrec())
+	sinh.SetRoundMode(f.RoundingMode)
+	sinh, cosh = sinh.SinhCosh(x)
+
+	if C.mpfr_inf_p(&cosh.mpfr[0]) != 0 {
+		C.mpfr_tanh(&f.mpfr[0], &x.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	} else {
+		C.mpfr_div(&f.mpfr[0], &sinh.mpfr[0], &cosh.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	}
+	return sinh, cosh, f
+}
+
 // Trunc computes the integer part of a value truncated toward zero and stores the result in the receiver `f`.
 //
 //   - If called with no arguments, the function truncates the current value of `f` toward zero.
@@ -2923,6 +5262,7 @@ func Tanh(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Trunc(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Truncate the current value of `f` in place.
@@ -2939,19 +5279,57 @@ func (f *Float) Trunc(args ...*Float) *Float {
 
 // Trunc returns the integer part of x truncated toward zero.
 func Trunc(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Trunc(x)
 }
 
-// Y0 computes the Bessel function of the second kind of order 0, Y₀(x),
-// and stores the result in the receiver `f`.
-//
-//   - If called with no arguments, the function computes Y₀(f), where `f` is the current value
-//     of the receiver. This modifies `f` in place and returns it.
-//
-//   - If called with one argument `x`, the function computes Y₀(x) and stores the result in the receiver `f`.
-//     This modifies `f` and returns it.
+// FloorChanged sets f = floor(x) and reports whether the result differs from x,
+// which is the case when x was not already an integer (or when the integer does
+// not fit in f's precision). This replaces a separate IsInteger check and
+// comparison. NaN and ±Inf are returned unchanged and report false.
+func (f *Float) FloorChanged(x *Float) (changed bool) {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	return C.mpfr_floor(&f.mpfr[0], &x.mpfr[0]) != 0
+}
+
+// CeilChanged sets f = ceil(x) and reports whether the result differs from x.
+// See FloorChanged.
+func (f *Float) CeilChanged(x *Float) (changed bool) {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	return C.mpfr_ceil(&f.mpfr[0], &x.mpfr[0]) != 0
+}
+
+// RoundChanged sets f to x rounded to the nearest integer, with halfway cases
+// away from zero as in Round, and reports whether the result differs from x.
+// See FloorChanged.
+func (f *Float) RoundChanged(x *Float) (changed bool) {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	return C.mpfr_round(&f.mpfr[0], &x.mpfr[0]) != 0
+}
+
+// TruncChanged sets f to x truncated toward zero and reports whether the result
+// differs from x. See FloorChanged.
+func (f *Float) TruncChanged(x *Float) (changed bool) {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	return C.mpfr_trunc(&f.mpfr[0], &x.mpfr[0]) != 0
+}
+
+// Y0 computes the Bessel function of the second kind of order 0, Y₀(x),
+// and stores the result in the receiver `f`.
+//
+//   - If called with no arguments, the function computes Y₀(f), where `f` is the current value
+//     of the receiver. This modifies `f` in place and returns it.
+//
+//   - If called with one argument `x`, the function computes Y₀(x) and stores the result in the receiver `f`.
+//     This modifies `f` and returns it.
 //
 // The result is computed using the rounding mode specified by the receiver `f`'s RoundingMode.
 //
@@ -2978,6 +5356,7 @@ func Trunc(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Y0(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Compute Y₀(f) in place.
@@ -2994,8 +5373,7 @@ func (f *Float) Y0(args ...*Float) *Float {
 
 // Y0 computes the Bessel function of the second kind of order 0, Y₀(x).
 func Y0(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Y0(x)
 }
 
@@ -3033,6 +5411,7 @@ func Y0(x *Float, rnd Rnd) *Float {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Y1(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Compute Y₁(f) in place.
@@ -3049,8 +5428,7 @@ func (f *Float) Y1(args ...*Float) *Float {
 
 // Y1 computes the Bessel function of the second kind of order 1, Y₁(x).
 func Y1(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Y1(x)
 }
 
@@ -3059,14 +5437,14 @@ func Y1(x *Float, rnd Rnd) *Float {
 func (f *Float) Yn(n int, x *Float) *Float {
 	x.doinit()
 	f.doinit()
+	f.markDirty()
 	C.mpfr_yn(&f.mpfr[0], C.long(n), &x.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
 	return f
 }
 
 // Yn returns Yn(n, x) (the Bessel function of the second kind of order n).
 func Yn(n int, x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Yn(n, x)
 }
 
@@ -3082,6 +5460,122 @@ func IsZero(x *Float) bool {
 	return C.mpfr_zero_p(&x.mpfr[0]) != 0
 }
 
+// IsNegZero returns true if f is -0. Cmp treats -0 and +0 as equal, as IEEE 754
+// requires; use IsNegZero and IsPosZero where the sign of zero matters, such as on
+// branch cuts or when dividing by a signed zero.
+func (f *Float) IsNegZero() bool {
+	return f.IsZero() && f.Signbit()
+}
+
+// IsPosZero returns true if f is +0.
+func (f *Float) IsPosZero() bool {
+	return f.IsZero() && !f.Signbit()
+}
+
+// SetZero sets f to +0 if sign >= 0 and to -0 if sign < 0, and returns f.
+func (f *Float) SetZero(sign int) *Float {
+	f.doinit()
+	f.markDirty()
+	s := C.int(1)
+	if sign < 0 {
+		s = -1
+	}
+	C.mpfr_set_zero(&f.mpfr[0], s)
+	return f
+}
+
+// SnapToZero sets f to +0 if |f| <= threshold and returns f; otherwise f is left
+// unchanged. It cleans the tiny residuals that iterative algorithms leave behind in
+// values that should be exactly zero. The sign of a snapped value is dropped, so a
+// -1e-40 residual becomes +0; use SnapToZeroSigned to keep it. NaN and infinite
+// values are never snapped, and a NaN threshold snaps nothing.
+func (f *Float) SnapToZero(threshold *Float) *Float {
+	return f.snapToZero(threshold, false)
+}
+
+// SnapToZeroSigned is like SnapToZero but keeps the sign of a snapped value, so a
+// -1e-40 residual becomes -0. This preserves the side from which a quantity
+// approached zero, as needed before a division or a branch cut.
+func (f *Float) SnapToZeroSigned(threshold *Float) *Float {
+	return f.snapToZero(threshold, true)
+}
+
+// snapToZero implements SnapToZero and, with keepSign, SnapToZeroSigned.
+func (f *Float) snapToZero(threshold *Float, keepSign bool) *Float {
+	threshold.doinit()
+	f.doinit()
+	f.markDirty()
+	if C.mpfr_number_p(&f.mpfr[0]) != 0 && C.mpfr_cmpabs(&f.mpfr[0], &threshold.mpfr[0]) <= 0 &&
+		C.mpfr_nan_p(&threshold.mpfr[0]) == 0 {
+		sign := C.int(1)
+		if keepSign && C.mpfr_signbit(&f.mpfr[0]) != 0 {
+			sign = -1
+		}
+		C.mpfr_set_zero(&f.mpfr[0], sign)
+	}
+	return f
+}
+
+// FlushSubnormals sets f to zero, keeping its sign, if f would be subnormal in the
+// current exponent range and returns f.
+//
+// MPFR itself has no subnormals, but IEEE 754 emulation treats a value of precision p
+// as subnormal when its exponent is below GetEmin()+p-1, since it then has fewer
+// than p significant bits available. This is the flush-to-zero behaviour of
+// hardware floating point. Zero, infinite and NaN values are unchanged.
+func (f *Float) FlushSubnormals() *Float {
+	f.doinit()
+	f.markDirty()
+	if C.mpfr_regular_p(&f.mpfr[0]) == 0 {
+		return f
+	}
+	minNormal := int64(C.mpfr_get_emin()) + int64(C.mpfr_get_prec(&f.mpfr[0])) - 1
+	if int64(C.mpfr_get_exp(&f.mpfr[0])) < minNormal {
+		sign := C.int(1)
+		if C.mpfr_signbit(&f.mpfr[0]) != 0 {
+			sign = -1
+		}
+		C.mpfr_set_zero(&f.mpfr[0], sign)
+	}
+	return f
+}
+
+// Sign returns -1 if f < 0, 0 if f is ±0 or NaN, and +1 if f > 0.
+func (f *Float) Sign() int {
+	f.doinit()
+	if C.mpfr_nan_p(&f.mpfr[0]) != 0 {
+		return 0
+	}
+	return int(C.mpfr_sgn(&f.mpfr[0]))
+}
+
+// IsPositive returns true if f > 0. It is false for ±0 and NaN.
+func (f *Float) IsPositive() bool {
+	return f.Sign() > 0
+}
+
+// SameSign reports whether x and y are both positive or both negative,
+// infinities included. A zero has neither sign, whatever its sign bit, and NaN
+// has none either, so SameSign is false whenever x or y is ±0 or NaN; use
+// Signbit to compare sign bits instead. Unlike testing x*y > 0 it cannot be
+// fooled by the product underflowing to zero or overflowing, which makes it the
+// check for whether fn(lo) and fn(hi) fail to bracket a root, as in Bisect.
+func SameSign(x, y *Float) bool {
+	sx := x.Sign()
+	return sx != 0 && sx == y.Sign()
+}
+
+// IsNegative returns true if f < 0. It is false for ±0 (including -0) and NaN.
+func (f *Float) IsNegative() bool {
+	return f.Sign() < 0
+}
+
+// IsNonNegative returns true if f >= 0. It is true for both +0 and -0, and false for NaN.
+func (f *Float) IsNonNegative() bool {
+	f.doinit()
+	return C.mpfr_nan_p(&f.mpfr[0]) == 0 && C.mpfr_sgn(&f.mpfr[0]) >= 0
+}
+
 // Zeta computes the Riemann zeta function, ζ(x), and stores the result in the receiver `f`.
 //
 //   - If called with no arguments, the function computes ζ(f), where `f` is the current value
@@ -3115,6 +5609,7 @@ func IsZero(x *Float) bool {
 //	A pointer to the modified receiver `f`.
 func (f *Float) Zeta(args ...*Float) *Float {
 	f.doinit()
+	f.markDirty()
 
 	if len(args) == 0 {
 		// Compute ζ(f) in place.
@@ -3131,21 +5626,70 @@ func (f *Float) Zeta(args ...*Float) *Float {
 
 // Zeta computes the Riemann zeta function, ζ(x).
 func Zeta(x *Float, rnd Rnd) *Float {
-	f := NewFloat()
-	f.SetRoundMode(rnd)
+	f := newResult(rnd, x)
 	return f.Zeta(x)
 }
 
-// SetPrec sets the precision of the Float to the specified number of bits.
-// This method changes the precision and clears the content of f, so the value will need to be reinitialized.
+// SetPrec sets the precision of the Float to the specified number of bits, keeping
+// its value: the change is exact when the precision grows and rounds with f's
+// RoundingMode when it shrinks.
 func (f *Float) SetPrec(prec uint) *Float {
 	f.doinit()
-	originalValue := f.String()
-	C.mpfr_set_prec(&f.mpfr[0], C.mpfr_prec_t(prec))
-	_ = f.SetString(originalValue, 10)
+	f.markDirty()
+	C.mpfr_prec_round(&f.mpfr[0], C.mpfr_prec_t(prec), C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// EnsurePrec raises f's precision to minPrec if it is currently lower, keeping the
+// value exactly, and does nothing if f already has at least minPrec bits. Library
+// code can use it to guarantee enough working precision without truncating a
+// caller's higher-precision input.
+func (f *Float) EnsurePrec(minPrec uint) *Float {
+	if f.GetPrec() < minPrec {
+		f.SetPrec(minPrec)
+	}
+	return f
+}
+
+// GetPrec returns the precision of f in bits.
+func (f *Float) GetPrec() uint {
+	f.doinit()
+	return uint(C.mpfr_get_prec(&f.mpfr[0]))
+}
+
+// LimbBits returns the number of bits in a GMP limb, the machine word MPFR stores
+// significands in: 64 on most 64-bit platforms.
+func LimbBits() int {
+	return int(C.GMP_NUMB_BITS)
+}
+
+// LimbCount returns the number of limbs holding f's significand,
+// ⌈GetPrec()/LimbBits()⌉. MPFR allocates this many whatever the value, plus one
+// word recording the allocation size, so (LimbCount()+1)·LimbBits()/8 bytes plus
+// the Float itself (56 bytes on 64-bit platforms) is the memory behind f.
+// An array of n Floats at the same precision needs n times that.
+func (f *Float) LimbCount() int {
+	prec := int(f.GetPrec())
+	return (prec + LimbBits() - 1) / LimbBits()
+}
+
+// Epsilon returns the machine epsilon for precision prec, 2^(1-prec): the distance
+// from 1 to the next larger value representable with prec bits. The result has
+// precision prec and RoundingMode rnd; it is always exact.
+func Epsilon(prec uint, rnd Rnd) *Float {
+	f := NewFloatWithPrec(prec)
+	f.SetRoundMode(rnd)
+	C.mpfr_set_ui_2exp(&f.mpfr[0], 1, C.mpfr_exp_t(1-int(prec)), C.mpfr_rnd_t(rnd))
 	return f
 }
 
+// Eps returns the machine epsilon at f's precision, as a new Float with f's
+// precision and rounding mode. See Epsilon.
+func (f *Float) Eps() *Float {
+	f.doinit()
+	return Epsilon(f.GetPrec(), f.RoundingMode)
+}
+
 // FitsIntmax returns true if f (rounded by rnd) fits in an intmax_t.
 func (f *Float) FitsIntmax() bool {
 	f.doinit()
@@ -3195,7 +5739,57 @@ func (f *Float) FitsUshort() bool {
 }
 
 // ErrInvalidString is returned when mpfr_set_str fails to parse a string.
-var ErrInvalidString = &FloatError{"invalid string for mpfr_set_str"}
+var ErrInvalidString = &FloatError{Msg: "invalid string for mpfr_set_str"}
+
+// ErrInvalidBase is returned when a numeric base outside 2 to 62 is requested.
+var ErrInvalidBase = &FloatError{Msg: "base must be between 2 and 62"}
+
+// ErrInvalidBytes is returned by SetBytesBE for input that is not in the GetBytesBE layout.
+var ErrInvalidBytes = &FloatError{Msg: "malformed GetBytesBE encoding"}
+
+// ErrNotBracketed is returned by Bisect when the function values at the ends of the
+// interval do not have opposite signs.
+var ErrNotBracketed = &FloatError{Msg: "interval does not bracket a root"}
+
+// ErrNoConvergence is returned by FixedPoint when the iteration does not settle
+// within its iteration limit or produces NaN.
+var ErrNoConvergence = &FloatError{Msg: "iteration did not converge"}
+
+// ErrUnsupported is returned by operations that the linked MPFR library was not
+// built to support; see HasFloat128 and HasDecimal64.
+var ErrUnsupported = &FloatError{Msg: "operation not supported by this MPFR build"}
+
+// ErrDomain is returned when an operation has no real result for its arguments.
+var ErrDomain = &FloatError{Msg: "argument outside the domain of the function"}
+
+// ErrRange is returned when a checked operation overflows or underflows the
+// current exponent range.
+var ErrRange = &FloatError{Msg: "result outside the exponent range"}
+
+// ErrorKind classifies the errors returned by this package.
+type ErrorKind int
+
+const (
+	ErrKindOther  ErrorKind = iota // none of the kinds below
+	ErrKindDomain                  // an argument outside the domain of the operation
+	ErrKindRange                   // a result that overflows or underflows
+	ErrKindParse                   // malformed textual or binary input
+)
+
+// MPFRError is implemented by every error this package returns, directly or
+// wrapped, so callers can branch on the kind of failure with errors.As:
+//
+//	var me mpfr.MPFRError
+//	if errors.As(err, &me) && me.Kind() == mpfr.ErrKindRange {
+//		// retry with a wider exponent range
+//	}
+//
+// To test for one specific condition, compare against the sentinels such as
+// ErrDomain with errors.Is instead.
+type MPFRError interface {
+	error
+	Kind() ErrorKind
+}
 
 // FloatError is a simple error type for mpfr-related errors.
 type FloatError struct {
@@ -3206,6 +5800,21 @@ func (e *FloatError) Error() string {
 	return e.Msg
 }
 
+// Kind returns the category of e. It is determined by which of the package's
+// sentinel errors e is, so FloatError keeps its single Msg field; any other
+// FloatError is ErrKindOther.
+func (e *FloatError) Kind() ErrorKind {
+	switch e {
+	case ErrDomain, ErrInvalidBase, ErrNotBracketed, ErrInvalidExponentRange:
+		return ErrKindDomain
+	case ErrRange:
+		return ErrKindRange
+	case ErrInvalidString, ErrInvalidBytes:
+		return ErrKindParse
+	}
+	return ErrKindOther
+}
+
 // FromInt initializes an MPFR Float from a Go int.
 func FromInt(value int) *Float {
 	f := NewFloat()
@@ -3250,6 +5859,28 @@ func FromFloat64(value float64) *Float {
 	return f
 }
 
+// FromFloat64Slice returns a new Float with precision prec for each value in vs.
+// Each conversion is exact when prec >= 53.
+func FromFloat64Slice(vs []float64, prec uint) []*Float {
+	out := make([]*Float, len(vs))
+	for i, v := range vs {
+		out[i] = NewFloatWithPrec(prec).SetFloat64(v)
+	}
+	return out
+}
+
+// ToFloat64Slice returns the float64 approximation of each Float in xs, as computed by GetFloat64.
+// Unlike Float64, the Floats are left intact. Each element is rounded according to its own
+// RoundingMode; values beyond the float64 range become ±Inf (or ±MaxFloat64 when rounding
+// toward zero), and values too small become ±0 or a subnormal.
+func ToFloat64Slice(xs []*Float) []float64 {
+	out := make([]float64, len(xs))
+	for i, x := range xs {
+		out[i] = x.GetFloat64()
+	}
+	return out
+}
+
 // FromBigInt initializes an MPFR Float from a math/big.Int.
 // TODO: needs a better implementation that doesn't rely on string conversion
 func FromBigInt(value *big.Int) *Float {
@@ -3272,6 +5903,8 @@ func FromBigInt(value *big.Int) *Float {
 }
 
 // FromBigFloat initializes an MPFR Float from a math/big.Float.
+// The new Float takes value's rounding mode, mapped by RndFromBig, and uses it
+// for the conversion.
 // TODO: needs a better implementation that doesn't rely on string conversion
 func FromBigFloat(value *big.Float) *Float {
 	f := NewFloat()
@@ -3279,6 +5912,7 @@ func FromBigFloat(value *big.Float) *Float {
 		C.mpfr_set_zero(&f.mpfr[0], 1) // Initialize to zero
 		return f
 	}
+	f.RoundingMode = RndFromBig(value.Mode())
 
 	// Convert math/big.Float to a string, then parse with MPFR
 	str := value.Text('g', -1) // Decimal format
@@ -3297,9 +5931,33 @@ func (f *Float) SetRoundMode(rnd Rnd) {
 	f.RoundingMode = rnd
 }
 
+// WithRound runs fn with f's RoundingMode set to rnd and then restores the previous
+// mode, even if fn panics. Operations inside fn that write into f round with rnd.
+func (f *Float) WithRound(rnd Rnd, fn func()) {
+	prev := f.RoundingMode
+	defer func() { f.RoundingMode = prev }()
+	f.RoundingMode = rnd
+	fn()
+}
+
+// WithRounding is WithRound for functions that return a value: it runs fn with f's
+// RoundingMode set to rnd, restores the previous mode, even if fn panics, and returns
+// fn's result. The package-level Add, Sub, Mul, Div, MaxFloat and MinFloat use it so
+// that the rnd they are given does not stick to the operand they update:
+//
+//	sum := mpfr.WithRounding(x, mpfr.RoundUp, func() *mpfr.Float { return x.Add(y) })
+func WithRounding[T any](f *Float, rnd Rnd, fn func() T) T {
+	// Initialize first: initialization resets RoundingMode to the default.
+	f.doinit()
+	var result T
+	f.WithRound(rnd, func() { result = fn() })
+	return result
+}
+
 // SetInt sets the value of the Float to the specified int.
 func (f *Float) SetInt(value int) *Float {
 	f.doinit()
+	f.markDirty()
 	C.mpfr_set_si(&f.mpfr[0], C.long(value), C.mpfr_rnd_t(f.RoundingMode))
 	return f
 }
@@ -3307,6 +5965,7 @@ func (f *Float) SetInt(value int) *Float {
 // SetInt64 sets the value of the Float to the specified int64.
 func (f *Float) SetInt64(value int64) *Float {
 	f.doinit()
+	f.markDirty()
 	if value >= math.MinInt32 && value <= math.MaxInt32 {
 		// Use mpfr_set_si directly for smaller values
 		C.mpfr_set_si(&f.mpfr[0], C.long(value), C.mpfr_rnd_t(f.RoundingMode))
@@ -3318,10 +5977,25 @@ func (f *Float) SetInt64(value int64) *Float {
 	return f
 }
 
+// SetFrac64 sets f to num/den and returns f.
+//
+// Both integers are held exactly and divided once, so the result is correctly
+// rounded to f's precision using f's RoundingMode; it is exact when num/den is a
+// dyadic fraction that fits, such as 3/8. A zero den gives ±Inf, or NaN for 0/0.
+func (f *Float) SetFrac64(num, den int64) *Float {
+	f.doinit()
+	f.markDirty()
+	n := NewFloatWithPrec(64).SetInt64(num)
+	d := NewFloatWithPrec(64).SetInt64(den)
+	C.mpfr_div(&f.mpfr[0], &n.mpfr[0], &d.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
 // SetUint64 sets the value of the Float to the specified uint64.
 // TODO: needs a better implementation that doesn't rely on string conversion
 func (f *Float) SetUint64(value uint64) *Float {
 	f.doinit()
+	f.markDirty()
 	if value <= math.MaxUint32 {
 		// Use mpfr_set_ui directly for smaller values
 		C.mpfr_set_ui(&f.mpfr[0], C.ulong(value), C.mpfr_rnd_t(f.RoundingMode))
@@ -3335,6 +6009,7 @@ func (f *Float) SetUint64(value uint64) *Float {
 
 func (f *Float) SetFloat64(value float64) *Float {
 	f.doinit()
+	f.markDirty()
 	C.mpfr_set_d(&f.mpfr[0], C.double(value), C.mpfr_rnd_t(f.RoundingMode))
 	return f
 }
@@ -3343,6 +6018,7 @@ func (f *Float) SetFloat64(value float64) *Float {
 // TODO: needs a better implementation that doesn't rely on string conversion
 func (f *Float) SetBigInt(value *big.Int) *Float {
 	f.doinit()
+	f.markDirty()
 	if value == nil {
 		C.mpfr_set_zero(&f.mpfr[0], 1) // Set to zero if nil
 		return f
@@ -3363,6 +6039,7 @@ func (f *Float) SetBigInt(value *big.Int) *Float {
 // TODO: needs a better implementation that doesn't rely on string conversion
 func (f *Float) SetBigFloat(value *big.Float) *Float {
 	f.doinit()
+	f.markDirty()
 	if value == nil {
 		C.mpfr_set_zero(&f.mpfr[0], 1) // Set to zero if nil
 		return f
@@ -3395,6 +6072,25 @@ func (f *Float) Uint64() uint64 {
 	return uint64(C.mpfr_get_ui(&f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode)))
 }
 
+// Int64Saturating returns f rounded to an integer with f's RoundingMode and
+// clamped to the int64 range: values above math.MaxInt64, including +Inf, give
+// math.MaxInt64, and values below math.MinInt64, including -Inf, give
+// math.MinInt64. NaN gives 0. Clamping is the intended contract; check
+// FitsIntmax first if overflow must be detected. Unlike Int64, f is not cleared.
+func (f *Float) Int64Saturating() int64 {
+	f.doinit()
+	return int64(C.mpfr_get_sj(&f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode)))
+}
+
+// Uint64Saturating returns f rounded to an integer with f's RoundingMode and
+// clamped to the uint64 range: negative values, including -Inf, give 0 and values
+// above math.MaxUint64, including +Inf, give math.MaxUint64. NaN gives 0.
+// Unlike Uint64, f is not cleared.
+func (f *Float) Uint64Saturating() uint64 {
+	f.doinit()
+	return uint64(C.mpfr_get_uj(&f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode)))
+}
+
 // Float64 converts the Float to a float64.
 // After the conversion, the Float is cleared to conserve memory.
 func (f *Float) Float64() float64 {
@@ -3480,3 +6176,2216 @@ func (f *Float) BigFloat(result *big.Float) {
 	// Parse the formatted mantissa into a big.Float
 	result.SetString(mantissa)
 }
+
+// Flags is a bit set of MPFR's global exception flags.
+type Flags uint
+
+const (
+	FlagUnderflow Flags = Flags(C.MPFR_FLAGS_UNDERFLOW)
+	FlagOverflow  Flags = Flags(C.MPFR_FLAGS_OVERFLOW)
+	FlagNaN       Flags = Flags(C.MPFR_FLAGS_NAN)
+	FlagInexact   Flags = Flags(C.MPFR_FLAGS_INEXACT)
+	FlagErange    Flags = Flags(C.MPFR_FLAGS_ERANGE)
+	FlagDivByZero Flags = Flags(C.MPFR_FLAGS_DIVBY0)
+	FlagsAll      Flags = Flags(C.MPFR_FLAGS_ALL)
+)
+
+// ErrInvalidExponentRange is returned when an emin or emax value is outside the range MPFR supports.
+var ErrInvalidExponentRange = &FloatError{Msg: "exponent bound outside the supported range"}
+
+// GetFlags returns the current set of MPFR exception flags.
+func GetFlags() Flags {
+	return Flags(C.mpfr_flags_save())
+}
+
+// SetFlags raises the flags in mask, leaving the others untouched.
+func SetFlags(mask Flags) {
+	C.mpfr_flags_set(C.mpfr_flags_t(mask))
+}
+
+// ClearFlags lowers the flags in mask, leaving the others untouched.
+func ClearFlags(mask Flags) {
+	C.mpfr_flags_clear(C.mpfr_flags_t(mask))
+}
+
+// checkRange runs op with the overflow, underflow and NaN flags lowered, reports
+// whether op raised overflow or underflow, and calls the hooks set by SetNaNTrap
+// and SetOverflowTrap if op raised theirs. The caller's flags are restored
+// afterwards, even if op or a hook panics, so the sticky global state is left
+// exactly as it was.
+func checkRange(op func()) (overflow, underflow bool) {
+	saved := C.mpfr_flags_save()
+	defer C.mpfr_flags_restore(saved, C.MPFR_FLAGS_ALL)
+	C.mpfr_flags_clear(C.MPFR_FLAGS_OVERFLOW | C.MPFR_FLAGS_UNDERFLOW | C.MPFR_FLAGS_NAN)
+	op()
+	after := Flags(C.mpfr_flags_save())
+	overflow, underflow = after&FlagOverflow != 0, after&FlagUnderflow != 0
+
+	trapMu.Lock()
+	onNaN, onOverflow := nanTrap, overflowTrap
+	trapMu.Unlock()
+	if after&FlagNaN != 0 && onNaN != nil {
+		onNaN()
+	}
+	if overflow && onOverflow != nil {
+		onOverflow()
+	}
+	return overflow, underflow
+}
+
+var (
+	trapMu       sync.Mutex
+	nanTrap      func()
+	overflowTrap func()
+)
+
+// SetNaNTrap registers hook to be called, on the calling goroutine, whenever a
+// checked operation produces NaN, including a NaN carried through from an
+// operand. The hook may panic or log a stack trace to find where a NaN first
+// appears in a long computation. A nil hook, the default, removes the trap.
+//
+// MPFR has no trap mechanism of its own, so only the operations that already
+// inspect the flags honour traps: AddChecked, SubChecked, MulChecked,
+// QuoChecked, ExpChecked, QuoErr, SqrtChecked and RootN. Plain arithmetic such
+// as Add never calls the hook. Like SetResultPrecMode, the trap is process-wide.
+func SetNaNTrap(hook func()) {
+	trapMu.Lock()
+	nanTrap = hook
+	trapMu.Unlock()
+}
+
+// SetOverflowTrap registers hook to be called whenever a checked operation
+// overflows, with the same scope and rules as SetNaNTrap. A nil hook removes it.
+func SetOverflowTrap(hook func()) {
+	trapMu.Lock()
+	overflowTrap = hook
+	trapMu.Unlock()
+}
+
+// AddChecked sets f = x + y using f's RoundingMode and reports whether that
+// operation alone overflowed or underflowed. Unlike reading GetFlags, the result
+// is not affected by earlier operations, and the global flags are left unchanged.
+// Like the flags themselves, it is only meaningful within one OS thread.
+func (f *Float) AddChecked(x, y *Float) (overflow, underflow bool) {
+	x.doinit()
+	y.doinit()
+	f.doinit()
+	f.markDirty()
+	return checkRange(func() {
+		C.mpfr_add(&f.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	})
+}
+
+// SubChecked sets f = x - y and reports overflow and underflow as AddChecked does.
+func (f *Float) SubChecked(x, y *Float) (overflow, underflow bool) {
+	x.doinit()
+	y.doinit()
+	f.doinit()
+	f.markDirty()
+	return checkRange(func() {
+		C.mpfr_sub(&f.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	})
+}
+
+// MulChecked sets f = x * y and reports overflow and underflow as AddChecked does.
+func (f *Float) MulChecked(x, y *Float) (overflow, underflow bool) {
+	x.doinit()
+	y.doinit()
+	f.doinit()
+	f.markDirty()
+	return checkRange(func() {
+		C.mpfr_mul(&f.mpfr[0], &x.mpfr[0], &y.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	})
+}
+
+// QuoChecked sets f = x / y and reports overflow and underflow as AddChecked does.
+// Like Quo, it panics if y is zero.
+func (f *Float) QuoChecked(x, y *Float) (overflow, underflow bool) {
+	return checkRange(func() { f.Quo(x, y) })
+}
+
+// ExpChecked sets f = e^x and reports overflow and underflow as AddChecked does.
+func (f *Float) ExpChecked(x *Float) (overflow, underflow bool) {
+	return checkRange(func() { f.Exp(x) })
+}
+
+// PrecMode selects the precision of the result allocated by package-level
+// functions such as Sqrt, Quo and Hypot. The zero value is PrecDefault.
+type PrecMode struct {
+	kind precModeKind
+	prec uint
+}
+
+type precModeKind int
+
+const (
+	precDefault precModeKind = iota
+	precMaxOperand
+	precFixed
+)
+
+var (
+	// PrecDefault gives results the global default precision (see SetDefaultPrec).
+	PrecDefault = PrecMode{kind: precDefault}
+	// PrecMaxOperand gives results the largest precision among the operands, so
+	// high-precision inputs are not silently rounded to the default precision.
+	PrecMaxOperand = PrecMode{kind: precMaxOperand}
+)
+
+// PrecFixed gives results exactly prec bits, whatever the operands' precisions.
+// PrecFixed(0) behaves like PrecDefault. Other values outside MPFR's supported
+// range are clamped to it, since mpfr_set_prec aborts the process on them.
+func PrecFixed(prec uint) PrecMode {
+	if prec != 0 {
+		prec = min(max(prec, uint(C.MPFR_PREC_MIN)), uint(C.MPFR_PREC_MAX))
+	}
+	return PrecMode{kind: precFixed, prec: prec}
+}
+
+var (
+	resultPrecMu   sync.Mutex
+	resultPrecMode PrecMode
+)
+
+// SetResultPrecMode sets how package-level functions size the Floats they return.
+// It does not affect methods, which always write into the receiver at the
+// receiver's precision, nor NewFloat. Nor does it affect the package-level Add,
+// Sub, Mul and Div, which store into their first operand rather than allocating.
+//
+// In particular NewFloat().Add(x, y) is out of scope: its receiver is allocated by
+// NewFloat at the default precision before Add sees x and y, so it still rounds
+// two 200-bit operands to 53 bits in every mode. Use Sum(x, y, rnd) to get a sum
+// sized by the mode.
+//
+// Like SetDefaultPrec it is a process-wide
+// setting: it is safe to call concurrently, but it changes the behaviour of every
+// goroutine, so set it once at start-up rather than toggling it around calls.
+func SetResultPrecMode(mode PrecMode) {
+	resultPrecMu.Lock()
+	resultPrecMode = mode
+	resultPrecMu.Unlock()
+}
+
+// GetResultPrecMode returns the mode set by SetResultPrecMode.
+func GetResultPrecMode() PrecMode {
+	resultPrecMu.Lock()
+	defer resultPrecMu.Unlock()
+	return resultPrecMode
+}
+
+// newResult allocates the result of a package-level function of operands, sized
+// according to the result precision mode, with rounding mode rnd.
+func newResult(rnd Rnd, operands ...*Float) *Float {
+	f := NewFloat()
+	f.SetRoundMode(rnd)
+	mode := GetResultPrecMode()
+	var prec C.mpfr_prec_t
+	switch mode.kind {
+	case precFixed:
+		prec = C.mpfr_prec_t(mode.prec)
+	case precMaxOperand:
+		for _, x := range operands {
+			x.doinit()
+			prec = max(prec, C.mpfr_get_prec(&x.mpfr[0]))
+		}
+	}
+	if prec != 0 {
+		C.mpfr_set_prec(&f.mpfr[0], prec)
+	}
+	return f
+}
+
+// GetDefaultPrec returns the precision, in bits, used for newly initialized Floats.
+func GetDefaultPrec() uint {
+	return uint(C.mpfr_get_default_prec())
+}
+
+// SetDefaultPrec sets the precision, in bits, used for newly initialized Floats.
+// Values outside MPFR's supported range are clamped to it, as for PrecFixed, since
+// MPFR aborts the process on them.
+func SetDefaultPrec(prec uint) {
+	prec = min(max(prec, uint(C.MPFR_PREC_MIN)), uint(C.MPFR_PREC_MAX))
+	C.mpfr_set_default_prec(C.mpfr_prec_t(prec))
+}
+
+// GetDefaultRound returns MPFR's global default rounding mode.
+func GetDefaultRound() Rnd {
+	return Rnd(C.mpfr_get_default_rounding_mode())
+}
+
+// SetDefaultRound sets MPFR's global default rounding mode. Floats initialized
+// afterwards, including by NewFloat, start with rnd as their RoundingMode; existing
+// Floats keep theirs. The initial default is RoundToNearest.
+func SetDefaultRound(rnd Rnd) {
+	C.mpfr_set_default_rounding_mode(C.mpfr_rnd_t(rnd))
+}
+
+// WithDefaultRound runs fn with MPFR's global default rounding mode set to rnd and
+// then restores the previous default, even if fn panics. Floats created inside fn,
+// including by NewFloat, start with rnd as their RoundingMode. The default is
+// global or thread-local MPFR state; see State for what that means for goroutines.
+func WithDefaultRound(rnd Rnd, fn func()) {
+	prev := GetDefaultRound()
+	defer SetDefaultRound(prev)
+	SetDefaultRound(rnd)
+	fn()
+}
+
+// GetEmin returns the smallest exponent currently allowed for a Float.
+func GetEmin() int {
+	return int(C.mpfr_get_emin())
+}
+
+// SetEmin sets the smallest exponent allowed for a Float.
+// It returns ErrInvalidExponentRange if e is not supported by MPFR.
+func SetEmin(e int) error {
+	if C.mpfr_set_emin(C.mpfr_exp_t(e)) != 0 {
+		return ErrInvalidExponentRange
+	}
+	return nil
+}
+
+// GetEmax returns the largest exponent currently allowed for a Float.
+func GetEmax() int {
+	return int(C.mpfr_get_emax())
+}
+
+// SetEmax sets the largest exponent allowed for a Float.
+// It returns ErrInvalidExponentRange if e is not supported by MPFR.
+func SetEmax(e int) error {
+	if C.mpfr_set_emax(C.mpfr_exp_t(e)) != 0 {
+		return ErrInvalidExponentRange
+	}
+	return nil
+}
+
+// State is a snapshot of MPFR's global configuration: the exception flags,
+// the exponent range, the default precision and the default rounding mode.
+//
+// MPFR keeps all of these in global (or thread-local) storage, so library code
+// that changes them should save the caller's state first and restore it when done:
+//
+//	func compute() {
+//		defer mpfr.SaveState().Restore()
+//		mpfr.SetDefaultPrec(256)
+//		mpfr.ClearFlags(mpfr.FlagsAll)
+//		// ... work in an isolated configuration ...
+//	}
+//
+// Notes:
+//   - If MPFR was built with thread-local storage, the state belongs to the OS thread.
+//     Use runtime.LockOSThread around the Save/Restore pair so the goroutine does not migrate.
+type State struct {
+	flags C.mpfr_flags_t
+	emin  C.mpfr_exp_t
+	emax  C.mpfr_exp_t
+	prec  C.mpfr_prec_t
+	rnd   C.mpfr_rnd_t
+}
+
+// SaveState captures the current MPFR flags, exponent range, default precision and default rounding mode.
+func SaveState() State {
+	return State{
+		flags: C.mpfr_flags_save(),
+		emin:  C.mpfr_get_emin(),
+		emax:  C.mpfr_get_emax(),
+		prec:  C.mpfr_get_default_prec(),
+		rnd:   C.mpfr_get_default_rounding_mode(),
+	}
+}
+
+// Restore reinstates the configuration captured by SaveState.
+func (s State) Restore() {
+	C.mpfr_set_emin(s.emin)
+	C.mpfr_set_emax(s.emax)
+	C.mpfr_set_default_prec(s.prec)
+	C.mpfr_set_default_rounding_mode(s.rnd)
+	C.mpfr_flags_restore(s.flags, C.MPFR_FLAGS_ALL)
+}
+
+// Kind classifies the value held by a Float.
+type Kind int
+
+const (
+	KindZero   Kind = iota // +0 or -0
+	KindFinite             // finite and non-zero
+	KindInf                // +Inf or -Inf
+	KindNaN                // not-a-number
+)
+
+// Decompose splits f into its sign, an integer mantissa and a binary exponent, such that
+// for finite values |f| = mantissa * 2^exp. The mantissa is always non-negative; the sign
+// is reported separately in neg so that -0, -Inf and negative NaN keep their sign.
+//
+// For KindZero the mantissa is 0 and exp is 0. For KindInf and KindNaN the mantissa is nil
+// and exp is 0.
+//
+// The result is the canonical input for Compose, or for ComposeSpecial when f is
+// infinite or NaN, which rebuild the same value:
+//
+//	neg, m, exp, kind := x.Decompose()
+//	var y *Float
+//	if kind == KindInf || kind == KindNaN {
+//		y = ComposeSpecial(neg, kind, prec)
+//	} else {
+//		y = Compose(neg, m, exp, prec)
+//	}
+func (f *Float) Decompose() (neg bool, mantissa *big.Int, exp int, kind Kind) {
+	f.doinit()
+	neg = C.mpfr_signbit(&f.mpfr[0]) != 0
+
+	switch {
+	case C.mpfr_nan_p(&f.mpfr[0]) != 0:
+		return neg, nil, 0, KindNaN
+	case C.mpfr_inf_p(&f.mpfr[0]) != 0:
+		return neg, nil, 0, KindInf
+	case C.mpfr_zero_p(&f.mpfr[0]) != 0:
+		return neg, new(big.Int), 0, KindZero
+	}
+
+	var z C.mpz_t
+	C.mpz_init(&z[0])
+	defer C.mpz_clear(&z[0])
+	e := C.mpfr_get_z_2exp(&z[0], &f.mpfr[0])
+	return neg, mpzToBigInt(&z[0]), int(e), KindFinite
+}
+
+// ScientificParts splits f into a mantissa and a base-base exponent with
+// f = mantissa · base^exp and 1 ≤ |mantissa| < base; the mantissa carries f's
+// sign. In base 10 this is the d.dddd × 10ⁿ form of scientific notation, so 12345
+// gives (1.2345, 4) and -0.00123 gives (-1.23, -3).
+//
+// exp is exact, found by comparing |f| with exact powers of base rather than from
+// a logarithm. The mantissa is a new Float with f's precision and RoundingMode,
+// holding f / base^exp rounded once; it is exact for power-of-two bases and
+// whenever the quotient fits. Should that rounding reach base itself, as 9.9999…
+// can, the result is normalized to (±1, exp+1). Zeros give (±0, 0), and ±Inf and
+// NaN give a copy of f and 0. ScientificParts panics with ErrInvalidBase if base is
+// outside 2 to 62.
+func (f *Float) ScientificParts(base int) (mantissa *Float, exp int) {
+	if base < 2 || base > 62 {
+		panic(ErrInvalidBase)
+	}
+	f.doinit()
+	prec := C.mpfr_get_prec(&f.mpfr[0])
+	mantissa = NewFloat()
+	mantissa.SetRoundMode(f.RoundingMode)
+	C.mpfr_set_prec(&mantissa.mpfr[0], prec)
+	if C.mpfr_regular_p(&f.mpfr[0]) == 0 {
+		C.mpfr_set(&mantissa.mpfr[0], &f.mpfr[0], C.MPFR_RNDN)
+		return mantissa, 0
+	}
+
+	exp = f.floorLogAbs(uint(base))
+
+	var z C.mpz_t
+	C.mpz_init(&z[0])
+	defer C.mpz_clear(&z[0])
+	rnd := C.mpfr_rnd_t(f.RoundingMode)
+	C.mpz_ui_pow_ui(&z[0], C.ulong(base), C.ulong(max(exp, -exp)))
+	if exp >= 0 {
+		C.mpfr_div_z(&mantissa.mpfr[0], &f.mpfr[0], &z[0], rnd)
+	} else {
+		C.mpfr_mul_z(&mantissa.mpfr[0], &f.mpfr[0], &z[0], rnd)
+	}
+	if C.mpfr_cmpabs_ui(&mantissa.mpfr[0], C.ulong(base)) == 0 {
+		C.mpfr_set_si(&mantissa.mpfr[0], C.long(C.mpfr_sgn(&mantissa.mpfr[0])), C.MPFR_RNDN)
+		exp++
+	}
+	return mantissa, exp
+}
+
+// floorLogAbs returns floor(log_base |f|) for a regular (finite, nonzero) f, exactly.
+func (f *Float) floorLogAbs(base uint) int {
+	prec := C.mpfr_get_prec(&f.mpfr[0])
+	// |f| lies in [2^(E-1), 2^E), so exp is within one of (E-1)·log_base(2).
+	e2 := float64(C.mpfr_get_exp(&f.mpfr[0]) - 1)
+	exp := int(math.Floor(e2 / math.Log2(float64(base))))
+
+	var z C.mpz_t
+	C.mpz_init(&z[0])
+	defer C.mpz_clear(&z[0])
+	t := &Float{}
+	t.doinit()
+	// cmpPow compares |f| with base^e exactly, by scaling f by base^-e when e < 0.
+	cmpPow := func(e int) int {
+		C.mpz_ui_pow_ui(&z[0], C.ulong(base), C.ulong(max(e, -e)))
+		zBits := C.mpfr_prec_t(C.mpz_sizeinbase(&z[0], 2))
+		if e >= 0 {
+			C.mpfr_set_prec(&t.mpfr[0], zBits)
+			C.mpfr_set_z(&t.mpfr[0], &z[0], C.MPFR_RNDN)
+			return int(C.mpfr_cmpabs(&f.mpfr[0], &t.mpfr[0]))
+		}
+		C.mpfr_set_prec(&t.mpfr[0], prec+zBits)
+		C.mpfr_mul_z(&t.mpfr[0], &f.mpfr[0], &z[0], C.MPFR_RNDN)
+		return int(C.mpfr_cmpabs_ui(&t.mpfr[0], 1))
+	}
+	for cmpPow(exp) < 0 {
+		exp--
+	}
+	for cmpPow(exp+1) >= 0 {
+		exp++
+	}
+	return exp
+}
+
+// FloorLogB returns floor(log_base(f)), the integer k with base^k <= f < base^(k+1).
+//
+// The result is exact: it is found by comparing f against powers of base in integer
+// arithmetic rather than by rounding a logarithm, so exact powers are never off by
+// one. FloorLogB(1000, 10) is 3 and FloorLogB(999, 10) is 2; for base 2 it is the
+// position of the leading bit. If f is zero, negative or NaN the logarithm is
+// undefined and FloorLogB returns math.MinInt; for +Inf it returns math.MaxInt.
+// It panics if base < 2.
+func (f *Float) FloorLogB(base uint) int {
+	if base < 2 {
+		panic("FloorLogB requires base >= 2")
+	}
+	f.doinit()
+	switch {
+	case C.mpfr_nan_p(&f.mpfr[0]) != 0 || C.mpfr_sgn(&f.mpfr[0]) <= 0:
+		return math.MinInt
+	case C.mpfr_inf_p(&f.mpfr[0]) != 0:
+		return math.MaxInt
+	}
+	return f.floorLogAbs(base)
+}
+
+// Compose builds a Float with precision prec from the finite parts returned by
+// Decompose: (-1)^neg * |mantissa| * 2^exp, rounded with the default rounding mode
+// if mantissa has more than prec significant bits. A nil or zero mantissa gives a
+// zero with the sign given by neg. Infinities and NaN have no mantissa; build them
+// with ComposeSpecial.
+func Compose(neg bool, mantissa *big.Int, exp int, prec uint) *Float {
+	f := NewFloatWithPrec(prec)
+	if mantissa == nil || mantissa.Sign() == 0 {
+		return f.SetZero(composeSign(neg))
+	}
+	var z C.mpz_t
+	C.mpz_init(&z[0])
+	defer C.mpz_clear(&z[0])
+	bigIntToMpz(&z[0], new(big.Int).Abs(mantissa))
+	C.mpfr_set_z_2exp(&f.mpfr[0], &z[0], C.mpfr_exp_t(exp), C.mpfr_rnd_t(f.RoundingMode))
+	if neg {
+		C.mpfr_neg(&f.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	}
+	return f
+}
+
+// ComposeSpecial builds a Float with precision prec holding the non-finite value, or
+// the zero, of the given kind, with the sign given by neg: ±Inf for KindInf, NaN
+// with that sign bit for KindNaN and ±0 for KindZero. It panics for KindFinite,
+// which needs the mantissa and exponent that Compose takes.
+func ComposeSpecial(neg bool, kind Kind, prec uint) *Float {
+	f := NewFloatWithPrec(prec)
+	switch kind {
+	case KindNaN:
+		C.mpfr_set_nan(&f.mpfr[0])
+		if neg {
+			C.mpfr_setsign(&f.mpfr[0], &f.mpfr[0], 1, C.mpfr_rnd_t(f.RoundingMode))
+		}
+	case KindInf:
+		C.mpfr_set_inf(&f.mpfr[0], C.int(composeSign(neg)))
+	case KindZero:
+		f.SetZero(composeSign(neg))
+	default:
+		panic("ComposeSpecial: finite values need Compose")
+	}
+	return f
+}
+
+// composeSign converts a sign bit to the +1 or -1 taken by SetZero.
+func composeSign(neg bool) int {
+	if neg {
+		return -1
+	}
+	return 1
+}
+
+// mpzToBigInt returns the absolute value of z as a big.Int.
+func mpzToBigInt(z C.mpz_ptr) *big.Int {
+	n := (C.mpz_sizeinbase(z, 2) + 7) / 8
+	buf := make([]byte, n)
+	var count C.size_t
+	C.mpz_export(unsafe.Pointer(&buf[0]), &count, 1, 1, 1, 0, z)
+	return new(big.Int).SetBytes(buf[:count])
+}
+
+// bigIntToMpz sets z to x.
+func bigIntToMpz(z C.mpz_ptr, x *big.Int) {
+	buf := x.Bytes()
+	if len(buf) == 0 {
+		C.mpz_set_ui(z, 0)
+		return
+	}
+	C.mpz_import(z, C.size_t(len(buf)), 1, 1, 1, 0, unsafe.Pointer(&buf[0]))
+	if x.Sign() < 0 {
+		C.mpz_neg(z, z)
+	}
+}
+
+// FutureValue returns principal * (1 + rate)^periods, rounded to prec bits with rnd.
+//
+// The growth factor is evaluated as exp(periods * log1p(rate)) at a working precision
+// of prec plus enough guard bits to absorb the magnification by periods. Unlike forming
+// 1 + rate first, log1p keeps every bit of a tiny rate, so the result stays accurate
+// for many periods at small rates (e.g. daily compounding of a 1e-9 rate for a million periods).
+// The final result is within one ulp of the exact value but is not guaranteed to be correctly rounded.
+func FutureValue(principal, rate *Float, periods int, prec uint, rnd Rnd) *Float {
+	principal.doinit()
+	rate.doinit()
+
+	f := NewFloatWithPrec(prec)
+	f.SetRoundMode(rnd)
+	if periods == 0 {
+		C.mpfr_set(&f.mpfr[0], &principal.mpfr[0], C.mpfr_rnd_t(rnd))
+		return f
+	}
+
+	n := periods
+	if n < 0 {
+		n = -n
+	}
+	g := NewFloatWithPrec(prec + 64 + uint(bits.Len(uint(n))))
+	defer g.Clear()
+	C.mpfr_log1p(&g.mpfr[0], &rate.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_mul_si(&g.mpfr[0], &g.mpfr[0], C.long(periods), C.MPFR_RNDN)
+	C.mpfr_exp(&g.mpfr[0], &g.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_mul(&f.mpfr[0], &g.mpfr[0], &principal.mpfr[0], C.mpfr_rnd_t(rnd))
+	return f
+}
+
+// ToBigFloat converts f to a big.Float with precision prec, rounded in the direction of
+// the receiver's RoundingMode, and reports the accuracy of the result relative to f.
+// If prec is 0, the precision of f is used.
+//
+// Both types are binary, so the conversion is Exact whenever prec >= f.GetPrec().
+// ±0 and ±Inf convert to the corresponding big.Float values. big.Float cannot
+// represent NaN, so a NaN f yields a nil result.
+func (f *Float) ToBigFloat(prec uint) (*big.Float, big.Accuracy) {
+	f.doinit()
+	if prec == 0 {
+		prec = f.GetPrec()
+	}
+	z := new(big.Float).SetPrec(prec).SetMode(f.RoundingMode.ToBig())
+
+	neg, mantissa, exp, kind := f.Decompose()
+	switch kind {
+	case KindNaN:
+		return nil, big.Exact
+	case KindInf:
+		z.SetInf(neg)
+		return z, big.Exact
+	}
+
+	exact := new(big.Float).SetInt(mantissa)
+	exact.SetMantExp(exact, exp)
+	if neg {
+		exact.Neg(exact)
+	}
+	z.Set(exact)
+	return z, z.Acc()
+}
+
+// ToBig returns the math/big rounding mode equivalent to r. Every Rnd has an exact
+// counterpart; RndFromBig maps it back to r.
+func (r Rnd) ToBig() big.RoundingMode {
+	switch r {
+	case RoundToward0:
+		return big.ToZero
+	case RoundUp:
+		return big.ToPositiveInf
+	case RoundDown:
+		return big.ToNegativeInf
+	case RoundAway:
+		return big.AwayFromZero
+	default:
+		return big.ToNearestEven
+	}
+}
+
+// RndFromBig returns the Rnd equivalent to the math/big rounding mode m.
+// big.ToNearestAway has no MPFR counterpart, since MPFR rounds ties to even, so it
+// maps to RoundToNearest, which differs only on exact ties. Conversely, big.Float
+// has no faithful rounding mode, so no RoundingMode maps to MPFR_RNDF.
+func RndFromBig(m big.RoundingMode) Rnd {
+	switch m {
+	case big.ToZero:
+		return RoundToward0
+	case big.ToPositiveInf:
+		return RoundUp
+	case big.ToNegativeInf:
+		return RoundDown
+	case big.AwayFromZero:
+		return RoundAway
+	default:
+		return RoundToNearest
+	}
+}
+
+// RandState is a random number generator state for MPFR's random functions.
+//
+// Each RandState owns its own gmp_randstate_t, so its output depends only on its
+// seed and the sequence of calls made on it, never on other states or on global
+// MPFR settings. Two states given the same seed produce identical sequences for
+// the same target precisions (with the same GMP and MPFR versions).
+//
+// A RandState must not be used by multiple goroutines concurrently; independent
+// RandStates may be used in parallel.
+type RandState struct {
+	state C.gmp_randstate_t
+	init  bool
+}
+
+// randStateFinalizer releases the native generator state.
+func randStateFinalizer(s *RandState) {
+	if s.init {
+		C.gmp_randclear(&s.state[0])
+		s.init = false
+	}
+}
+
+// doinit initializes s.state with a Mersenne Twister generator if it isn’t already initialized.
+func (s *RandState) doinit() {
+	if s.init {
+		return
+	}
+	s.init = true
+	C.gmp_randinit_mt(&s.state[0])
+	runtime.SetFinalizer(s, randStateFinalizer)
+}
+
+// NewRandState returns a new RandState seeded with seed.
+func NewRandState(seed uint64) *RandState {
+	s := &RandState{}
+	s.Seed(seed)
+	return s
+}
+
+// Seed resets s to the start of the sequence determined by seed.
+func (s *RandState) Seed(seed uint64) {
+	s.seed(new(big.Int).SetUint64(seed))
+}
+
+// SeedBytes resets s to the start of the sequence determined by b, read as a
+// big-endian unsigned integer. Leading zero bytes therefore do not change the seed.
+func (s *RandState) SeedBytes(b []byte) {
+	s.seed(new(big.Int).SetBytes(b))
+}
+
+func (s *RandState) seed(x *big.Int) {
+	s.doinit()
+	var z C.mpz_t
+	C.mpz_init(&z[0])
+	defer C.mpz_clear(&z[0])
+	bigIntToMpz(&z[0], x)
+	C.gmp_randseed(&s.state[0], &z[0])
+}
+
+// UrandomInto sets f to a uniformly distributed random value in [0, 1] drawn
+// from s, rounded with f's RoundingMode and precision, and returns f.
+func UrandomInto(f *Float, s *RandState) *Float {
+	f.doinit()
+	f.markDirty()
+	s.doinit()
+	C.mpfr_urandom(&f.mpfr[0], &s.state[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// constKey identifies a cached constant by precision and rounding mode.
+type constKey struct {
+	prec uint
+	rnd  Rnd
+}
+
+var (
+	constMu    sync.Mutex
+	constCache = map[constKey]*Float{}
+)
+
+// Pi returns π rounded to prec bits with rnd. The result's RoundingMode is rnd.
+//
+// Values are cached per (prec, rnd) pair and each call returns a fresh copy,
+// so callers may modify the result freely. Calling Pi in a loop at a fixed
+// precision costs one copy per call instead of a full evaluation, a saving that
+// grows with the precision; BenchmarkPi compares cached and uncached calls. Use
+// ClearConstCache to release the memory.
+func Pi(prec uint, rnd Rnd) *Float {
+	key := constKey{prec, rnd}
+	constMu.Lock()
+	defer constMu.Unlock()
+	if c, ok := constCache[key]; ok {
+		return c.clone()
+	}
+	c := NewFloatWithPrec(prec)
+	c.SetRoundMode(rnd)
+	C.mpfr_const_pi(&c.mpfr[0], C.mpfr_rnd_t(rnd))
+	constCache[key] = c
+	return c.clone()
+}
+
+// ClearConstCache discards all constants cached by Pi and frees MPFR's
+// internal constant caches for the calling thread.
+func ClearConstCache() {
+	constMu.Lock()
+	defer constMu.Unlock()
+	for key, c := range constCache {
+		c.Clear()
+		delete(constCache, key)
+	}
+	C.mpfr_free_cache()
+}
+
+// PrecForDigits returns the precision in bits needed to carry decimalDigits
+// significant decimal digits, ⌈decimalDigits·log₂10⌉ + 1: a Float of that
+// precision distinguishes every decimalDigits-digit value, so converting such a
+// string to it and back is lossless. Counts below 1 are treated as 1.
+func PrecForDigits(decimalDigits int) uint {
+	d := max(decimalDigits, 1)
+	return uint(math.Ceil(float64(d)*math.Log2(10))) + 1
+}
+
+// constDigitGuardBits is the precision PiDigits and EDigits add beyond
+// PrecForDigits, so that rounding the result to the requested digits is correct
+// unless the constant has a run of about ten 0s or 9s right after them.
+const constDigitGuardBits = 32
+
+// PiDigits returns π with enough precision for decimalDigits significant decimal
+// digits, PrecForDigits(decimalDigits) plus constDigitGuardBits bits, rounded with
+// rnd. Formatting it with StringN(decimalDigits) gives π to that many digits,
+// rounded in the same direction. The value comes from Pi and shares its cache.
+func PiDigits(decimalDigits int, rnd Rnd) *Float {
+	return Pi(PrecForDigits(decimalDigits)+constDigitGuardBits, rnd)
+}
+
+// EDigits returns e, the base of the natural logarithm, with the same precision
+// and rounding as PiDigits gives π.
+func EDigits(decimalDigits int, rnd Rnd) *Float {
+	f := NewFloatWithPrec(PrecForDigits(decimalDigits) + constDigitGuardBits)
+	f.SetRoundMode(rnd)
+	C.mpfr_set_ui(&f.mpfr[0], 1, C.MPFR_RNDN)
+	C.mpfr_exp(&f.mpfr[0], &f.mpfr[0], C.mpfr_rnd_t(rnd))
+	return f
+}
+
+// binomialExactBits bounds the estimated size of C(n, k) that Binomial computes
+// as an exact integer; larger results go through ln Γ unless prec asks for more.
+const binomialExactBits = 1 << 20
+
+// Binomial returns the binomial coefficient C(n, k) = n! / (k!·(n-k)!) correctly
+// rounded to prec bits with rnd; it is 0 when k > n.
+//
+// Results up to about a million bits, or up to prec bits if that is more, are
+// formed as exact integers and rounded once, so whenever C(n, k) fits in prec bits
+// the result is exact: Binomial(52, 5, 53, rnd) is 2598960. Larger coefficients are
+// evaluated as exp(ln Γ(n+1) - ln Γ(k+1) - ln Γ(n-k+1)), raising the working
+// precision until the rounding is certain. A result beyond the exponent range
+// overflows to +Inf.
+func Binomial(n, k uint, prec uint, rnd Rnd) *Float {
+	f := NewFloatWithPrec(prec)
+	f.SetRoundMode(rnd)
+	if k > n {
+		C.mpfr_set_zero(&f.mpfr[0], 1)
+		return f
+	}
+	k = min(k, n-k)
+
+	lg := func(m uint) float64 {
+		v, _ := math.Lgamma(float64(m) + 1)
+		return v
+	}
+	estBits := (lg(n) - lg(k) - lg(n-k)) / math.Ln2
+	if estBits <= float64(max(binomialExactBits, prec+128)) {
+		var z C.mpz_t
+		C.mpz_init(&z[0])
+		defer C.mpz_clear(&z[0])
+		C.mpz_bin_uiui(&z[0], C.ulong(n), C.ulong(k))
+		C.mpfr_set_z(&f.mpfr[0], &z[0], C.mpfr_rnd_t(rnd))
+		return f
+	}
+
+	// Here C(n, k) has far more than prec significant bits (it has at most
+	// log₂ n trailing zeros), so it is never a rounding boundary and the loop ends.
+	w := C.mpfr_prec_t(prec + 64)
+	t, a := &Float{}, &Float{}
+	t.doinit()
+	a.doinit()
+	lnFac := func(dst *Float, m uint) {
+		C.mpfr_set_ui(&dst.mpfr[0], C.ulong(m), C.MPFR_RNDN)
+		C.mpfr_add_ui(&dst.mpfr[0], &dst.mpfr[0], 1, C.MPFR_RNDN)
+		C.mpfr_lngamma(&dst.mpfr[0], &dst.mpfr[0], C.MPFR_RNDN)
+	}
+	nearest := C.mpfr_prec_t(0)
+	if rnd == RoundToNearest {
+		nearest = 1
+	}
+	for {
+		C.mpfr_set_prec(&t.mpfr[0], w)
+		C.mpfr_set_prec(&a.mpfr[0], w)
+		lnFac(t, n)
+		eMax := C.mpfr_get_exp(&t.mpfr[0])
+		lnFac(a, k)
+		C.mpfr_sub(&t.mpfr[0], &t.mpfr[0], &a.mpfr[0], C.MPFR_RNDN)
+		lnFac(a, n-k)
+		C.mpfr_sub(&t.mpfr[0], &t.mpfr[0], &a.mpfr[0], C.MPFR_RNDN)
+		// The five roundings above leave an absolute error below 2^(eMax-w+2) in
+		// the log, which becomes the relative error of its exponential.
+		C.mpfr_exp(&t.mpfr[0], &t.mpfr[0], C.MPFR_RNDN)
+		if C.mpfr_inf_p(&t.mpfr[0]) != 0 {
+			C.mpfr_set_inf(&f.mpfr[0], 1)
+			return f
+		}
+		err := C.mpfr_exp_t(w) - eMax - 4
+		if C.mpfr_can_round(&t.mpfr[0], err, C.MPFR_RNDN, C.MPFR_RNDZ, C.mpfr_prec_t(prec)+nearest) != 0 {
+			C.mpfr_set(&f.mpfr[0], &t.mpfr[0], C.mpfr_rnd_t(rnd))
+			return f
+		}
+		w += w / 2
+	}
+}
+
+// ExpSlice sets dst[i] = e^src[i] for every i, rounding each result with rnd.
+//
+// Elements of dst that are already allocated are reused and keep their precision,
+// so a destination slice can be recycled across calls without allocating; nil
+// elements are filled with new Floats whose precision follows SetResultPrecMode.
+// dst and src may be the same slice, to apply the function in place. ExpSlice
+// panics if len(dst) != len(src).
+func ExpSlice(dst, src []*Float, rnd Rnd) {
+	mapSlice("ExpSlice", dst, src, nil, rnd, func(r, x, _ *Float, rnd C.mpfr_rnd_t) {
+		C.mpfr_exp(&r.mpfr[0], &x.mpfr[0], rnd)
+	})
+}
+
+// LogSlice sets dst[i] = ln src[i] for every i, rounding each result with rnd.
+// Negative elements give NaN, as with Log. Destination reuse and the length
+// requirement are as for ExpSlice.
+func LogSlice(dst, src []*Float, rnd Rnd) {
+	mapSlice("LogSlice", dst, src, nil, rnd, func(r, x, _ *Float, rnd C.mpfr_rnd_t) {
+		C.mpfr_log(&r.mpfr[0], &x.mpfr[0], rnd)
+	})
+}
+
+// PowSlice sets dst[i] = base[i]^y for every i, rounding each result with rnd.
+// Destination reuse and the length requirement are as for ExpSlice; PowSlice
+// panics if len(dst) != len(base).
+func PowSlice(dst, base []*Float, y *Float, rnd Rnd) {
+	y.doinit()
+	mapSlice("PowSlice", dst, base, y, rnd, func(r, x, y *Float, rnd C.mpfr_rnd_t) {
+		C.mpfr_pow(&r.mpfr[0], &x.mpfr[0], &y.mpfr[0], rnd)
+	})
+}
+
+// mapSlice applies fn(dst[i], src[i], y) for every i, allocating nil elements of
+// dst with newResult. y is an optional shared second operand.
+func mapSlice(name string, dst, src []*Float, y *Float, rnd Rnd, fn func(r, x, y *Float, rnd C.mpfr_rnd_t)) {
+	if len(dst) != len(src) {
+		panic(name + " requires len(dst) == len(src)")
+	}
+	for i, x := range src {
+		x.doinit()
+		if dst[i] == nil {
+			if y != nil {
+				dst[i] = newResult(rnd, x, y)
+			} else {
+				dst[i] = newResult(rnd, x)
+			}
+		} else {
+			dst[i].doinit()
+			dst[i].markDirty()
+		}
+		fn(dst[i], x, y, C.mpfr_rnd_t(rnd))
+	}
+}
+
+// GeometricMean returns (x₁·x₂·…·xₙ)^(1/n) rounded to prec bits with rnd.
+//
+// It is computed as exp((Σ ln xᵢ)/n) with 64 + log₂(n) guard bits, so the
+// intermediate product is never formed and cannot overflow or underflow however
+// widely the values are spread. Every xᵢ must be positive and finite; an empty
+// slice or any zero, negative, infinite or NaN element returns ErrDomain.
+func GeometricMean(xs []*Float, prec uint, rnd Rnd) (*Float, error) {
+	if len(xs) == 0 {
+		return nil, ErrDomain
+	}
+	sum := NewFloatWithPrec(prec + 64 + uint(bits.Len(uint(len(xs)))))
+	defer sum.Clear()
+	term := NewFloatWithPrec(sum.GetPrec())
+	defer term.Clear()
+	for _, x := range xs {
+		x.doinit()
+		if C.mpfr_number_p(&x.mpfr[0]) == 0 || C.mpfr_sgn(&x.mpfr[0]) <= 0 {
+			return nil, ErrDomain
+		}
+		C.mpfr_log(&term.mpfr[0], &x.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_add(&sum.mpfr[0], &sum.mpfr[0], &term.mpfr[0], C.MPFR_RNDN)
+	}
+	C.mpfr_div_ui(&sum.mpfr[0], &sum.mpfr[0], C.ulong(len(xs)), C.MPFR_RNDN)
+
+	f := NewFloatWithPrec(prec)
+	f.SetRoundMode(rnd)
+	C.mpfr_exp(&f.mpfr[0], &sum.mpfr[0], C.mpfr_rnd_t(rnd))
+	return f, nil
+}
+
+// Softmax returns the probability vector e^xᵢ / Σⱼ e^xⱼ, each element rounded to
+// prec bits with rnd.
+//
+// The largest element is subtracted before exponentiating, so every exponential lies
+// in (0, 1] and widely separated inputs such as [1000, 0] neither overflow nor lose
+// the small entries. The exponentials and their sum are carried with 64 + log₂(n)
+// guard bits and each quotient is rounded once. An empty slice returns an empty
+// slice. -Inf elements get probability 0; if any element is NaN or +Inf, or every
+// element is -Inf, all results are NaN.
+func Softmax(xs []*Float, prec uint, rnd Rnd) []*Float {
+	out := make([]*Float, len(xs))
+	if len(xs) == 0 {
+		return out
+	}
+	wp := prec + 64 + uint(bits.Len(uint(len(xs))))
+
+	top := xs[0]
+	top.doinit()
+	for _, x := range xs {
+		x.doinit()
+		if C.mpfr_nan_p(&x.mpfr[0]) != 0 {
+			top = x
+			break
+		}
+		if C.mpfr_greater_p(&x.mpfr[0], &top.mpfr[0]) != 0 {
+			top = x
+		}
+	}
+
+	sum := NewFloatWithPrec(wp)
+	defer sum.Clear()
+	exps := make([]*Float, len(xs))
+	for i, x := range xs {
+		e := NewFloatWithPrec(wp)
+		defer e.Clear()
+		C.mpfr_sub(&e.mpfr[0], &x.mpfr[0], &top.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_exp(&e.mpfr[0], &e.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_add(&sum.mpfr[0], &sum.mpfr[0], &e.mpfr[0], C.MPFR_RNDN)
+		exps[i] = e
+	}
+	for i, e := range exps {
+		out[i] = NewFloatWithPrec(prec)
+		out[i].SetRoundMode(rnd)
+		C.mpfr_div(&out[i].mpfr[0], &e.mpfr[0], &sum.mpfr[0], C.mpfr_rnd_t(rnd))
+	}
+	return out
+}
+
+// LogSumExp returns log(exp(x₁) + … + exp(xₙ)), rounded with rnd, without the
+// overflow of forming the exponentials directly: with m the largest xᵢ it
+// evaluates m + log Σ exp(xᵢ - m), where every term is at most 1. The sum is
+// carried at 64 + log₂(n) bits beyond the result's precision, so the result is
+// within an ulp.
+//
+// An empty xs gives -Inf, the logarithm of an empty sum. A NaN element gives NaN,
+// a +Inf element +Inf, and if every element is -Inf the result is -Inf.
+func LogSumExp(xs []*Float, rnd Rnd) *Float {
+	f := newResult(rnd, xs...)
+	sum, term := &Float{}, &Float{}
+	sum.doinit()
+	term.doinit()
+	defer sum.Clear()
+	defer term.Clear()
+	return f.logSumExp(xs, sum, term)
+}
+
+// LogSumExpScratch is like LogSumExp but writes into storage owned by s, as
+// HypotScratch does, and keeps its temporaries in s too.
+func LogSumExpScratch(xs []*Float, s *Scratch) *Float {
+	wp := s.prec + 64 + uint(bits.Len(uint(len(xs))))
+	return s.out().logSumExp(xs, s.temp(0, wp), s.temp(1, wp))
+}
+
+// logSumExp sets f to LogSumExp(xs) using sum and term as temporaries, which it
+// resizes as needed.
+func (f *Float) logSumExp(xs []*Float, sum, term *Float) *Float {
+	f.doinit()
+	f.markDirty()
+	if len(xs) == 0 {
+		C.mpfr_set_inf(&f.mpfr[0], -1)
+		return f
+	}
+	top := xs[0]
+	for _, x := range xs {
+		x.doinit()
+		if C.mpfr_nan_p(&x.mpfr[0]) != 0 {
+			C.mpfr_set_nan(&f.mpfr[0])
+			return f
+		}
+		if C.mpfr_greater_p(&x.mpfr[0], &top.mpfr[0]) != 0 {
+			top = x
+		}
+	}
+	if C.mpfr_inf_p(&top.mpfr[0]) != 0 {
+		C.mpfr_set(&f.mpfr[0], &top.mpfr[0], C.MPFR_RNDN)
+		return f
+	}
+
+	wp := C.mpfr_prec_t(uint(C.mpfr_get_prec(&f.mpfr[0])) + 64 + uint(bits.Len(uint(len(xs)))))
+	for _, t := range []*Float{sum, term} {
+		if C.mpfr_get_prec(&t.mpfr[0]) != wp {
+			C.mpfr_set_prec(&t.mpfr[0], wp)
+		}
+	}
+	C.mpfr_set_zero(&sum.mpfr[0], 1)
+	for _, x := range xs {
+		C.mpfr_sub(&term.mpfr[0], &x.mpfr[0], &top.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_exp(&term.mpfr[0], &term.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_add(&sum.mpfr[0], &sum.mpfr[0], &term.mpfr[0], C.MPFR_RNDN)
+	}
+	C.mpfr_log(&sum.mpfr[0], &sum.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_add(&sum.mpfr[0], &sum.mpfr[0], &top.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_set(&f.mpfr[0], &sum.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// degreeGuardBits is the extra working precision used when converting between
+// degrees and radians.
+const degreeGuardBits = 64
+
+// fromDegrees evaluates fn at x degrees, rounding into f with f's RoundingMode.
+// x is first reduced modulo 360 exactly, so large angles lose no accuracy, and then
+// scaled by π/180 with degreeGuardBits extra bits.
+func (f *Float) fromDegrees(x *Float, fn func(rop, op *Float)) *Float {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	prec := uint(C.mpfr_get_prec(&f.mpfr[0])) + degreeGuardBits
+	if xp := uint(C.mpfr_get_prec(&x.mpfr[0])); xp > prec {
+		prec = xp
+	}
+	t := NewFloatWithPrec(prec)
+	defer t.Clear()
+	C.mpfr_fmod(&t.mpfr[0], &x.mpfr[0], &FromInt(360).mpfr[0], C.MPFR_RNDN)
+	C.mpfr_mul(&t.mpfr[0], &t.mpfr[0], &Pi(prec, RoundToNearest).mpfr[0], C.MPFR_RNDN)
+	C.mpfr_div_ui(&t.mpfr[0], &t.mpfr[0], 180, C.MPFR_RNDN)
+	fn(f, t)
+	return f
+}
+
+// toDegrees evaluates fn at x with degreeGuardBits extra bits, converts the
+// result from radians to degrees and rounds it into f with f's RoundingMode.
+func (f *Float) toDegrees(x *Float, fn func(rop, op *Float)) *Float {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	prec := uint(C.mpfr_get_prec(&f.mpfr[0])) + degreeGuardBits
+	t := NewFloatWithPrec(prec)
+	defer t.Clear()
+	fn(t, x)
+	C.mpfr_mul_ui(&t.mpfr[0], &t.mpfr[0], 180, C.MPFR_RNDN)
+	C.mpfr_div(&t.mpfr[0], &t.mpfr[0], &Pi(prec, RoundToNearest).mpfr[0], C.MPFR_RNDN)
+	C.mpfr_set(&f.mpfr[0], &t.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f
+}
+
+// SinDeg sets f = sin(x), where x is in degrees, and returns f.
+//
+// The degree-to-radian conversion is carried out with extra precision, so the
+// result is within one ulp of the exact value; SinDeg(30) is 0.5 to the last bit.
+// Angles where the exact result is 0, such as SinDeg(180), yield a value that is
+// tiny relative to f's precision rather than exactly 0.
+func (f *Float) SinDeg(x *Float) *Float {
+	return f.fromDegrees(x, func(rop, op *Float) {
+		C.mpfr_sin(&rop.mpfr[0], &op.mpfr[0], C.mpfr_rnd_t(rop.RoundingMode))
+	})
+}
+
+// CosDeg sets f = cos(x), where x is in degrees, and returns f.
+// Accuracy is as described for SinDeg.
+func (f *Float) CosDeg(x *Float) *Float {
+	return f.fromDegrees(x, func(rop, op *Float) {
+		C.mpfr_cos(&rop.mpfr[0], &op.mpfr[0], C.mpfr_rnd_t(rop.RoundingMode))
+	})
+}
+
+// TanDeg sets f = tan(x), where x is in degrees, and returns f.
+// Accuracy is as described for SinDeg; at odd multiples of 90 the result is a
+// very large finite value rather than Inf.
+func (f *Float) TanDeg(x *Float) *Float {
+	return f.fromDegrees(x, func(rop, op *Float) {
+		C.mpfr_tan(&rop.mpfr[0], &op.mpfr[0], C.mpfr_rnd_t(rop.RoundingMode))
+	})
+}
+
+// AsinDeg sets f = asin(x) in degrees, in [-90, 90], and returns f.
+// The result is within one ulp of the exact value.
+func (f *Float) AsinDeg(x *Float) *Float {
+	return f.toDegrees(x, func(rop, op *Float) {
+		C.mpfr_asin(&rop.mpfr[0], &op.mpfr[0], C.MPFR_RNDN)
+	})
+}
+
+// AcosDeg sets f = acos(x) in degrees, in [0, 180], and returns f.
+// The result is within one ulp of the exact value.
+func (f *Float) AcosDeg(x *Float) *Float {
+	return f.toDegrees(x, func(rop, op *Float) {
+		C.mpfr_acos(&rop.mpfr[0], &op.mpfr[0], C.MPFR_RNDN)
+	})
+}
+
+// AtanDeg sets f = atan(x) in degrees, in [-90, 90], and returns f.
+// The result is within one ulp of the exact value.
+func (f *Float) AtanDeg(x *Float) *Float {
+	return f.toDegrees(x, func(rop, op *Float) {
+		C.mpfr_atan(&rop.mpfr[0], &op.mpfr[0], C.MPFR_RNDN)
+	})
+}
+
+// SinPi sets f = sin(π·x) using f's RoundingMode and returns f.
+//
+// Unlike the trigonometric functions applied to a rounded π·x, the result is exact
+// where the true value is: SinPi(1) is exactly 0 (with the sign of x) and
+// SinPi(0.5) exactly 1, so rational multiples of π need no tolerance. ±Inf and NaN
+// give NaN. MPFR 4.2's mpfr_sinpi is used when available; with older libraries the
+// argument is reduced exactly and the exact cases are handled directly, so the
+// result is within an ulp.
+func (f *Float) SinPi(x *Float) *Float {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	if haveTrigPi {
+		C.gompfr_sinpi(&f.mpfr[0], &x.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+		return f
+	}
+	return f.trigPi(x, false)
+}
+
+// CosPi sets f = cos(π·x) using f's RoundingMode and returns f. Exact cases and
+// accuracy are as for SinPi: CosPi(0.5) is exactly +0 and CosPi(1) exactly -1.
+func (f *Float) CosPi(x *Float) *Float {
+	x.doinit()
+	f.doinit()
+	f.markDirty()
+	if haveTrigPi {
+		C.gompfr_cospi(&f.mpfr[0], &x.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+		return f
+	}
+	return f.trigPi(x, true)
+}
+
+// SinPi returns sin(π·x), using rnd.
+func SinPi(x *Float, rnd Rnd) *Float {
+	f := newResult(rnd, x)
+	return f.SinPi(x)
+}
+
+// CosPi returns cos(π·x), using rnd.
+func CosPi(x *Float, rnd Rnd) *Float {
+	f := newResult(rnd, x)
+	return f.CosPi(x)
+}
+
+// haveTrigPi reports whether the linked MPFR provides mpfr_sinpi and mpfr_cospi.
+const haveTrigPi = C.HAVE_MPFR_TRIGPI != 0
+
+// trigPi computes sin(π·x), or cos(π·x) if cosine is set, for MPFR libraries
+// without mpfr_sinpi and mpfr_cospi. x is reduced modulo 2 and folded by the
+// symmetries of sine and cosine at x's precision plus two bits, where every step
+// is exact. The cosine of a reduced t in
+// [0, 1/4] is taken directly rather than as sin(π(1/2 - t)), since 1/2 - t would
+// need about -log2(t) extra bits to be exact. The only roundings are then the
+// multiplication by π and the final sine or cosine.
+func (f *Float) trigPi(x *Float, cosine bool) *Float {
+	if C.mpfr_number_p(&x.mpfr[0]) == 0 {
+		C.mpfr_set_nan(&f.mpfr[0])
+		return f
+	}
+	t := &Float{}
+	t.doinit()
+	C.mpfr_set_prec(&t.mpfr[0], C.mpfr_get_prec(&x.mpfr[0])+2)
+	C.mpfr_set_ui(&t.mpfr[0], 2, C.MPFR_RNDN)
+	C.mpfr_fmod(&t.mpfr[0], &x.mpfr[0], &t.mpfr[0], C.MPFR_RNDN)
+
+	tp := &t.mpfr[0]
+	cmpHalf := func() int { return int(C.mpfr_cmp_ui_2exp(tp, 1, -1)) }
+	negate := false
+	direct := false
+	if cosine {
+		// cos is even with period 2, and cos(π(1-t)) = -cos(πt).
+		C.mpfr_abs(tp, tp, C.MPFR_RNDN)
+		if C.mpfr_cmp_ui(tp, 1) > 0 {
+			C.mpfr_ui_sub(tp, 2, tp, C.MPFR_RNDN)
+		}
+		if cmpHalf() > 0 {
+			C.mpfr_ui_sub(tp, 1, tp, C.MPFR_RNDN)
+			negate = true
+		}
+		// Now t ∈ [0, 1/2]. Above 1/4, cos(πt) = sin(π(1/2 - t)) keeps the
+		// relative accuracy near the zero at 1/2.
+		if C.mpfr_cmp_ui_2exp(tp, 1, -2) > 0 {
+			C.mpfr_mul_2si(tp, tp, 1, C.MPFR_RNDN)
+			C.mpfr_ui_sub(tp, 1, tp, C.MPFR_RNDN)
+			C.mpfr_mul_2si(tp, tp, -1, C.MPFR_RNDN)
+			if negate {
+				C.mpfr_neg(tp, tp, C.MPFR_RNDN)
+			}
+		} else {
+			direct = true
+		}
+	} else {
+		// sin has period 2, and sin(π(±1 - t)) = sin(πt).
+		if C.mpfr_cmp_ui(tp, 1) > 0 {
+			C.mpfr_sub_ui(tp, tp, 2, C.MPFR_RNDN)
+		} else if C.mpfr_cmp_si(tp, -1) < 0 {
+			C.mpfr_add_ui(tp, tp, 2, C.MPFR_RNDN)
+		}
+		if cmpHalf() > 0 {
+			C.mpfr_ui_sub(tp, 1, tp, C.MPFR_RNDN)
+		} else if C.mpfr_cmp_si_2exp(tp, -1, -1) < 0 {
+			C.mpfr_si_sub(tp, -1, tp, C.MPFR_RNDN)
+		}
+	}
+
+	// Unless direct, t ∈ [-1/2, 1/2] and the result is sin(πt).
+	switch {
+	case direct:
+		// ±cos(πt): round -cos the opposite way and negate.
+		rnd := f.RoundingMode
+		if negate {
+			switch rnd {
+			case RoundUp:
+				rnd = RoundDown
+			case RoundDown:
+				rnd = RoundUp
+			}
+		}
+		u := &Float{}
+		u.doinit()
+		C.mpfr_set_prec(&u.mpfr[0], C.mpfr_get_prec(&f.mpfr[0])+64)
+		C.mpfr_const_pi(&u.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_mul(&u.mpfr[0], &u.mpfr[0], tp, C.MPFR_RNDN)
+		C.mpfr_cos(&f.mpfr[0], &u.mpfr[0], C.mpfr_rnd_t(rnd))
+		if negate {
+			C.mpfr_neg(&f.mpfr[0], &f.mpfr[0], C.MPFR_RNDN)
+		}
+	case C.mpfr_zero_p(tp) != 0:
+		sign := C.int(1)
+		if !cosine && C.mpfr_signbit(&x.mpfr[0]) != 0 {
+			sign = -1
+		}
+		C.mpfr_set_zero(&f.mpfr[0], sign)
+	case C.mpfr_cmpabs_ui(tp, 1) < 0 && C.mpfr_get_exp(tp) == 0 && C.mpfr_min_prec(tp) == 1:
+		// |t| = 1/2
+		C.mpfr_set_si(&f.mpfr[0], C.long(C.mpfr_sgn(tp)), C.MPFR_RNDN)
+	default:
+		u := &Float{}
+		u.doinit()
+		C.mpfr_set_prec(&u.mpfr[0], C.mpfr_get_prec(&f.mpfr[0])+64)
+		C.mpfr_const_pi(&u.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_mul(&u.mpfr[0], &u.mpfr[0], tp, C.MPFR_RNDN)
+		C.mpfr_sin(&f.mpfr[0], &u.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	}
+	return f
+}
+
+// HasTLS reports whether the linked MPFR library was built with thread-local
+// storage.
+//
+// MPFR keeps some state outside of any Float: the exception flags (GetFlags), the
+// exponent range (SetEmin, SetEmax), the default precision and rounding mode
+// (SetDefaultPrec, SetDefaultRound) and the caches behind constants such as π and
+// log 2. With TLS this state is per OS thread; without it, it is shared by the whole
+// process and every arithmetic operation may update the flags, so calls into the
+// package from multiple goroutines must be serialized by the caller.
+//
+// Even with TLS, Go may move a goroutine between OS threads, so code that relies on
+// the flags or on a changed default should use runtime.LockOSThread (see State).
+// Regardless of TLS, an individual Float is never safe to share between goroutines
+// without synchronization.
+func HasTLS() bool {
+	return C.mpfr_buildopt_tls_p() != 0
+}
+
+// SolveQuadratic returns the real roots of a·x² + b·x + c = 0, rounded to prec bits
+// with rnd, in ascending order (x1 <= x2). If the discriminant b² - 4ac is negative the
+// roots are complex and SolveQuadratic returns nil, nil, false.
+//
+// The textbook formula (-b ± √(b² - 4ac)) / 2a subtracts nearly equal numbers for
+// one of the roots whenever b² ≫ |4ac|. Instead the discriminant is formed with a
+// single rounding (Fmms) at prec + 64 bits, then
+//
+//	q  = -(b + sign(b)·√(b² - 4ac)) / 2
+//	x1 = q / a,  x2 = c / q
+//
+// which never cancels. If a is zero the equation is linear and both roots are -c/b;
+// if a and b are both zero there is no unique root and the results are ±Inf or NaN.
+func SolveQuadratic(a, b, c *Float, prec uint, rnd Rnd) (x1, x2 *Float, real bool) {
+	a.doinit()
+	b.doinit()
+	c.doinit()
+	x1 = NewFloatWithPrec(prec)
+	x2 = NewFloatWithPrec(prec)
+	x1.SetRoundMode(rnd)
+	x2.SetRoundMode(rnd)
+
+	if C.mpfr_zero_p(&a.mpfr[0]) != 0 {
+		C.mpfr_div(&x1.mpfr[0], &c.mpfr[0], &b.mpfr[0], C.mpfr_rnd_t(rnd))
+		C.mpfr_neg(&x1.mpfr[0], &x1.mpfr[0], C.mpfr_rnd_t(rnd))
+		C.mpfr_set(&x2.mpfr[0], &x1.mpfr[0], C.mpfr_rnd_t(rnd))
+		return x1, x2, true
+	}
+
+	w := prec + 64
+	a4 := NewFloatWithPrec(uint(C.mpfr_get_prec(&a.mpfr[0])))
+	defer a4.Clear()
+	C.mpfr_mul_2si(&a4.mpfr[0], &a.mpfr[0], 2, C.MPFR_RNDN)
+	q := NewFloatWithPrec(w)
+	defer q.Clear()
+	C.mpfr_fmms(&q.mpfr[0], &b.mpfr[0], &b.mpfr[0], &a4.mpfr[0], &c.mpfr[0], C.MPFR_RNDN)
+	if C.mpfr_sgn(&q.mpfr[0]) < 0 {
+		return nil, nil, false
+	}
+
+	C.mpfr_sqrt(&q.mpfr[0], &q.mpfr[0], C.MPFR_RNDN)
+	if C.mpfr_signbit(&b.mpfr[0]) != 0 {
+		C.mpfr_sub(&q.mpfr[0], &q.mpfr[0], &b.mpfr[0], C.MPFR_RNDN)
+	} else {
+		C.mpfr_add(&q.mpfr[0], &q.mpfr[0], &b.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_neg(&q.mpfr[0], &q.mpfr[0], C.MPFR_RNDN)
+	}
+	C.mpfr_mul_2si(&q.mpfr[0], &q.mpfr[0], -1, C.MPFR_RNDN)
+
+	C.mpfr_div(&x1.mpfr[0], &q.mpfr[0], &a.mpfr[0], C.mpfr_rnd_t(rnd))
+	if C.mpfr_zero_p(&q.mpfr[0]) != 0 {
+		// b and c are both zero: the double root is 0.
+		C.mpfr_set(&x2.mpfr[0], &x1.mpfr[0], C.mpfr_rnd_t(rnd))
+	} else {
+		C.mpfr_div(&x2.mpfr[0], &c.mpfr[0], &q.mpfr[0], C.mpfr_rnd_t(rnd))
+	}
+	if C.mpfr_greater_p(&x1.mpfr[0], &x2.mpfr[0]) != 0 {
+		x1, x2 = x2, x1
+	}
+	return x1, x2, true
+}
+
+// accGuardBits is the extra precision accumulators keep beyond the requested
+// precision, so that rounding errors from up to 2^32 additions stay far below
+// one ulp of the result.
+const accGuardBits = 64
+
+// MeanAccumulator computes the arithmetic mean, variance and standard deviation
+// of a stream of Floats.
+//
+// The running sum is kept with accGuardBits bits beyond the requested precision
+// and the mean is rounded once, so unlike a float64 loop the result does not drift
+// as values are added. The variance uses Welford's one-pass update at the same
+// working precision, which never forms the sum of squares and so stays accurate
+// when the spread is tiny compared with the values themselves. The zero value is
+// not usable; create one with NewMeanAccumulator. A MeanAccumulator must not be
+// used concurrently.
+type MeanAccumulator struct {
+	prec uint
+	sum  *Float
+	n    uint64
+
+	// Welford state: running mean and sum of squared deviations from it.
+	mean, m2 *Float
+
+	// scratch space reused by Add
+	delta, tmp, count *Float
+}
+
+// NewMeanAccumulator returns an empty accumulator whose results are rounded to prec bits.
+func NewMeanAccumulator(prec uint) *MeanAccumulator {
+	w := prec + accGuardBits
+	return &MeanAccumulator{
+		prec: prec,
+		sum:  NewFloatWithPrec(w),
+		mean: NewFloatWithPrec(w),
+		m2:   NewFloatWithPrec(w),
+
+		delta: NewFloatWithPrec(w),
+		tmp:   NewFloatWithPrec(w),
+		count: NewFloatWithPrec(64),
+	}
+}
+
+// Add adds x to the accumulated values. x is not retained.
+func (m *MeanAccumulator) Add(x *Float) {
+	x.doinit()
+	C.mpfr_add(&m.sum.mpfr[0], &m.sum.mpfr[0], &x.mpfr[0], C.MPFR_RNDN)
+	m.n++
+
+	// delta = x - mean; mean += delta/n; m2 += delta * (x - mean)
+	m.count.SetUint64(m.n)
+	C.mpfr_sub(&m.delta.mpfr[0], &x.mpfr[0], &m.mean.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_div(&m.tmp.mpfr[0], &m.delta.mpfr[0], &m.count.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_add(&m.mean.mpfr[0], &m.mean.mpfr[0], &m.tmp.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_sub(&m.tmp.mpfr[0], &x.mpfr[0], &m.mean.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_fma(&m.m2.mpfr[0], &m.delta.mpfr[0], &m.tmp.mpfr[0], &m.m2.mpfr[0], C.MPFR_RNDN)
+}
+
+// Count returns the number of values added so far.
+func (m *MeanAccumulator) Count() uint64 {
+	return m.n
+}
+
+// Mean returns the mean of the values added so far as a new Float with the
+// accumulator's precision, rounded to nearest. It is NaN if no values were added.
+func (m *MeanAccumulator) Mean() *Float {
+	f := NewFloatWithPrec(m.prec)
+	if m.n == 0 {
+		C.mpfr_set_nan(&f.mpfr[0])
+		return f
+	}
+	count := NewFloatWithPrec(64).SetUint64(m.n)
+	C.mpfr_div(&f.mpfr[0], &m.sum.mpfr[0], &count.mpfr[0], C.MPFR_RNDN)
+	return f
+}
+
+// Variance returns the sample variance of the values added so far, Σ(xᵢ - mean)² / (n - 1),
+// as a new Float with the accumulator's precision, rounded to nearest. It is NaN if fewer
+// than two values were added. For the population variance (dividing by n), multiply by
+// (n - 1)/n.
+func (m *MeanAccumulator) Variance() *Float {
+	f := NewFloatWithPrec(m.prec)
+	if m.n < 2 {
+		C.mpfr_set_nan(&f.mpfr[0])
+		return f
+	}
+	C.mpfr_div(&f.mpfr[0], &m.m2.mpfr[0], &NewFloatWithPrec(64).SetUint64(m.n - 1).mpfr[0], C.MPFR_RNDN)
+	return f
+}
+
+// StdDev returns the sample standard deviation, the square root of Variance, as a new
+// Float with the accumulator's precision, rounded to nearest. It is NaN if fewer than
+// two values were added.
+func (m *MeanAccumulator) StdDev() *Float {
+	f := NewFloatWithPrec(m.prec)
+	if m.n < 2 {
+		C.mpfr_set_nan(&f.mpfr[0])
+		return f
+	}
+	v := NewFloatWithPrec(m.prec + accGuardBits)
+	defer v.Clear()
+	C.mpfr_div(&v.mpfr[0], &m.m2.mpfr[0], &NewFloatWithPrec(64).SetUint64(m.n - 1).mpfr[0], C.MPFR_RNDN)
+	C.mpfr_sqrt(&f.mpfr[0], &v.mpfr[0], C.MPFR_RNDN)
+	return f
+}
+
+// LogProductAccumulator computes the product of a stream of positive Floats, such
+// as the probabilities in a likelihood, by summing their natural logarithms.
+//
+// A direct product of many small factors underflows: 10000 factors of 0.01 are
+// 1e-20000, far below the float64 range. The log sum stays moderate, so ResultLog
+// returns ln of the product without ever forming it, and Result forms it only at
+// the end, in MPFR's much wider exponent range. The sum is kept with accGuardBits
+// bits beyond the requested precision, like MeanAccumulator's; since Result's
+// relative error is the sum's absolute error, it stays below an ulp while the log
+// sum is under 2^32 in magnitude. The zero value is not usable; create one with
+// NewLogProductAccumulator. A LogProductAccumulator must not be used concurrently.
+type LogProductAccumulator struct {
+	prec   uint
+	logSum *Float
+	n      uint64
+
+	// scratch space reused by MulValue
+	term *Float
+}
+
+// NewLogProductAccumulator returns an empty accumulator, representing the empty
+// product 1, whose results are rounded to prec bits.
+func NewLogProductAccumulator(prec uint) *LogProductAccumulator {
+	w := prec + accGuardBits
+	return &LogProductAccumulator{
+		prec:   prec,
+		logSum: NewFloatWithPrec(w),
+		term:   NewFloatWithPrec(w),
+	}
+}
+
+// MulValue multiplies the accumulated product by x, by adding ln x to the log sum.
+// A zero x makes the product 0 (and ResultLog -Inf); a negative or NaN x makes
+// both results NaN. x is not retained.
+func (p *LogProductAccumulator) MulValue(x *Float) {
+	x.doinit()
+	C.mpfr_log(&p.term.mpfr[0], &x.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_add(&p.logSum.mpfr[0], &p.logSum.mpfr[0], &p.term.mpfr[0], C.MPFR_RNDN)
+	p.n++
+}
+
+// Count returns the number of factors multiplied in so far.
+func (p *LogProductAccumulator) Count() uint64 {
+	return p.n
+}
+
+// ResultLog returns the natural logarithm of the product as a new Float with the
+// accumulator's precision, rounded to nearest. It is 0 if no factors were added.
+func (p *LogProductAccumulator) ResultLog() *Float {
+	f := NewFloatWithPrec(p.prec)
+	C.mpfr_set(&f.mpfr[0], &p.logSum.mpfr[0], C.MPFR_RNDN)
+	return f
+}
+
+// Result returns the product as a new Float with the accumulator's precision,
+// rounded to nearest. It is 1 if no factors were added. A product outside the
+// current exponent range (see SetEmin and SetEmax) still underflows to 0 or overflows
+// to +Inf; use ResultLog for those.
+func (p *LogProductAccumulator) Result() *Float {
+	f := NewFloatWithPrec(p.prec)
+	C.mpfr_exp(&f.mpfr[0], &p.logSum.mpfr[0], C.MPFR_RNDN)
+	return f
+}
+
+// Accumulator computes a running sum of products Σ xᵢ·yᵢ, the inner-product step
+// of Gram-Schmidt, residual computation and other linear-algebra loops, and tracks
+// whether the accumulated value is still exact.
+//
+// The sum is kept with accGuardBits bits beyond the requested precision and each
+// product is added with a single fused multiply-add, so every step rounds at most
+// once. MPFR reports the direction of each rounding; once any step rounds, Exact
+// is false from then on. Iterative refinement can use this to tell an exactly zero
+// residual from one that merely rounded to zero. The zero value is not usable;
+// create one with NewAccumulator. An Accumulator must not be used concurrently.
+type Accumulator struct {
+	prec    uint
+	sum     *Float
+	inexact bool
+}
+
+// NewAccumulator returns an empty accumulator, holding an exact 0, whose result is
+// rounded to prec bits.
+func NewAccumulator(prec uint) *Accumulator {
+	return &Accumulator{
+		prec: prec,
+		sum:  NewFloatWithPrec(prec + accGuardBits),
+	}
+}
+
+// AddProductTernary adds x·y to the accumulated sum, rounding to nearest, and returns
+// the ternary value of the step: 0 if the new sum is exact, positive if it was
+// rounded up and negative if it was rounded down. x and y are not retained.
+func (a *Accumulator) AddProductTernary(x, y *Float) int {
+	x.doinit()
+	y.doinit()
+	t := int(C.mpfr_fma(&a.sum.mpfr[0], &x.mpfr[0], &y.mpfr[0], &a.sum.mpfr[0], C.MPFR_RNDN))
+	if t != 0 {
+		a.inexact = true
+	}
+	return t
+}
+
+// Exact reports whether every product added so far was accumulated without
+// rounding, so that the working sum equals the true Σ xᵢ·yᵢ. It does not account
+// for the final rounding to the accumulator's precision done by Result.
+func (a *Accumulator) Exact() bool {
+	return !a.inexact
+}
+
+// Result returns the accumulated sum as a new Float with the accumulator's
+// precision, rounded to nearest. It is 0 if nothing was added.
+func (a *Accumulator) Result() *Float {
+	f := NewFloatWithPrec(a.prec)
+	C.mpfr_set(&f.mpfr[0], &a.sum.mpfr[0], C.MPFR_RNDN)
+	return f
+}
+
+// GetBytesBE returns a self-describing, fixed-layout binary encoding of f:
+//
+//	byte 0      bit 0: sign (1 = negative); bits 1-2: Kind (zero, finite, Inf, NaN);
+//	            bits 3-7: zero
+//	varint      signed exponent e (encoding/binary varint), 0 unless finite
+//	n bytes     significand, big-endian, n = ceil(prec/8)
+//
+// A finite value is (-1)^sign × 0.s × 2^e, where 0.s is the significand bytes read
+// as a binary fraction with its top bit set. The significand is zero for the other
+// kinds but still occupies n bytes, so the byte count always carries the precision.
+// Unlike gob, the layout depends on nothing but this description.
+func (f *Float) GetBytesBE() []byte {
+	neg, mantissa, exp, kind := f.Decompose()
+	n := (f.GetPrec() + 7) / 8
+
+	head := byte(kind) << 1
+	if neg {
+		head |= 1
+	}
+	b := []byte{head}
+	if kind == KindFinite {
+		bitLen := mantissa.BitLen()
+		exp += bitLen
+		mantissa.Lsh(mantissa, 8*n-uint(bitLen))
+	}
+	b = binary.AppendVarint(b, int64(exp))
+	sig := make([]byte, n)
+	if kind == KindFinite {
+		mantissa.FillBytes(sig)
+	}
+	return append(b, sig...)
+}
+
+// SetBytesBE sets f to the value encoded by GetBytesBE and returns nil, or returns
+// ErrInvalidBytes and leaves f unchanged. f's precision becomes 8 times the number of
+// significand bytes, so it is at least the precision of the encoded Float and the
+// value is reproduced exactly.
+//
+// The exponent may be anything MPFR supports, so a value encoded after SetEmax or
+// SetEmin widened the range decodes while the range is as wide. Outside the current
+// range SetBytesBE returns ErrRange and leaves f unchanged.
+func (f *Float) SetBytesBE(b []byte) error {
+	if len(b) < 2 || b[0]>>3 != 0 {
+		return ErrInvalidBytes
+	}
+	neg, kind := b[0]&1 != 0, Kind(b[0]>>1)
+	exp, k := binary.Varint(b[1:])
+	if k <= 0 {
+		return ErrInvalidBytes
+	}
+	sig := b[1+k:]
+	if len(sig) == 0 {
+		return ErrInvalidBytes
+	}
+	prec := 8 * uint(len(sig))
+
+	mantissa := new(big.Int).SetBytes(sig)
+	if kind == KindFinite {
+		if sig[0]&0x80 == 0 {
+			return ErrInvalidBytes
+		}
+		if exp < int64(GetEmin()) || exp > int64(GetEmax()) {
+			return ErrRange
+		}
+		exp -= int64(prec)
+	} else if mantissa.Sign() != 0 || exp != 0 {
+		return ErrInvalidBytes
+	}
+
+	f.doinit()
+	var g *Float
+	if kind == KindInf || kind == KindNaN {
+		g = ComposeSpecial(neg, kind, prec)
+	} else {
+		g = Compose(neg, mantissa, int(exp), prec)
+	}
+	f.SetPrecRnd(g, prec, f.RoundingMode)
+	return nil
+}
+
+// integrateMaxDepth bounds the recursion of Integrate. Each level halves the
+// interval, so the finest subinterval is (b-a)/2^integrateMaxDepth.
+const integrateMaxDepth = 50
+
+// integrateMaxEvals bounds the total number of integrand evaluations in one call
+// to Integrate or IntegrateErr. The depth limit alone only bounds each branch: a tol that cannot
+// be met at the working precision would otherwise refine every subinterval to
+// integrateMaxDepth levels, about 2^50 evaluations.
+const integrateMaxEvals = 1 << 16
+
+// Integrate returns an approximation of the integral of fn over [a, b] using
+// adaptive Simpson quadrature at prec bits, rounding arithmetic with rnd.
+//
+// Each interval is split until the Simpson estimates of the whole and its two
+// halves agree to within 15·tol, with tol halved at each split, and the halves are
+// then combined with Richardson extrapolation. A branch stops splitting at
+// integrateMaxDepth levels (intervals of (b-a)/2^50) even if the tolerance has not
+// been met, which limits the refinement around singularities or discontinuities
+// at the cost of accuracy there. The total work is bounded separately: once fn has
+// been evaluated integrateMaxEvals (2^16) times no further splits are made and the
+// estimate built so far is returned. This is what happens when tol is too small to
+// be reached at prec bits; use IntegrateErr to find out whether it did. fn is
+// called with Floats of precision prec; it may return any Float and must not
+// retain its argument.
+//
+// Integrate does not check its inputs: if fn returns NaN or ±Inf anywhere it is
+// sampled, the result is NaN or ±Inf.
+func Integrate(fn func(*Float) *Float, a, b *Float, prec uint, tol *Float, rnd Rnd) *Float {
+	sum, _ := IntegrateErr(fn, a, b, prec, tol, rnd)
+	return sum
+}
+
+// IntegrateErr is like Integrate but also reports whether tol was met: it returns
+// ErrNoConvergence, along with the same estimate Integrate would return, if the
+// evaluation budget ran out before every interval had converged.
+func IntegrateErr(fn func(*Float) *Float, a, b *Float, prec uint, tol *Float, rnd Rnd) (*Float, error) {
+	a.doinit()
+	b.doinit()
+	tol.doinit()
+
+	r := C.mpfr_rnd_t(rnd)
+	newF := func() *Float {
+		// Every temporary is assigned before it is read, so skip NewFloatWithPrec's
+		// value-preserving SetPrec and leave the fresh mpfr_t as NaN.
+		f := &Float{}
+		f.doinit()
+		C.mpfr_set_prec(&f.mpfr[0], C.mpfr_prec_t(prec))
+		f.RoundingMode = rnd
+		return f
+	}
+	evals := 0
+	eval := func(x *Float) *Float {
+		evals++
+		y := newF()
+		C.mpfr_set(&y.mpfr[0], &fn(x).mpfr[0], r)
+		return y
+	}
+	// simpson returns the midpoint of [lo, hi], fn there and (hi-lo)/6·(flo + 4·fm + fhi).
+	simpson := func(lo, flo, hi, fhi *Float) (m, fm, s *Float) {
+		m = newF()
+		C.mpfr_add(&m.mpfr[0], &lo.mpfr[0], &hi.mpfr[0], r)
+		C.mpfr_mul_2si(&m.mpfr[0], &m.mpfr[0], -1, r)
+		fm = eval(m)
+		s = newF()
+		C.mpfr_mul_2si(&s.mpfr[0], &fm.mpfr[0], 2, r)
+		C.mpfr_add(&s.mpfr[0], &s.mpfr[0], &flo.mpfr[0], r)
+		C.mpfr_add(&s.mpfr[0], &s.mpfr[0], &fhi.mpfr[0], r)
+		h := newF()
+		C.mpfr_sub(&h.mpfr[0], &hi.mpfr[0], &lo.mpfr[0], r)
+		C.mpfr_mul(&s.mpfr[0], &s.mpfr[0], &h.mpfr[0], r)
+		C.mpfr_div_ui(&s.mpfr[0], &s.mpfr[0], 6, r)
+		return m, fm, s
+	}
+
+	exhausted := false
+	var step func(lo, flo, hi, fhi, m, fm, whole, tol *Float, depth int) *Float
+	step = func(lo, flo, hi, fhi, m, fm, whole, tol *Float, depth int) *Float {
+		lm, flm, left := simpson(lo, flo, m, fm)
+		rm, frm, right := simpson(m, fm, hi, fhi)
+
+		// delta = left + right - whole; the extrapolated result is left + right + delta/15.
+		sum := newF()
+		C.mpfr_add(&sum.mpfr[0], &left.mpfr[0], &right.mpfr[0], r)
+		delta := newF()
+		C.mpfr_sub(&delta.mpfr[0], &sum.mpfr[0], &whole.mpfr[0], r)
+
+		bound := newF()
+		C.mpfr_mul_ui(&bound.mpfr[0], &tol.mpfr[0], 15, r)
+		converged := C.mpfr_cmpabs(&delta.mpfr[0], &bound.mpfr[0]) <= 0
+		if !converged && evals >= integrateMaxEvals {
+			exhausted = true
+		}
+		if depth <= 0 || converged || exhausted {
+			C.mpfr_div_ui(&delta.mpfr[0], &delta.mpfr[0], 15, r)
+			C.mpfr_add(&sum.mpfr[0], &sum.mpfr[0], &delta.mpfr[0], r)
+			return sum
+		}
+
+		half := newF()
+		C.mpfr_mul_2si(&half.mpfr[0], &tol.mpfr[0], -1, r)
+		lv := step(lo, flo, m, fm, lm, flm, left, half, depth-1)
+		rv := step(m, fm, hi, fhi, rm, frm, right, half, depth-1)
+		C.mpfr_add(&lv.mpfr[0], &lv.mpfr[0], &rv.mpfr[0], r)
+		return lv
+	}
+
+	lo, hi := newF(), newF()
+	C.mpfr_set(&lo.mpfr[0], &a.mpfr[0], r)
+	C.mpfr_set(&hi.mpfr[0], &b.mpfr[0], r)
+	flo, fhi := eval(lo), eval(hi)
+	m, fm, whole := simpson(lo, flo, hi, fhi)
+	sum := step(lo, flo, hi, fhi, m, fm, whole, tol, integrateMaxDepth)
+	if exhausted {
+		return sum, ErrNoConvergence
+	}
+	return sum, nil
+}
+
+// Bisect finds a root of fn in [lo, hi] by bisection at prec bits, rounding with rnd.
+//
+// fn(lo) and fn(hi) must have opposite signs (or one of them must be zero);
+// otherwise, or if either is NaN, Bisect returns ErrNotBracketed. Each iteration
+// evaluates fn at the midpoint, formed with Half so the only rounding is in lo + hi,
+// and keeps the half that still brackets a sign change. Unlike Newton's method it
+// cannot diverge: after k iterations the root lies within (hi-lo)/2^k of the
+// result. Bisect stops after iters iterations, when fn is exactly zero, or when
+// the interval can no longer be split at prec bits.
+func Bisect(fn func(*Float) *Float, lo, hi *Float, prec uint, iters int, rnd Rnd) (*Float, error) {
+	a := NewFloat().SetPrecRnd(lo, prec, rnd)
+	b := NewFloat().SetPrecRnd(hi, prec, rnd)
+	fa, fb := fn(a), fn(b)
+	if fa.IsNaN() || fb.IsNaN() {
+		return nil, ErrNotBracketed
+	}
+	sa, sb := fa.Sign(), fb.Sign()
+	switch {
+	case sa == 0:
+		return a, nil
+	case sb == 0:
+		return b, nil
+	case SameSign(fa, fb):
+		return nil, ErrNotBracketed
+	}
+
+	m := NewFloatWithPrec(prec)
+	m.SetRoundMode(rnd)
+	for i := 0; i < iters; i++ {
+		m.Copy(a).Add(b).Half()
+		if m.Cmp(a) == 0 || m.Cmp(b) == 0 {
+			break
+		}
+		sm := fn(m).Sign()
+		if sm == 0 {
+			return m, nil
+		}
+		if sm == sa {
+			a.Copy(m)
+		} else {
+			b.Copy(m)
+		}
+	}
+	return m.Copy(a).Add(b).Half(), nil
+}
+
+// FixedPoint iterates x ← g(x) from x0 at prec bits, rounding each iterate with
+// rnd, until two successive iterates differ by less than tol. It returns the last
+// iterate and the number of calls to g. g receives the current iterate and must
+// not modify it; the Float it returns is rounded into a fresh iterate.
+//
+// The iteration converges when g is a contraction near the fixed point, |g'| < 1,
+// and then the error shrinks by about |g'| per step, so tol should sit a few bits
+// above the ulp at prec or the loop may never settle. If the iterates still
+// differ by tol or more after maxIters steps, or become NaN, FixedPoint returns
+// the last iterate together with ErrNoConvergence.
+func FixedPoint(g func(*Float) *Float, x0 *Float, prec uint, maxIters int, tol *Float, rnd Rnd) (*Float, int, error) {
+	tol.doinit()
+	x := NewFloat().SetPrecRnd(x0, prec, rnd)
+	diff := NewFloatWithPrec(prec)
+	defer diff.Clear()
+	for i := 1; i <= maxIters; i++ {
+		next := NewFloat().SetPrecRnd(g(x), prec, rnd)
+		C.mpfr_sub(&diff.mpfr[0], &next.mpfr[0], &x.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_abs(&diff.mpfr[0], &diff.mpfr[0], C.MPFR_RNDN)
+		x = next
+		if C.mpfr_nan_p(&diff.mpfr[0]) != 0 {
+			return x, i, ErrNoConvergence
+		}
+		if C.mpfr_less_p(&diff.mpfr[0], &tol.mpfr[0]) != 0 {
+			return x, i, nil
+		}
+	}
+	return x, maxIters, ErrNoConvergence
+}
+
+// derivLevels is the number of step sizes NumericalDeriv combines by Richardson
+// extrapolation.
+const derivLevels = 5
+
+// NumericalDeriv returns an estimate of g'(x) rounded to prec bits with rnd, for a g
+// whose derivative is not available in closed form (for example to drive a Newton
+// iteration).
+//
+// It evaluates central differences (g(x+h) - g(x-h)) / 2h for derivLevels steps
+// h, h/2, h/4, … and removes the h², h⁴, … error terms by Richardson extrapolation.
+// The first step is 2^(-prec/3) scaled to the magnitude of x, and g is called with
+// arguments carrying prec + prec/3 + 64 bits, so g should compute at the precision of
+// its argument, as the package-level functions do with the default result precision.
+//
+// Accuracy trade-offs: a smaller step reduces the truncation error but magnifies the
+// rounding error in g by 1/h, which is why g is evaluated with extra bits. For smooth
+// g with moderate higher derivatives near x the result is accurate to nearly prec
+// bits. It degrades when g has large higher derivatives on the scale of the step (for
+// example near a pole or a kink), when g itself is only accurate to fewer bits than
+// it is given, or when x is within a step of the edge of g's domain. A NaN or infinite
+// x, or a NaN from g, gives NaN.
+func NumericalDeriv(g func(*Float) *Float, x *Float, prec uint, rnd Rnd) *Float {
+	x.doinit()
+	f := NewFloatWithPrec(prec)
+	f.SetRoundMode(rnd)
+	if C.mpfr_number_p(&x.mpfr[0]) == 0 {
+		C.mpfr_set_nan(&f.mpfr[0])
+		return f
+	}
+
+	wp := C.mpfr_prec_t(prec + prec/3 + 64)
+	scale := C.mpfr_exp_t(0)
+	if C.mpfr_regular_p(&x.mpfr[0]) != 0 && C.mpfr_get_exp(&x.mpfr[0]) > 0 {
+		scale = C.mpfr_get_exp(&x.mpfr[0])
+	}
+	// x ± h is rounded to argPrec bits. It is exact unless x has bits far below h,
+	// as a tiny nonzero |x| can; then each sample moves by less than 2^-(prec+64)
+	// of h, which is negligible next to the accuracy sought.
+	argPrec := max(C.mpfr_get_prec(&x.mpfr[0]), wp) + derivLevels + 2
+	xp, xm := &Float{}, &Float{}
+	xp.doinit()
+	xm.doinit()
+	C.mpfr_set_prec(&xp.mpfr[0], argPrec)
+	C.mpfr_set_prec(&xm.mpfr[0], argPrec)
+	h := NewFloatWithPrec(uint(wp))
+	defer h.Clear()
+
+	// d[j] holds the j-th column of the Richardson (Neville) table.
+	d := make([]*Float, derivLevels)
+	for j := range d {
+		C.mpfr_set_ui_2exp(&h.mpfr[0], 1, C.mpfr_exp_t(int64(scale)-int64(prec/3)-int64(j)), C.MPFR_RNDN)
+		C.mpfr_add(&xp.mpfr[0], &x.mpfr[0], &h.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_sub(&xm.mpfr[0], &x.mpfr[0], &h.mpfr[0], C.MPFR_RNDN)
+		d[j] = NewFloatWithPrec(uint(wp))
+		defer d[j].Clear()
+		gp, gm := g(xp), g(xm)
+		gp.doinit()
+		gm.doinit()
+		C.mpfr_sub(&d[j].mpfr[0], &gp.mpfr[0], &gm.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_div(&d[j].mpfr[0], &d[j].mpfr[0], &h.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_div_2ui(&d[j].mpfr[0], &d[j].mpfr[0], 1, C.MPFR_RNDN)
+	}
+	diff := NewFloatWithPrec(uint(wp))
+	defer diff.Clear()
+	for k := 1; k < derivLevels; k++ {
+		// Halving h divides the h^(2k) term by 4^k.
+		for j := 0; j+k < derivLevels; j++ {
+			C.mpfr_sub(&diff.mpfr[0], &d[j+1].mpfr[0], &d[j].mpfr[0], C.MPFR_RNDN)
+			C.mpfr_div_ui(&diff.mpfr[0], &diff.mpfr[0], C.ulong(1)<<(2*k)-1, C.MPFR_RNDN)
+			C.mpfr_add(&d[j].mpfr[0], &d[j+1].mpfr[0], &diff.mpfr[0], C.MPFR_RNDN)
+		}
+	}
+	C.mpfr_set(&f.mpfr[0], &d[0].mpfr[0], C.mpfr_rnd_t(rnd))
+	return f
+}
+
+// ContinuedFraction evaluates the generalized continued fraction
+//
+//	b[0] + a[0]/(b[1] + a[1]/(b[2] + … + a[n-1]/b[n]))
+//
+// and returns it rounded to prec bits with rnd. In the usual notation
+// b₀ + a₁/(b₁ + a₂/(b₂ + …)), a[i-1] holds aᵢ, so len(a) must be len(b)-1; a simple
+// continued fraction [b₀; b₁, b₂, …] has every a[i] equal to 1. ContinuedFraction
+// panics if the lengths do not match or b is empty.
+//
+// The fraction is evaluated from the innermost term outward (backward recurrence)
+// with 64 guard bits, so only the final result is rounded with rnd.
+func ContinuedFraction(a []*Float, b []*Float, prec uint, rnd Rnd) *Float {
+	if len(b) == 0 || len(a) != len(b)-1 {
+		panic("ContinuedFraction requires len(a) == len(b)-1 and len(b) > 0")
+	}
+	t := NewFloatWithPrec(prec + 64)
+	defer t.Clear()
+	last := b[len(b)-1]
+	last.doinit()
+	C.mpfr_set(&t.mpfr[0], &last.mpfr[0], C.MPFR_RNDN)
+	for i := len(a) - 1; i >= 0; i-- {
+		a[i].doinit()
+		b[i].doinit()
+		C.mpfr_div(&t.mpfr[0], &a[i].mpfr[0], &t.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_add(&t.mpfr[0], &t.mpfr[0], &b[i].mpfr[0], C.MPFR_RNDN)
+	}
+	f := NewFloatWithPrec(prec)
+	f.SetRoundMode(rnd)
+	C.mpfr_set(&f.mpfr[0], &t.mpfr[0], C.mpfr_rnd_t(rnd))
+	return f
+}
+
+// ClenshawChebyshev evaluates the Chebyshev series
+//
+//	coeffs[0]·T₀(x) + coeffs[1]·T₁(x) + … + coeffs[n-1]·Tₙ₋₁(x)
+//
+// at x with the Clenshaw recurrence and returns it rounded to prec bits with rnd.
+// coeffs[0] is used as given, not halved: for a series in the common Σ' form,
+// whose first term is c₀/2, pass c₀/2 as coeffs[0]. An empty series evaluates to 0.
+//
+// Each step bₖ = 2x·bₖ₊₁ − bₖ₊₂ + cₖ is one MulAddAssign at 64 guard bits, so the
+// recurrence stays stable on [-1, 1] and only the final result is rounded with rnd.
+func ClenshawChebyshev(coeffs []*Float, x *Float, prec uint, rnd Rnd) *Float {
+	x.doinit()
+	wp := prec + 64
+	twoX := NewFloatWithPrec(wp)
+	C.mpfr_mul_2ui(&twoX.mpfr[0], &x.mpfr[0], 1, C.MPFR_RNDN)
+	b1, b2, t := NewFloatWithPrec(wp), NewFloatWithPrec(wp), NewFloatWithPrec(wp)
+	for _, g := range []*Float{twoX, b1, b2, t} {
+		g.SetRoundMode(RoundToNearest)
+	}
+	for k := len(coeffs) - 1; k >= 1; k-- {
+		c := coeffs[k]
+		c.doinit()
+		C.mpfr_sub(&t.mpfr[0], &c.mpfr[0], &b2.mpfr[0], C.MPFR_RNDN)
+		C.mpfr_swap(&b2.mpfr[0], &b1.mpfr[0])
+		// b2 now holds bₖ₊₁; overwrite b1 with bₖ = bₖ₊₁·2x + (cₖ − bₖ₊₂).
+		C.mpfr_set(&b1.mpfr[0], &b2.mpfr[0], C.MPFR_RNDN)
+		b1.MulAddAssign(twoX, t)
+	}
+	f := NewFloatWithPrec(prec)
+	f.SetRoundMode(rnd)
+	if len(coeffs) == 0 {
+		return f
+	}
+	// The last step uses x rather than 2x: result = x·b₁ − b₂ + c₀.
+	coeffs[0].doinit()
+	C.mpfr_sub(&t.mpfr[0], &coeffs[0].mpfr[0], &b2.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_fma(&t.mpfr[0], &b1.mpfr[0], &x.mpfr[0], &t.mpfr[0], C.MPFR_RNDN)
+	C.mpfr_set(&f.mpfr[0], &t.mpfr[0], C.mpfr_rnd_t(rnd))
+	return f
+}
+
+// PolyEvalWithDeriv evaluates the polynomial
+//
+//	coeffs[0] + coeffs[1]·x + … + coeffs[n-1]·xⁿ⁻¹
+//
+// and its derivative at x in a single Horner pass, returning both rounded to prec
+// bits with rnd; coeffs[i] is the coefficient of xⁱ, lowest degree first as in
+// ClenshawChebyshev. This is what one Newton step p(x)/p'(x) needs. An empty
+// polynomial evaluates to 0 with derivative 0.
+//
+// Each step is one MulAddAssign at 64 guard bits, for the derivative
+// d = d·x + p and then the value p = p·x + cᵢ, so only the final results are
+// rounded with rnd.
+func PolyEvalWithDeriv(coeffs []*Float, x *Float, prec uint, rnd Rnd) (value, deriv *Float) {
+	x.doinit()
+	wp := prec + 64
+	p, d := NewFloatWithPrec(wp), NewFloatWithPrec(wp)
+	p.SetRoundMode(RoundToNearest)
+	d.SetRoundMode(RoundToNearest)
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		c := coeffs[i]
+		c.doinit()
+		d.MulAddAssign(x, p)
+		p.MulAddAssign(x, c)
+	}
+	value, deriv = NewFloatWithPrec(prec), NewFloatWithPrec(prec)
+	value.SetRoundMode(rnd)
+	deriv.SetRoundMode(rnd)
+	C.mpfr_set(&value.mpfr[0], &p.mpfr[0], C.mpfr_rnd_t(rnd))
+	C.mpfr_set(&deriv.mpfr[0], &d.mpfr[0], C.mpfr_rnd_t(rnd))
+	return value, deriv
+}
+
+// IEEE 754 binary128 parameters, in MPFR's exponent convention (x = 0.m × 2^E).
+const (
+	float128Prec    = 113
+	float128Bias    = 16383
+	float128EmaxE   = 16384  // largest E of a finite binary128
+	float128EminE   = -16381 // smallest E of a normal binary128
+	float128MinExp2 = -16494 // the smallest subnormal is 2^-16494
+)
+
+// HasFloat128 reports whether the linked MPFR library was built with binary128
+// support (configure --enable-float128, reported by mpfr_buildopt_float128_p).
+// GetFloat128 and SetFloat128 return ErrUnsupported when it is false.
+func HasFloat128() bool {
+	return C.mpfr_buildopt_float128_p() != 0
+}
+
+// GetFloat128 returns the IEEE 754 binary128 (quadruple precision) encoding of f,
+// rounded with f's RoundingMode, as the high and low 64 bits of the 128-bit pattern.
+// Go has no 128-bit float type, so this is the interchange form, analogous to
+// math.Float64bits. Values too small for the normal range are rounded to binary128
+// subnormals; values too large overflow to ±Inf or to the largest finite value,
+// as IEEE 754 prescribes for the rounding mode. NaN encodes as a quiet NaN.
+// It returns ErrUnsupported if HasFloat128 is false.
+func (f *Float) GetFloat128() (hi, lo uint64, err error) {
+	if !HasFloat128() {
+		return 0, 0, ErrUnsupported
+	}
+	f.doinit()
+	rnd := C.mpfr_rnd_t(f.RoundingMode)
+	var sign uint64
+	if C.mpfr_signbit(&f.mpfr[0]) != 0 {
+		sign = 1 << 63
+	}
+	switch {
+	case C.mpfr_nan_p(&f.mpfr[0]) != 0:
+		return sign | 0x7fff8000_00000000, 0, nil
+	case C.mpfr_inf_p(&f.mpfr[0]) != 0:
+		return sign | 0x7fff0000_00000000, 0, nil
+	case C.mpfr_zero_p(&f.mpfr[0]) != 0:
+		return sign, 0, nil
+	}
+
+	var bits128 *big.Int
+	if C.mpfr_get_exp(&f.mpfr[0]) < float128EminE {
+		// Subnormal range: round |f| to an integer multiple n of 2^-16494. The pattern
+		// is n itself; if rounding carries n up to 2^112 it becomes the smallest
+		// normal, whose exponent field of 1 is exactly the carried bit.
+		y := NewFloatWithPrec(f.GetPrec())
+		defer y.Clear()
+		C.mpfr_mul_2si(&y.mpfr[0], &f.mpfr[0], -float128MinExp2, C.MPFR_RNDN)
+		z := NewFloatWithPrec(float128Prec)
+		defer z.Clear()
+		C.mpfr_rint(&z.mpfr[0], &y.mpfr[0], rnd)
+		_, m, exp, _ := z.Decompose() // |n| = m × 2^exp
+		if exp >= 0 {
+			bits128 = m.Lsh(m, uint(exp))
+		} else {
+			bits128 = m.Rsh(m, uint(-exp))
+		}
+	} else {
+		y := NewFloat().SetPrecRnd(f, float128Prec, f.RoundingMode)
+		defer y.Clear()
+		e := int(C.mpfr_get_exp(&y.mpfr[0]))
+		if e > float128EmaxE {
+			toInf := f.RoundingMode == RoundToNearest || f.RoundingMode == RoundAway ||
+				(f.RoundingMode == RoundUp && sign == 0) || (f.RoundingMode == RoundDown && sign != 0)
+			if toInf {
+				return sign | 0x7fff0000_00000000, 0, nil
+			}
+			return sign | 0x7ffeffff_ffffffff, 0xffffffff_ffffffff, nil
+		}
+		// Normalize the significand to 2^112 <= m < 2^113, then replace the implicit
+		// leading bit with the biased exponent.
+		_, m, _, _ := y.Decompose()
+		m.Lsh(m, uint(float128Prec-m.BitLen()))
+		m.SetBit(m, float128Prec-1, 0)
+		bits128 = m.Or(m, new(big.Int).Lsh(big.NewInt(int64(e-1+float128Bias)), float128Prec-1))
+	}
+
+	lo = bits128.Uint64()
+	hi = new(big.Int).Rsh(bits128, 64).Uint64()
+	return sign | hi, lo, nil
+}
+
+// SetFloat128 sets f to the IEEE 754 binary128 value with the given high and low
+// 64 bits, rounded to f's precision with f's RoundingMode, and returns f. Every
+// binary128 value, subnormals included, is exact at 113 bits or more. All NaN
+// patterns become NaN. It returns ErrUnsupported, leaving f unchanged, if
+// HasFloat128 is false.
+func (f *Float) SetFloat128(hi, lo uint64) (*Float, error) {
+	if !HasFloat128() {
+		return f, ErrUnsupported
+	}
+	f.doinit()
+	f.markDirty()
+	neg := hi>>63 != 0
+	expField := int(hi >> 48 & 0x7fff)
+	frac := new(big.Int).Lsh(new(big.Int).SetUint64(hi&0xffff_ffffffff), 64)
+	frac.Or(frac, new(big.Int).SetUint64(lo))
+
+	var g *Float
+	switch {
+	case expField == 0x7fff && frac.Sign() != 0:
+		g = ComposeSpecial(neg, KindNaN, float128Prec)
+	case expField == 0x7fff:
+		g = ComposeSpecial(neg, KindInf, float128Prec)
+	case expField == 0:
+		g = Compose(neg, frac, float128MinExp2, float128Prec)
+	default:
+		frac.SetBit(frac, float128Prec-1, 1)
+		g = Compose(neg, frac, expField-float128Bias-(float128Prec-1), float128Prec)
+	}
+	C.mpfr_set(&f.mpfr[0], &g.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))
+	return f, nil
+}
+
+// HasDecimal64 reports whether the linked MPFR library was built with decimal
+// float support (configure --enable-decimal-float, reported by
+// mpfr_buildopt_decimal_p) and the C compiler provides _Decimal64. GetDecimal64 and
+// SetDecimal64 return ErrUnsupported when it is false.
+func HasDecimal64() bool {
+	return C.gompfr_have_decimal64() != 0
+}
+
+// GetDecimal64 returns the IEEE 754 decimal64 encoding of f, rounded with f's
+// RoundingMode. The encoding is the platform's _Decimal64 format: binary integer
+// decimal (BID) on x86-64 and most other targets, densely packed decimal on POWER.
+// It returns ErrUnsupported if HasDecimal64 is false.
+func (f *Float) GetDecimal64() (uint64, error) {
+	if !HasDecimal64() {
+		return 0, ErrUnsupported
+	}
+	f.doinit()
+	return uint64(C.gompfr_get_decimal64_bits(&f.mpfr[0], C.mpfr_rnd_t(f.RoundingMode))), nil
+}
+
+// SetDecimal64 sets f to the decimal64 value with the given encoding (see
+// GetDecimal64), rounded to f's precision with f's RoundingMode, and returns f.
+// It returns ErrUnsupported, leaving f unchanged, if HasDecimal64 is false.
+func (f *Float) SetDecimal64(bits uint64) (*Float, error) {
+	if !HasDecimal64() {
+		return f, ErrUnsupported
+	}
+	f.doinit()
+	f.markDirty()
+	C.gompfr_set_decimal64_bits(&f.mpfr[0], C.uint64_t(bits), C.mpfr_rnd_t(f.RoundingMode))
+	return f, nil
+}
@@ -0,0 +1,87 @@
+package mpfr
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// TestTrigPiFallback checks the reduction used without mpfr_sinpi and mpfr_cospi
+// against those functions.
+func TestTrigPiFallback(t *testing.T) {
+	if !haveTrigPi {
+		t.Skip("MPFR has no mpfr_sinpi or mpfr_cospi to compare against")
+	}
+	var xs []*Float
+	for _, prec := range []uint{24, 53, 200} {
+		add := func(num, den int64, exp2 int) {
+			x := NewFloatWithPrec(prec).SetFrac64(num, den)
+			xs = append(xs, x.Scalbn(x, exp2))
+		}
+		for _, v := range [][2]int64{
+			{0, 1}, {1, 4}, {1, 2}, {1, 1}, {3, 2}, {2, 1}, {-1, 2}, {-3, 1},
+			{1, 3}, {-7, 3}, {5, 7}, {11, 40}, {9, 40}, {1, 6},
+		} {
+			add(v[0], v[1], 0)
+		}
+		// Tiny arguments, where 1/2 - t is not representable at x's precision.
+		add(1, 1, -30)
+		add(3, 1, -70)
+		add(-1, 3, -1000)
+		// Just below 1/2 and 1, and large arguments.
+		add(1<<40-1, 1<<41, 0)
+		add(1<<50-1, 1<<50, 0)
+		add(1000000000001, 4, 0)
+		add(-17, 7, 60)
+	}
+	xs = append(xs, NewFloat().Neg(NewFloat()))
+
+	for _, x := range xs {
+		for _, prec := range []uint{24, 53, 300} {
+			for _, rnd := range []Rnd{RoundToNearest, RoundUp, RoundDown} {
+				for _, cosine := range []bool{false, true} {
+					got := NewFloatWithPrec(prec)
+					got.RoundingMode = rnd
+					got.doinit()
+					got.trigPi(x, cosine)
+					want := NewFloatWithPrec(prec)
+					want.RoundingMode = rnd
+					name := "sinpi"
+					if cosine {
+						name = "cospi"
+						want.CosPi(x)
+					} else {
+						want.SinPi(x)
+					}
+					desc := fmt.Sprintf("%s(%v) at %d bits, %v", name, x, prec, rnd)
+					if !got.EqualULP(want, 1) {
+						t.Errorf("%s = %v; want %v", desc, got, want)
+					}
+					// Exact results must match exactly, including the sign of zero.
+					if want.IsZero() || want.Cmp(NewFloat().SetInt64(1)) == 0 || want.Cmp(NewFloat().SetInt64(-1)) == 0 {
+						if got.Cmp(want) != 0 || got.Signbit() != want.Signbit() {
+							t.Errorf("%s = %v; want exactly %v", desc, got, want)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestAbsUint(t *testing.T) {
+	for _, tt := range []struct {
+		n    int
+		want uint
+	}{
+		{0, 0},
+		{7, 7},
+		{-7, 7},
+		{math.MaxInt, math.MaxInt},
+		{math.MinInt, math.MaxInt + 1},
+	} {
+		if got := absUint(tt.n); got != tt.want {
+			t.Errorf("absUint(%d) = %d; want %d", tt.n, got, tt.want)
+		}
+	}
+}
@@ -1,13 +1,17 @@
 package mpfr_test
 
 import (
+	"errors"
 	"fmt"
 	"github.com/mexicantexan/go-mpfr"
 	"math"
 	"math/big"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 const eps = 1e-13
@@ -55,6 +59,21 @@ func TestNewFloat(t *testing.T) {
 	}
 }
 
+func TestNewFloatDefaultRound(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if got := mpfr.NewFloat().RoundingMode; got != mpfr.RoundToNearest {
+		t.Errorf("NewFloat().RoundingMode = %v; want RoundToNearest", got)
+	}
+
+	defer mpfr.SaveState().Restore()
+	mpfr.SetDefaultRound(mpfr.RoundDown)
+	if got := mpfr.NewFloat().RoundingMode; got != mpfr.RoundDown {
+		t.Errorf("NewFloat().RoundingMode after SetDefaultRound(RoundDown) = %v; want RoundDown", got)
+	}
+}
+
 func TestSetFloat64GetFloat64(t *testing.T) {
 	f := mpfr.NewFloat()
 
@@ -89,6 +108,298 @@ func TestSetString(t *testing.T) {
 	}
 }
 
+func TestSetStringRound(t *testing.T) {
+	// With 2 bits of precision the neighbours of 1.25 are 1.0 and 1.5.
+	tests := []struct {
+		rnd  mpfr.Rnd
+		want float64
+	}{
+		{mpfr.RoundUp, 1.5},
+		{mpfr.RoundDown, 1.0},
+		{mpfr.RoundToNearest, 1.0},
+	}
+
+	for _, tt := range tests {
+		f := mpfr.NewFloatWithPrec(2)
+		if err := f.SetStringRound("1.25", 10, tt.rnd); err != nil {
+			t.Fatalf("SetStringRound(\"1.25\", %v) returned error: %v", tt.rnd, err)
+		}
+		if got := f.GetFloat64(); got != tt.want {
+			t.Errorf("SetStringRound(\"1.25\", %v) = %v; want %v", tt.rnd, got, tt.want)
+		}
+		if f.RoundingMode != mpfr.RoundToNearest {
+			t.Errorf("SetStringRound changed RoundingMode to %v", f.RoundingMode)
+		}
+	}
+
+	if err := mpfr.NewFloat().SetStringRound("1.2.5", 10, mpfr.RoundUp); err == nil {
+		t.Error("SetStringRound(\"1.2.5\") = nil error; want non-nil error")
+	}
+}
+
+func TestParseFloats(t *testing.T) {
+	fs, err := mpfr.ParseFloats([]string{"1.5", "-2", "0.1", "1e10"}, 10, 200, mpfr.RoundToNearest)
+	if err != nil {
+		t.Fatalf("ParseFloats returned error: %v", err)
+	}
+	want := []float64{1.5, -2, 0.1, 1e10}
+	for i, f := range fs {
+		if got := f.GetFloat64(); got != want[i] {
+			t.Errorf("ParseFloats entry %d = %v; want %v", i, got, want[i])
+		}
+		if got := f.GetPrec(); got != 200 {
+			t.Errorf("ParseFloats entry %d precision = %v; want 200", i, got)
+		}
+	}
+
+	_, err = mpfr.ParseFloats([]string{"1", "2", "oops", "4"}, 10, 64, mpfr.RoundToNearest)
+	if err == nil {
+		t.Fatal("ParseFloats with a malformed entry = nil error; want non-nil error")
+	}
+	if !strings.Contains(err.Error(), "entry 2") {
+		t.Errorf("ParseFloats error %q does not mention index 2", err)
+	}
+	if !errors.Is(err, mpfr.ErrInvalidString) {
+		t.Errorf("ParseFloats error %q does not wrap ErrInvalidString", err)
+	}
+}
+
+func TestReadFloats(t *testing.T) {
+	input := "1.5 -2\n\t0.1   6.02214076e23\n\n-1.25E-3\r\n  3\n"
+	fs, err := mpfr.ReadFloats(strings.NewReader(input), 10, 200, mpfr.RoundToNearest)
+	if err != nil {
+		t.Fatalf("ReadFloats returned error: %v", err)
+	}
+	want := []string{"1.5", "-2", "0.1", "6.02214076e23", "-1.25E-3", "3"}
+	if len(fs) != len(want) {
+		t.Fatalf("ReadFloats returned %d values; want %d", len(fs), len(want))
+	}
+	for i, f := range fs {
+		w := mpfr.MustParse(want[i], 200)
+		if f.Cmp(w) != 0 || f.GetPrec() != 200 {
+			t.Errorf("ReadFloats value %d = %v (prec %d); want %v at 200 bits", i, f, f.GetPrec(), w)
+		}
+	}
+
+	if fs, err := mpfr.ReadFloats(strings.NewReader(" \n"), 10, 64, mpfr.RoundToNearest); err != nil || len(fs) != 0 {
+		t.Errorf("ReadFloats(blank) = %v, %v; want no values and no error", fs, err)
+	}
+
+	_, err = mpfr.ReadFloats(strings.NewReader("1 2\n3 x4 5"), 10, 64, mpfr.RoundToNearest)
+	if err == nil || !errors.Is(err, mpfr.ErrInvalidString) {
+		t.Fatalf("ReadFloats with a malformed token = %v; want ErrInvalidString", err)
+	}
+	if !strings.Contains(err.Error(), "token 3") || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("ReadFloats error %q does not locate token 3 on line 2", err)
+	}
+
+	readErr := errors.New("disk on fire")
+	if _, err := mpfr.ReadFloats(&failingReader{"1 2 ", readErr}, 10, 64, mpfr.RoundToNearest); err != readErr {
+		t.Errorf("ReadFloats read error = %v; want %v", err, readErr)
+	}
+}
+
+// failingReader yields data and then err instead of io.EOF.
+type failingReader struct {
+	data string
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestErrorKinds(t *testing.T) {
+	kindOf := func(err error) mpfr.ErrorKind {
+		t.Helper()
+		var me mpfr.MPFRError
+		if !errors.As(err, &me) {
+			t.Fatalf("error %v does not implement MPFRError", err)
+		}
+		return me.Kind()
+	}
+
+	f := mpfr.NewFloat()
+	if err := f.SqrtChecked(mpfr.FromInt(-4)); !errors.Is(err, mpfr.ErrDomain) || kindOf(err) != mpfr.ErrKindDomain || !f.IsNaN() {
+		t.Errorf("SqrtChecked(-4) = %v, f = %v; want ErrDomain and NaN", err, f)
+	}
+	if err := f.SqrtChecked(mpfr.FromInt(9)); err != nil || f.GetFloat64() != 3 {
+		t.Errorf("SqrtChecked(9) = %v, f = %v; want nil and 3", err, f)
+	}
+	if err := f.SqrtChecked(mpfr.FromFloat64(math.NaN())); err != nil {
+		t.Errorf("SqrtChecked(NaN) = %v; want nil, as NaN propagates", err)
+	}
+
+	if err := f.QuoErr(mpfr.FromInt(1), mpfr.FromInt(0)); !errors.Is(err, mpfr.ErrDomain) {
+		t.Errorf("QuoErr(1, 0) = %v; want ErrDomain", err)
+	}
+	if err := f.QuoErr(mpfr.FromInt(1), mpfr.FromInt(8)); err != nil || f.GetFloat64() != 0.125 {
+		t.Errorf("QuoErr(1, 8) = %v, f = %v; want nil and 0.125", err, f)
+	}
+	huge := mpfr.Pow2(mpfr.GetEmax()-1, 53)
+	err := f.QuoErr(huge, mpfr.FromFloat64(0.25))
+	if !errors.Is(err, mpfr.ErrRange) || kindOf(err) != mpfr.ErrKindRange {
+		t.Errorf("QuoErr(huge, 0.25) = %v; want ErrRange", err)
+	}
+
+	if err := f.RootN(mpfr.FromInt(-27), 3); err != nil || f.GetFloat64() != -3 {
+		t.Errorf("RootN(-27, 3) = %v, f = %v; want nil and -3", err, f)
+	}
+	if err := f.RootN(mpfr.FromInt(16), -4); err != nil || f.GetFloat64() != 0.5 {
+		t.Errorf("RootN(16, -4) = %v, f = %v; want nil and 0.5", err, f)
+	}
+	for _, n := range []int{0, 2} {
+		if err := f.RootN(mpfr.FromInt(-16), n); !errors.Is(err, mpfr.ErrDomain) {
+			t.Errorf("RootN(-16, %d) = %v; want ErrDomain", n, err)
+		}
+	}
+
+	_, err = mpfr.ParseFloats([]string{"1", "x"}, 10, 53, mpfr.RoundToNearest)
+	if !errors.Is(err, mpfr.ErrInvalidString) || kindOf(err) != mpfr.ErrKindParse {
+		t.Errorf("ParseFloats error %v does not wrap a parse error", err)
+	}
+	if errors.Is(err, mpfr.ErrDomain) {
+		t.Errorf("parse error %v matches ErrDomain", err)
+	}
+
+	// FloatError keeps its single exported field, so callers' own literals still
+	// compile; they are classified as ErrKindOther.
+	if k := (&mpfr.FloatError{Msg: "custom"}).Kind(); k != mpfr.ErrKindOther {
+		t.Errorf("Kind of a caller-built FloatError = %v; want ErrKindOther", k)
+	}
+	if k := mpfr.ErrInvalidBase.Kind(); k != mpfr.ErrKindDomain {
+		t.Errorf("ErrInvalidBase.Kind() = %v; want ErrKindDomain", k)
+	}
+}
+
+func TestPowRational(t *testing.T) {
+	tests := []struct {
+		x    float64
+		p    int
+		q    uint
+		want float64
+	}{
+		{8, 2, 3, 4},
+		{-8, 1, 3, -2},
+		{-8, 2, 3, 4},
+		{-8, 2, 6, -2},
+		{16, -3, 4, 0.125},
+		{-32, -1, 5, -0.5},
+		{5, 0, 7, 1},
+		{2, 3, 1, 8},
+	}
+	for _, tt := range tests {
+		got, err := mpfr.NewFloat().PowRational(mpfr.FromFloat64(tt.x), tt.p, tt.q)
+		if err != nil || got.GetFloat64() != tt.want {
+			t.Errorf("PowRational(%v, %d, %d) = %v, %v; want %v", tt.x, tt.p, tt.q, got.GetFloat64(), err, tt.want)
+		}
+	}
+	if pow := mpfr.NewFloat().Pow(mpfr.FromInt(-8), mpfr.NewFloat().Quo(mpfr.FromInt(1), mpfr.FromInt(3))); !pow.IsNaN() {
+		t.Errorf("Pow(-8, 1/3) = %v; PowRational is only needed because this is NaN", pow.GetFloat64())
+	}
+
+	// 2^(1/3) is correctly rounded: it matches Cbrt in every rounding mode.
+	for _, rnd := range []mpfr.Rnd{mpfr.RoundToNearest, mpfr.RoundUp, mpfr.RoundDown} {
+		f := mpfr.NewFloatWithPrec(200)
+		f.SetRoundMode(rnd)
+		want := mpfr.NewFloatWithPrec(200)
+		want.SetRoundMode(rnd)
+		want.Cbrt(mpfr.FromInt(2))
+		if _, err := f.PowRational(mpfr.FromInt(2), 1, 3); err != nil || f.Cmp(want) != 0 {
+			t.Errorf("PowRational(2, 1, 3) in mode %v differs from Cbrt(2)", rnd)
+		}
+	}
+
+	// A power too large to form exactly still gives an accurate result.
+	x := mpfr.NewFloat().SetFloat64(1.1)
+	large, err := mpfr.NewFloat().PowRational(x, 3001, 7)
+	if err != nil || !almostEqual(large.GetFloat64()/math.Pow(1.1, 3001.0/7), 1) {
+		t.Errorf("PowRational(1.1, 3001, 7) = %v, %v; want %v", large.GetFloat64(), err, math.Pow(1.1, 3001.0/7))
+	}
+
+	for _, bad := range []struct {
+		x float64
+		p int
+		q uint
+	}{{-8, 1, 2}, {-8, 3, 6}, {4, 1, 0}} {
+		f, err := mpfr.NewFloat().PowRational(mpfr.FromFloat64(bad.x), bad.p, bad.q)
+		if !errors.Is(err, mpfr.ErrDomain) || !f.IsNaN() {
+			t.Errorf("PowRational(%v, %d, %d) = %v, %v; want NaN, ErrDomain", bad.x, bad.p, bad.q, f.GetFloat64(), err)
+		}
+	}
+
+	// A negative power of zero is a pole: ±Inf with ErrDomain, like QuoErr.
+	for _, pole := range []struct {
+		zero float64
+		p    int
+		q    uint
+		want float64
+	}{{0, -1, 3, math.Inf(1)}, {math.Copysign(0, -1), -1, 3, math.Inf(-1)}, {math.Copysign(0, -1), -2, 3, math.Inf(1)}} {
+		f, err := mpfr.NewFloat().PowRational(mpfr.FromFloat64(pole.zero), pole.p, pole.q)
+		if !errors.Is(err, mpfr.ErrDomain) || f.GetFloat64() != pole.want {
+			t.Errorf("PowRational(%v, %d, %d) = %v, %v; want %v, ErrDomain", pole.zero, pole.p, pole.q, f.GetFloat64(), err, pole.want)
+		}
+	}
+	if f, err := mpfr.NewFloat().PowRational(mpfr.FromInt(0), 1, 3); err != nil || !f.IsZero() {
+		t.Errorf("PowRational(0, 1, 3) = %v, %v; want 0, nil", f.GetFloat64(), err)
+	}
+}
+
+func TestInverseHyperbolicChecked(t *testing.T) {
+	f := mpfr.NewFloat()
+	for _, v := range []float64{1, -1, 1.5, -2, math.Inf(1)} {
+		if err := f.AtanhChecked(mpfr.FromFloat64(v)); !errors.Is(err, mpfr.ErrDomain) || !f.IsNaN() {
+			t.Errorf("AtanhChecked(%v) = %v, f = %v; want ErrDomain and NaN", v, err, f.GetFloat64())
+		}
+	}
+	if err := f.AtanhChecked(mpfr.FromFloat64(0.5)); err != nil || !almostEqual(f.GetFloat64(), math.Atanh(0.5)) {
+		t.Errorf("AtanhChecked(0.5) = %v, f = %v; want nil and %v", err, f.GetFloat64(), math.Atanh(0.5))
+	}
+
+	for _, v := range []float64{0.5, -1, math.Inf(-1)} {
+		if err := f.AcoshChecked(mpfr.FromFloat64(v)); !errors.Is(err, mpfr.ErrDomain) || !f.IsNaN() {
+			t.Errorf("AcoshChecked(%v) = %v, f = %v; want ErrDomain and NaN", v, err, f.GetFloat64())
+		}
+	}
+	if err := f.AcoshChecked(mpfr.FromInt(1)); err != nil || f.Sign() != 0 {
+		t.Errorf("AcoshChecked(1) = %v, f = %v; want nil and 0", err, f.GetFloat64())
+	}
+	if err := f.AcoshChecked(mpfr.FromInt(2)); err != nil || !almostEqual(f.GetFloat64(), math.Acosh(2)) {
+		t.Errorf("AcoshChecked(2) = %v, f = %v; want nil and %v", err, f.GetFloat64(), math.Acosh(2))
+	}
+	if err := f.AcoshChecked(mpfr.FromFloat64(math.NaN())); err != nil {
+		t.Errorf("AcoshChecked(NaN) = %v; want nil, as NaN propagates", err)
+	}
+}
+
+func TestParseMustParse(t *testing.T) {
+	const pi100 = "3.141592653589793238462643383279502884197169399375105820974944592307816406286208998628034825342117068"
+
+	pi := mpfr.MustParse(pi100, 350)
+	if got := pi.GetPrec(); got != 350 {
+		t.Errorf("MustParse precision = %v; want 350", got)
+	}
+	if got := pi.StringN(100); got != pi100 {
+		t.Errorf("MustParse(pi100).StringN(100) = %s; want %s", got, pi100)
+	}
+
+	if _, err := mpfr.Parse("3.14.15", 64); err == nil {
+		t.Error("Parse(\"3.14.15\") = nil error; want non-nil error")
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("MustParse(\"garbage\") did not panic")
+		}
+	}()
+	mpfr.MustParse("garbage", 64)
+}
+
 func TestStringMethod(t *testing.T) {
 	f := mpfr.NewFloat()
 	f.SetFloat64(1.2345)
@@ -99,6 +410,222 @@ func TestStringMethod(t *testing.T) {
 	}
 }
 
+func TestCompactString(t *testing.T) {
+	negZero := mpfr.NewFloat().SetZero(-1)
+	tests := []struct {
+		x    *mpfr.Float
+		want string
+	}{
+		{mpfr.FromFloat64(1e-7), "1e-07"},
+		{mpfr.FromFloat64(1e20), "1e+20"},
+		{mpfr.FromFloat64(-0.5), "-0.5"},
+		{mpfr.FromInt(0), "0"},
+		{negZero, "-0"},
+		{mpfr.FromFloat64(math.Pi), "3.141592653589793"},
+		{mpfr.FromFloat64(0.1), "0.1"},
+		{mpfr.FromFloat64(123456), "123456"},
+		{mpfr.FromFloat64(math.Inf(-1)), "-Inf"},
+		{mpfr.FromFloat64(math.NaN()), "NaN"},
+		{mpfr.Pi(100, mpfr.RoundToNearest), "3.14159265358979323846264338328"},
+	}
+	for _, tc := range tests {
+		if got := tc.x.CompactString(); got != tc.want {
+			t.Errorf("CompactString(%v) = %q; want %q", tc.x, got, tc.want)
+		}
+	}
+
+	// At 53 bits the layout and the shortest digits agree with strconv across the
+	// float64 normal range; below it float64 loses precision that the Float keeps.
+	for _, v := range []float64{1.5e6, 1e-5, 1e-4, 100000, 2.5e-300, 2.2250738585072014e-308, math.MaxFloat64, 1.0 / 3, -123.456, 9007199254740993} {
+		if got, want := mpfr.FromFloat64(v).CompactString(), strconv.FormatFloat(v, 'g', -1, 64); got != want {
+			t.Errorf("CompactString(%v) = %q; want %q", v, got, want)
+		}
+	}
+
+	// Higher precision values must still round-trip.
+	third := mpfr.NewFloatWithPrec(200).Quo(mpfr.FromInt(1), mpfr.FromInt(3))
+	back := mpfr.MustParse(third.CompactString(), 200)
+	if back.Cmp(third) != 0 {
+		t.Errorf("CompactString(1/3 at 200 bits) = %q does not round-trip", third.CompactString())
+	}
+}
+
+func TestCachedString(t *testing.T) {
+	f := mpfr.NewFloatWithPrec(80).SetFloat64(1.25)
+	first := f.CachedString()
+	if want := f.String(); first != want {
+		t.Fatalf("CachedString() = %q; want %q", first, want)
+	}
+	if again := f.CachedString(); again != first {
+		t.Errorf("second CachedString() = %q; want %q", again, first)
+	}
+
+	// Every kind of change invalidates the cache, whatever method made it.
+	changes := []struct {
+		name   string
+		mutate func()
+	}{
+		{"Add", func() { f.Add(mpfr.FromInt(1)) }},
+		{"Neg", func() { f.Neg(f) }},
+		{"SetPrec", func() { f.SetPrec(24) }},
+		{"RoundingMode", func() { f.SetRoundMode(mpfr.RoundUp) }},
+		{"SetZero", func() { f.SetZero(1) }},
+		{"negative zero", func() { f.Neg(f) }},
+		{"SetFloat64", func() { f.SetFloat64(math.NaN()) }},
+		{"SetString", func() { _ = f.SetString("2.5", 10) }},
+		{"Sqrt", func() { f.Sqrt() }},
+		{"SinDeg", func() { f.SinDeg(mpfr.FromInt(30)) }},
+		{"TanReduced", func() { f.TanReduced(mpfr.FromInt(1), 64) }},
+		{"Swap", func() { f.Swap(mpfr.FromInt(7)) }},
+		{"Swap argument", func() { mpfr.FromInt(8).Swap(f) }},
+		{"ClampMonotone", func() { mpfr.ClampMonotone([]*mpfr.Float{mpfr.FromInt(100), f}, true) }},
+		{"ExpSlice", func() { mpfr.ExpSlice([]*mpfr.Float{f}, []*mpfr.Float{mpfr.FromInt(1)}, mpfr.RoundToNearest) }},
+	}
+	for _, c := range changes {
+		before := f.CachedString()
+		if again := f.CachedString(); again != before {
+			t.Fatalf("before %s: CachedString() changed from %q to %q without a mutation", c.name, before, again)
+		}
+		c.mutate()
+		got, want := f.CachedString(), f.String()
+		if got != want {
+			t.Errorf("after %s: CachedString() = %q; want %q", c.name, got, want)
+		}
+		if c.name != "RoundingMode" && got == before {
+			t.Errorf("after %s: CachedString() still %q", c.name, got)
+		}
+	}
+}
+
+// TestStringNegativeAndSpecial checks that String keeps its historical output for
+// negative and special values, while StringN lays them out properly. Non-negative
+// finite values look the same in both.
+func TestStringNegativeAndSpecial(t *testing.T) {
+	tests := []struct {
+		x            float64
+		str, stringN string
+	}{
+		{-3.25, "-.32500000000000000", "-3.2500000000000000"},
+		{-0.5, ".-50000000000000000", "-.50000000000000000"},
+		{-1e-3, "0.00-10000000000000000", "-0.0010000000000000000"},
+		{-1e20, "-10000000000000000000.0", "-100000000000000000000.0"},
+		{math.NaN(), ".@NaN@", "NaN"},
+		{math.Inf(1), ".@Inf@", "+Inf"},
+		{math.Inf(-1), ".-@Inf@", "-Inf"},
+		{3.25, "3.2500000000000000", "3.2500000000000000"},
+		{0.5, ".50000000000000000", ".50000000000000000"},
+		{1e-3, "0.0010000000000000000", "0.0010000000000000000"},
+		{1e20, "100000000000000000000.0", "100000000000000000000.0"},
+	}
+	for _, tt := range tests {
+		x := mpfr.FromFloat64(tt.x)
+		if got := x.String(); got != tt.str {
+			t.Errorf("String(%v) = %q; want %q", tt.x, got, tt.str)
+		}
+		if got := x.StringN(0); got != tt.stringN {
+			t.Errorf("StringN(%v, 0) = %q; want %q", tt.x, got, tt.stringN)
+		}
+	}
+}
+
+func TestStringN(t *testing.T) {
+	pi := mpfr.NewFloatWithPrec(1000)
+	pi.Acos(mpfr.FromInt(-1))
+
+	if got, want := pi.StringN(10), "3.141592654"; got != want {
+		t.Errorf("StringN(10) of pi = %q; want %q", got, want)
+	}
+	if got := pi.String(); len(got) < 300 || !strings.HasPrefix(got, "3.14159265358979323846") {
+		t.Errorf("String() of 1000-bit pi = %q; want the full expansion", got)
+	}
+	if got, want := pi.StringN(0), pi.String(); got != want {
+		t.Errorf("StringN(0) = %q; want String() = %q", got, want)
+	}
+
+	x := mpfr.FromFloat64(3.5)
+	if got, want := x.String(), "3.5000000000000000"; got != want {
+		t.Errorf("String() of 3.5 = %q; want %q", got, want)
+	}
+	if got, want := mpfr.FromFloat64(-1.5).StringN(3), "-1.50"; got != want {
+		t.Errorf("StringN(3) of -1.5 = %q; want %q", got, want)
+	}
+
+	// When every digit falls before the point, including after rounding carries
+	// into a new digit, the output ends in ".0" rather than a bare ".".
+	for _, tt := range []struct {
+		x      *mpfr.Float
+		digits int
+		want   string
+	}{
+		{pi, 1, "3.0"},
+		{mpfr.FromFloat64(9.99), 2, "10.0"},
+		{mpfr.FromFloat64(-9.99), 2, "-10.0"},
+		{mpfr.FromFloat64(99.5), 2, "100.0"},
+		{mpfr.FromFloat64(9.96), 2, "10.0"},
+		{mpfr.FromInt(42), 2, "42.0"},
+	} {
+		if got := tt.x.StringN(tt.digits); got != tt.want {
+			t.Errorf("StringN(%v, %d) = %q; want %q", tt.x.GetFloat64(), tt.digits, got, tt.want)
+		}
+	}
+}
+
+// failingWriter accepts n bytes and then fails.
+type failingWriter struct{ n int }
+
+var errWriterFull = errors.New("writer full")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		k := w.n
+		w.n = 0
+		return k, errWriterFull
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestDigitStream(t *testing.T) {
+	// 33216 bits is enough for 10000 significant decimal digits.
+	pi := mpfr.Pi(33216, mpfr.RoundToNearest)
+	var buf strings.Builder
+	if err := pi.DigitStream(10, &buf); err != nil {
+		t.Fatalf("DigitStream returned error: %v", err)
+	}
+	want := pi.StringN(0)
+	if buf.String() != want {
+		t.Errorf("DigitStream output differs from StringN(0): %d vs %d bytes", buf.Len(), len(want))
+	}
+	if digits := len(want) - len("3."); digits < 10000 {
+		t.Errorf("only %d digits streamed; want at least 10000", digits)
+	}
+
+	for _, v := range []string{"-1234.5", "1e-30", "123456789e20"} {
+		x := mpfr.MustParse(v, 64)
+		buf.Reset()
+		if err := x.DigitStream(10, &buf); err != nil || buf.String() != x.StringN(0) {
+			t.Errorf("DigitStream(%s) = %q, %v; want %q", v, buf.String(), err, x.StringN(0))
+		}
+	}
+
+	// A 17-digit integer at 53 bits fills every digit before the point.
+	buf.Reset()
+	if err := mpfr.FromFloat64(12345678901234568).DigitStream(10, &buf); err != nil || buf.String() != "12345678901234568.0" {
+		t.Errorf("DigitStream(12345678901234568) = %q, %v; want %q", buf.String(), err, "12345678901234568.0")
+	}
+
+	buf.Reset()
+	if err := mpfr.FromInt(255).DigitStream(16, &buf); err != nil || !strings.HasPrefix(buf.String(), "ff.") {
+		t.Errorf("DigitStream(255, base 16) = %q, %v; want ff.…", buf.String(), err)
+	}
+	if err := pi.DigitStream(63, &buf); !errors.Is(err, mpfr.ErrInvalidBase) {
+		t.Errorf("DigitStream(base 63) error = %v; want ErrInvalidBase", err)
+	}
+	if err := pi.DigitStream(10, &failingWriter{n: 100}); !errors.Is(err, errWriterFull) {
+		t.Errorf("DigitStream to a failing writer returned %v; want %v", err, errWriterFull)
+	}
+}
+
 func TestAdd(t *testing.T) {
 	x := mpfr.NewFloat().SetFloat64(1.5)
 	y := mpfr.NewFloat().SetFloat64(2.25)
@@ -111,6 +638,26 @@ func TestAdd(t *testing.T) {
 	}
 }
 
+func TestAddAll(t *testing.T) {
+	xs := make([]*mpfr.Float, 1000)
+	for i := range xs {
+		xs[i] = mpfr.NewFloat().SetFloat64(1 / float64(i+1))
+	}
+	want := mpfr.NewFloat().SetFloat64(0.5).Add(xs...)
+	got := mpfr.NewFloat().SetFloat64(0.5).AddAll(xs)
+	if got.Cmp(want) != 0 {
+		t.Errorf("AddAll = %v; want %v (variadic Add)", got, want)
+	}
+
+	f := mpfr.FromInt(7)
+	if f.AddAll(nil).GetFloat64() != 7 {
+		t.Errorf("AddAll(nil) changed the receiver to %v", f)
+	}
+	if f.AddAll([]*mpfr.Float{f, mpfr.FromInt(1), f}).GetFloat64() != 30 {
+		t.Errorf("AddAll with the receiver as an operand = %v; want 30", f)
+	}
+}
+
 func TestSub(t *testing.T) {
 	x := mpfr.NewFloat().SetFloat64(5.0)
 	y := mpfr.NewFloat().SetFloat64(3.1)
@@ -135,6 +682,33 @@ func TestSub(t *testing.T) {
 	}
 }
 
+func TestSubWithCancellation(t *testing.T) {
+	// 1 + 2^-40 and 1 agree in their leading 40 bits.
+	x := mpfr.NewFloatWithPrec(128).SetFloat64(1 + math.Ldexp(1, -40))
+	y := mpfr.NewFloatWithPrec(128).SetFloat64(1)
+	f := mpfr.NewFloatWithPrec(128)
+	got, lost := f.SubWithCancellation(x, y)
+	if got != f || got.GetFloat64() != math.Ldexp(1, -40) {
+		t.Errorf("SubWithCancellation result = %v; want 2^-40 in f", got)
+	}
+	if lost != 40 {
+		t.Errorf("lostBits for nearly equal operands = %d; want 40", lost)
+	}
+
+	if _, lost := f.SubWithCancellation(mpfr.FromInt(1000), mpfr.FromFloat64(0.001)); lost != 0 {
+		t.Errorf("lostBits for dissimilar magnitudes = %d; want 0", lost)
+	}
+	if _, lost := f.SubWithCancellation(mpfr.FromInt(5), mpfr.FromInt(-5)); lost != 0 {
+		t.Errorf("lostBits for opposite signs = %d; want 0", lost)
+	}
+	if _, lost := f.SubWithCancellation(y, y); lost != 128 {
+		t.Errorf("lostBits for total cancellation = %d; want 128", lost)
+	}
+	if _, lost := f.SubWithCancellation(mpfr.FromFloat64(math.Inf(1)), y); lost != 0 {
+		t.Errorf("lostBits with an infinite operand = %d; want 0", lost)
+	}
+}
+
 func TestMul(t *testing.T) {
 	x := mpfr.NewFloat().SetFloat64(2.0)
 	y := mpfr.NewFloat().SetFloat64(3.25)
@@ -169,6 +743,29 @@ func TestMul(t *testing.T) {
 	}
 }
 
+func TestMulAll(t *testing.T) {
+	xs := make([]*mpfr.Float, 1000)
+	for i := range xs {
+		xs[i] = mpfr.NewFloat().SetFloat64(1 + 1/float64(i+1))
+	}
+	for _, rnd := range []mpfr.Rnd{mpfr.RoundToNearest, mpfr.RoundDown, mpfr.RoundUp} {
+		want := mpfr.NewFloat().SetFloat64(0.5)
+		want.SetRoundMode(rnd)
+		want.Mul(xs...)
+		got := mpfr.NewFloat().SetFloat64(0.5)
+		got.SetRoundMode(rnd)
+		got.MulAll(xs)
+		if got.Cmp(want) != 0 {
+			t.Errorf("MulAll with %v = %v; want %v (variadic Mul)", rnd, got, want)
+		}
+	}
+
+	f := mpfr.FromInt(3)
+	if f.MulAll([]*mpfr.Float{f, mpfr.FromInt(2)}).GetFloat64() != 18 {
+		t.Errorf("MulAll with the receiver as an operand = %v; want 18", f)
+	}
+}
+
 func TestDiv(t *testing.T) {
 	x := mpfr.NewFloat().SetFloat64(10.0)
 	y := mpfr.NewFloat().SetFloat64(4.0)
@@ -191,54 +788,303 @@ func TestDiv(t *testing.T) {
 	}
 }
 
-func TestQuo(t *testing.T) {
-	tests := []struct {
-		x, y        float64
-		rnd         mpfr.Rnd
-		expected    string
-		shouldPanic bool
-	}{
-		{10, 2, mpfr.RoundToNearest, "5", false},
-		{10, 3, mpfr.RoundToNearest, "3.333333333333333", false},
-		{10, 3, mpfr.RoundToward0, "3.333333333333333", false},
-		{10, 0, mpfr.RoundToNearest, "", true},
-		{-10, 0, mpfr.RoundToNearest, "", true},
-	}
+func TestHalfDouble(t *testing.T) {
+	two := mpfr.FromInt(2)
+	for _, v := range []string{"1", "-3", "0.1", "12345678901234567890.125", "1e-300", "0"} {
+		x := mpfr.NewFloatWithPrec(200)
+		if err := x.SetString(v, 10); err != nil {
+			t.Fatalf("SetString(%q) returned error: %v", v, err)
+		}
+		orig := mpfr.NewFloatWithPrec(200).Copy(x)
 
-	for _, tt := range tests {
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					if !tt.shouldPanic {
-						t.Errorf("Quo(%v, %v) unexpectedly panicked: %v", tt.x, tt.y, r)
-					}
-				} else if tt.shouldPanic {
-					t.Errorf("Quo(%v, %v) did not panic as expected", tt.x, tt.y)
-				}
-			}()
+		half := mpfr.NewFloatWithPrec(200).Copy(x).Half()
+		quo := mpfr.NewFloatWithPrec(200).Copy(x).Div(two)
+		if half.Cmp(quo) != 0 {
+			t.Errorf("Half(%s) = %v; want %v", v, half, quo)
+		}
 
-			x := mpfr.FromFloat64(tt.x)
-			y := mpfr.FromFloat64(tt.y)
-			result := mpfr.NewFloat()
+		if half.Double().Cmp(orig) != 0 {
+			t.Errorf("Double(Half(%s)) = %v; want %v", v, half, orig)
+		}
+	}
+}
 
-			if !tt.shouldPanic {
-				result.Quo(x, y)
-				got := result.Float64()
-				expected, _ := strconv.ParseFloat(tt.expected, 64)
-				closeEnough := almostEqual(got, expected)
-				if !closeEnough {
-					t.Errorf("Quo(%v, %v) got %v; want %v", tt.x, tt.y, got, tt.expected)
-				}
-			} else {
-				_ = result.Quo(x, y) // Expect a panic
-			}
-		}()
+func TestPow2(t *testing.T) {
+	for _, prec := range []uint{1, 2, 53, 1000} {
+		if got := mpfr.Pow2(-10, prec); got.GetFloat64() != 1.0/1024 || got.GetPrec() != prec {
+			t.Errorf("Pow2(-10, %d) = %v at %d bits; want 1/1024", prec, got, got.GetPrec())
+		}
+		want := mpfr.NewFloatWithPrec(prec)
+		if err := want.SetString("1267650600228229401496703205376", 10); err != nil {
+			t.Fatal(err)
+		}
+		if got := mpfr.Pow2(100, prec); got.Cmp(want) != 0 {
+			t.Errorf("Pow2(100, %d) = %v; want 2^100", prec, got)
+		}
+	}
+	if got := mpfr.NewFloat().SetPow2(0).GetFloat64(); got != 1 {
+		t.Errorf("SetPow2(0) = %v; want 1", got)
+	}
+	if got := mpfr.NewFloat().SetPow2(mpfr.GetEmax()); !got.IsInf() {
+		t.Error("SetPow2(emax) did not overflow")
 	}
 }
 
-func TestCmp(t *testing.T) {
-	a := mpfr.NewFloat().SetFloat64(2.0)
-	b := mpfr.NewFloat().SetFloat64(2.0)
+func TestScalbnSafeMul(t *testing.T) {
+	if got := mpfr.NewFloat().Scalbn(mpfr.FromFloat64(1.5), 4).GetFloat64(); got != 24 {
+		t.Errorf("Scalbn(1.5, 4) = %v; want 24", got)
+	}
+	if got := mpfr.NewFloat().Scalbn(mpfr.FromFloat64(-3), -2).GetFloat64(); got != -0.75 {
+		t.Errorf("Scalbn(-3, -2) = %v; want -0.75", got)
+	}
+
+	emax, emin := mpfr.GetEmax(), mpfr.GetEmin()
+	huge := mpfr.NewFloat().Scalbn(mpfr.FromFloat64(1.5), emax-10)
+	tiny := mpfr.NewFloat().Scalbn(mpfr.FromFloat64(1.25), emin+10)
+
+	// huge² overflows, but its mantissa and scale are still available.
+	if sq := mpfr.NewFloat().Copy(huge).Mul(huge); !sq.IsInf() {
+		t.Fatal("direct huge² did not overflow")
+	}
+	m, scale := mpfr.SafeMul(huge, huge, mpfr.RoundToNearest)
+	if m.GetFloat64() != 0.5625 || scale != 2*(emax-9) {
+		t.Errorf("SafeMul(huge, huge) = %v × 2^%d; want 0.5625 × 2^%d", m, scale, 2*(emax-9))
+	}
+
+	// huge·tiny is representable, and rescaling gives the true product.
+	m, scale = mpfr.SafeMul(huge, tiny, mpfr.RoundToNearest)
+	got := mpfr.NewFloat().Scalbn(m, scale)
+	want := mpfr.NewFloat().Copy(huge).Mul(tiny)
+	if got.Cmp(want) != 0 || got.GetFloat64() != 1.875 {
+		t.Errorf("Scalbn(SafeMul(huge, tiny)) = %g; want %g", got.GetFloat64(), want.GetFloat64())
+	}
+
+	if m, scale := mpfr.SafeMul(mpfr.FromInt(0), huge, mpfr.RoundToNearest); !m.IsZero() || scale != 0 {
+		t.Errorf("SafeMul(0, huge) = %g × 2^%d; want 0 × 2^0", m.GetFloat64(), scale)
+	}
+}
+
+func TestModfTyped(t *testing.T) {
+	tests := []struct {
+		in, wantInt float64
+	}{
+		{3.7, 3},
+		{-2.8, -2},
+	}
+	for _, tt := range tests {
+		// The subtraction is exact at run time, unlike the untyped constant 3.7 - 3.
+		wantFrac := tt.in - tt.wantInt
+		check := func(name string, i, fr *mpfr.Float) {
+			t.Helper()
+			if i.GetFloat64() != tt.wantInt || fr.GetFloat64() != wantFrac {
+				t.Errorf("%s(%v) = (%v, %v); want (%v, %v)", name, tt.in, i, fr, tt.wantInt, wantFrac)
+			}
+			if i.Signbit() != (tt.in < 0) || fr.Signbit() != (tt.in < 0) {
+				t.Errorf("%s(%v) parts have signs (%v, %v); want both to follow the input", name, tt.in, i.Signbit(), fr.Signbit())
+			}
+		}
+
+		x := mpfr.NewFloat().SetFloat64(tt.in)
+		i, fr := x.ModfSelf()
+		check("ModfSelf", i, fr)
+		if x.GetFloat64() != tt.in {
+			t.Errorf("ModfSelf modified its receiver: %v", x)
+		}
+		i, fr = mpfr.NewFloat().ModfOf(x)
+		check("ModfOf", i, fr)
+		i, fr = mpfr.ModfRnd(x, mpfr.RoundToNearest)
+		check("ModfRnd", i, fr)
+	}
+
+	x := mpfr.NewFloatWithPrec(200)
+	if err := x.SetString("12345678901234567890.0000000001", 10); err != nil {
+		t.Fatal(err)
+	}
+	i, fr := x.ModfSelf()
+	if i.GetPrec() != 200 || fr.GetPrec() != 200 {
+		t.Errorf("ModfSelf parts have precision (%d, %d); want 200", i.GetPrec(), fr.GetPrec())
+	}
+	if got := i.Add(fr); got.Cmp(x) != 0 {
+		t.Errorf("intPart + fracPart = %v; want %v", got, x)
+	}
+}
+
+func TestMulDivPow10(t *testing.T) {
+	if got := mpfr.NewFloat().SetFloat64(1.5).MulPow10(3); got.Float64() != 1500 {
+		t.Errorf("1.5.MulPow10(3) = %v; want 1500", got)
+	}
+
+	// At 53 bits the correctly rounded product must match IEEE double arithmetic.
+	tenth, thousand := 0.1, 1000.0
+	if got, want := mpfr.NewFloat().SetFloat64(tenth).MulPow10(3).Float64(), tenth*thousand; got != want {
+		t.Errorf("0.1.MulPow10(3) = %v; want %v", got, want)
+	}
+	if got := mpfr.NewFloat().SetFloat64(1).DivPow10(1).Float64(); got != 0.1 {
+		t.Errorf("1.DivPow10(1) = %v; want 0.1", got)
+	}
+	if got := mpfr.NewFloat().SetFloat64(5).MulPow10(-2).Float64(); got != 0.05 {
+		t.Errorf("5.MulPow10(-2) = %v; want 0.05", got)
+	}
+
+	// Exponents beyond the cache bound take the uncached path.
+	x := mpfr.NewFloatWithPrec(64).SetFloat64(3).MulPow10(2000).DivPow10(2000)
+	if x.Float64() != 3 {
+		t.Errorf("3.MulPow10(2000).DivPow10(2000) = %v; want 3", x)
+	}
+
+	// Cleared powers are recomputed on the next use.
+	mpfr.ClearPow10Cache()
+	if got := mpfr.NewFloat().SetFloat64(1.5).MulPow10(3); got.Float64() != 1500 {
+		t.Errorf("1.5.MulPow10(3) after ClearPow10Cache = %v; want 1500", got)
+	}
+}
+
+func TestRoundDecimal(t *testing.T) {
+	tests := []struct {
+		x      float64
+		places int
+		rnd    mpfr.Rnd
+		want   float64
+	}{
+		// The double nearest 2.345 lies just above it, so it is not a tie.
+		{2.345, 2, mpfr.RoundToNearest, 2.35},
+		{2.335, 2, mpfr.RoundToNearest, 2.33},
+		// 2.125 and 2.375 are exact binary ties, broken to even.
+		{2.125, 2, mpfr.RoundToNearest, 2.12},
+		{2.375, 2, mpfr.RoundToNearest, 2.38},
+		{2.341, 2, mpfr.RoundUp, 2.35},
+		{-2.349, 2, mpfr.RoundToward0, -2.34},
+		{-2.341, 2, mpfr.RoundAway, -2.35},
+		{2.5, 2, mpfr.RoundDown, 2.5},
+		{7.5, 0, mpfr.RoundToNearest, 8},
+		// Negative places round to tens, hundreds, ...
+		{1250, -2, mpfr.RoundToNearest, 1200},
+		{1350, -2, mpfr.RoundToNearest, 1400},
+		{1250.5, -2, mpfr.RoundToNearest, 1300},
+		{1201, -2, mpfr.RoundUp, 1300},
+		{-1250, -2, mpfr.RoundAway, -1300},
+		{-1299.5, -2, mpfr.RoundToward0, -1200},
+		{-1201, -2, mpfr.RoundDown, -1300},
+		{0.004, 2, mpfr.RoundToNearest, 0},
+	}
+	for _, tc := range tests {
+		got := mpfr.NewFloatWithPrec(53).SetFloat64(tc.x).RoundDecimal(tc.places, tc.rnd).GetFloat64()
+		if got != tc.want {
+			t.Errorf("RoundDecimal(%v, %d, %v) = %v; want %v", tc.x, tc.places, tc.rnd, got, tc.want)
+		}
+	}
+
+	// The result is the Float nearest the decimal value, as parsing would give.
+	x := mpfr.MustParse("3.14159265358979323846264338327950288", 200).RoundDecimal(20, mpfr.RoundToNearest)
+	if want := mpfr.MustParse("3.14159265358979323846", 200); x.Cmp(want) != 0 {
+		t.Errorf("π.RoundDecimal(20) = %v; want %v", x, want)
+	}
+	if got := mpfr.NewFloat().SetFloat64(-0.001).RoundDecimal(1, mpfr.RoundToNearest); !got.IsNegZero() {
+		t.Errorf("RoundDecimal(-0.001, 1) = %v; want -0", got)
+	}
+	if got := mpfr.NewFloat().SetFloat64(-4).RoundDecimal(-1, mpfr.RoundToNearest); !got.IsNegZero() {
+		t.Errorf("RoundDecimal(-4, -1) = %v; want -0", got)
+	}
+}
+
+func TestFixed(t *testing.T) {
+	f := mpfr.NewFloat().SetFixed(big.NewInt(12345), 2)
+	if got := f.GetFloat64(); got != 123.45 {
+		t.Errorf("SetFixed(12345, 2) = %v; want 123.45", got)
+	}
+	if got := mpfr.NewFloat().SetFixed(big.NewInt(-7), -3).GetFloat64(); got != -7000 {
+		t.Errorf("SetFixed(-7, -3) = %v; want -7000", got)
+	}
+	if got := mpfr.NewFloat().SetFixed(nil, 2); got.Sign() != 0 {
+		t.Errorf("SetFixed(nil, 2) = %v; want 0", got.GetFloat64())
+	}
+	// A value with more digits than float64 holds survives the round trip.
+	units, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	wide := mpfr.NewFloatWithPrec(200).SetFixed(units, 18)
+	if got := wide.Fixed(18, mpfr.RoundToNearest); got.Cmp(units) != 0 {
+		t.Errorf("Fixed(SetFixed(%v, 18), 18) = %v", units, got)
+	}
+
+	// At 64 bits 123.455 is stored just above the decimal value, so it rounds up;
+	// the float64 nearest 123.455 lies just below and rounds down.
+	above := mpfr.MustParse("123.455", 64)
+	below := mpfr.FromFloat64(123.455)
+	tests := []struct {
+		name     string
+		x        *mpfr.Float
+		decimals int
+		rnd      mpfr.Rnd
+		want     int64
+	}{
+		{"123.455 at 64 bits", above, 2, mpfr.RoundToNearest, 12346},
+		{"float64 123.455", below, 2, mpfr.RoundToNearest, 12345},
+		{"tie to even", mpfr.FromFloat64(0.125), 2, mpfr.RoundToNearest, 12},
+		{"tie away", mpfr.FromFloat64(0.125), 2, mpfr.RoundAway, 13},
+		{"floor negative", mpfr.FromFloat64(-1.001), 2, mpfr.RoundDown, -101},
+		{"truncate negative", mpfr.FromFloat64(-1.009), 2, mpfr.RoundToward0, -100},
+		{"negative decimals", mpfr.FromInt(12550), -2, mpfr.RoundToNearest, 126},
+		{"negative decimals tie", mpfr.FromInt(12500), -3, mpfr.RoundToNearest, 12},
+		{"negative decimals ceiling", mpfr.FromFloat64(-1250.5), -2, mpfr.RoundUp, -12},
+		{"zero", mpfr.FromInt(0), 5, mpfr.RoundToNearest, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.x.Fixed(tt.decimals, tt.rnd); got == nil || got.Cmp(big.NewInt(tt.want)) != 0 {
+			t.Errorf("Fixed(%s) = %v; want %d", tt.name, got, tt.want)
+		}
+	}
+	if got := mpfr.FromFloat64(math.Inf(1)).Fixed(2, mpfr.RoundToNearest); got != nil {
+		t.Errorf("Fixed(+Inf) = %v; want nil", got)
+	}
+}
+
+func TestQuo(t *testing.T) {
+	tests := []struct {
+		x, y        float64
+		rnd         mpfr.Rnd
+		expected    string
+		shouldPanic bool
+	}{
+		{10, 2, mpfr.RoundToNearest, "5", false},
+		{10, 3, mpfr.RoundToNearest, "3.333333333333333", false},
+		{10, 3, mpfr.RoundToward0, "3.333333333333333", false},
+		{10, 0, mpfr.RoundToNearest, "", true},
+		{-10, 0, mpfr.RoundToNearest, "", true},
+	}
+
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					if !tt.shouldPanic {
+						t.Errorf("Quo(%v, %v) unexpectedly panicked: %v", tt.x, tt.y, r)
+					}
+				} else if tt.shouldPanic {
+					t.Errorf("Quo(%v, %v) did not panic as expected", tt.x, tt.y)
+				}
+			}()
+
+			x := mpfr.FromFloat64(tt.x)
+			y := mpfr.FromFloat64(tt.y)
+			result := mpfr.NewFloat()
+
+			if !tt.shouldPanic {
+				result.Quo(x, y)
+				got := result.Float64()
+				expected, _ := strconv.ParseFloat(tt.expected, 64)
+				closeEnough := almostEqual(got, expected)
+				if !closeEnough {
+					t.Errorf("Quo(%v, %v) got %v; want %v", tt.x, tt.y, got, tt.expected)
+				}
+			} else {
+				_ = result.Quo(x, y) // Expect a panic
+			}
+		}()
+	}
+}
+
+func TestCmp(t *testing.T) {
+	a := mpfr.NewFloat().SetFloat64(2.0)
+	b := mpfr.NewFloat().SetFloat64(2.0)
 	c := mpfr.NewFloat().SetFloat64(3.0)
 
 	if a.Cmp(b) != 0 {
@@ -267,6 +1113,40 @@ func TestCopy(t *testing.T) {
 	}
 }
 
+func TestSetPrecRnd(t *testing.T) {
+	// 1 + 2^-30 needs 31 bits, so at 24 bits it lies strictly between 1 and 1 + 2^-23.
+	x := mpfr.NewFloatWithPrec(300).SetFloat64(1 + math.Ldexp(1, -30))
+
+	down := mpfr.NewFloat().SetPrecRnd(x, 24, mpfr.RoundDown)
+	up := mpfr.NewFloat()
+	ternary := up.SetPrecRndTernary(x, 24, mpfr.RoundUp)
+	if down.GetPrec() != 24 || up.GetPrec() != 24 {
+		t.Fatalf("precisions = %d, %d; want 24", down.GetPrec(), up.GetPrec())
+	}
+	if down.GetFloat64() != 1 || up.GetFloat64() != 1+math.Ldexp(1, -23) {
+		t.Errorf("24-bit RoundDown, RoundUp = %v, %v; want 1, 1+2^-23", down, up)
+	}
+	if ternary <= 0 {
+		t.Errorf("RoundUp ternary = %d; want > 0", ternary)
+	}
+	if down.RoundingMode != mpfr.RoundDown || up.RoundingMode != mpfr.RoundUp {
+		t.Errorf("rounding modes = %v, %v; want RoundDown, RoundUp", down.RoundingMode, up.RoundingMode)
+	}
+	if x.GetPrec() != 300 {
+		t.Errorf("source precision changed to %d", x.GetPrec())
+	}
+
+	if tern := mpfr.NewFloat().SetPrecRndTernary(mpfr.FromInt(3), 24, mpfr.RoundDown); tern != 0 {
+		t.Errorf("ternary for exact 3 = %d; want 0", tern)
+	}
+
+	// Aliasing the source keeps its value.
+	x.SetPrecRnd(x, 24, mpfr.RoundDown)
+	if x.GetPrec() != 24 || x.GetFloat64() != 1 {
+		t.Errorf("x.SetPrecRnd(x, 24, RoundDown) = %v (prec %d); want 1 (prec 24)", x, x.GetPrec())
+	}
+}
+
 func TestClear(t *testing.T) {
 	f := mpfr.NewFloat().SetFloat64(3.14159)
 	f.Clear()
@@ -290,6 +1170,136 @@ func TestPow(t *testing.T) {
 	}
 }
 
+func TestPowTyped(t *testing.T) {
+	x := mpfr.NewFloat().SetFloat64(2.0)
+	y := mpfr.NewFloat().SetFloat64(10.0)
+
+	if got := mpfr.NewFloat().PowOf(x, y).GetFloat64(); got != 1024 {
+		t.Errorf("PowOf(2, 10) = %v; want 1024", got)
+	}
+	if got := mpfr.NewFloat().SetFloat64(3).PowSelf(y).GetFloat64(); got != 59049 {
+		t.Errorf("3.PowSelf(10) = %v; want 59049", got)
+	}
+	if x.GetFloat64() != 2 || y.GetFloat64() != 10 {
+		t.Errorf("PowOf modified its arguments: x = %v, y = %v", x, y)
+	}
+
+	// The deprecated variadic form must keep working for existing callers.
+	if got := mpfr.NewFloat().Pow(x, y).GetFloat64(); got != 1024 {
+		t.Errorf("Pow(2, 10) = %v; want 1024", got)
+	}
+	if got := mpfr.NewFloat().SetFloat64(3).Pow(y).GetFloat64(); got != 59049 {
+		t.Errorf("3.Pow(10) = %v; want 59049", got)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Pow(2.0) with a float64 argument did not panic")
+		}
+	}()
+	mpfr.NewFloat().Pow(2.0)
+}
+
+func TestSmartPow(t *testing.T) {
+	x := mpfr.FromFloat64(2)
+	third := mpfr.NewFloat().Quo(mpfr.FromInt(1), mpfr.FromInt(3))
+	tests := []struct {
+		name string
+		y    *mpfr.Float
+		want *mpfr.Float
+	}{
+		{"y=0.5", mpfr.FromFloat64(0.5), mpfr.NewFloat().Sqrt(x)},
+		{"y=-0.5", mpfr.FromFloat64(-0.5), mpfr.NewFloat().RecSqrt(x)},
+		{"y=-7", mpfr.FromInt(-7), mpfr.FromFloat64(1.0 / 128)},
+		{"y=1/3", third, mpfr.NewFloat().Cbrt(x)},
+		{"y=0.75", mpfr.FromFloat64(0.75), mpfr.NewFloat().PowOf(x, mpfr.FromFloat64(0.75))},
+	}
+	for _, tc := range tests {
+		if got := mpfr.NewFloat().SmartPow(x, tc.y); got.Cmp(tc.want) != 0 {
+			t.Errorf("SmartPow(2, %s) = %v; want %v", tc.name, got, tc.want)
+		}
+	}
+
+	// The Cbrt branch gives a real root for a negative base, where Pow gives NaN.
+	neg := mpfr.FromFloat64(-27)
+	if got := mpfr.NewFloat().SmartPow(neg, third).GetFloat64(); got != -3 {
+		t.Errorf("SmartPow(-27, 1/3) = %v; want -3", got)
+	}
+	// A value near 1/3 that is not its nearest representation must not match.
+	near := mpfr.NewFloat().Quo(mpfr.FromInt(1), mpfr.FromInt(3))
+	near.NextAbove()
+	if got := mpfr.NewFloat().SmartPow(neg, near); !got.IsNaN() {
+		t.Errorf("SmartPow(-27, nextabove(1/3)) = %v; want NaN", got)
+	}
+	// A NaN exponent must not be mistaken for ±0.5.
+	if got := mpfr.NewFloat().SmartPow(x, mpfr.FromFloat64(math.NaN())); !got.IsNaN() {
+		t.Errorf("SmartPow(2, NaN) = %v; want NaN", got)
+	}
+	// Zero and infinite bases keep Pow's rules rather than Sqrt's.
+	negZero := mpfr.NewFloat().SetZero(-1)
+	if got := mpfr.NewFloat().SmartPow(negZero, mpfr.FromFloat64(0.5)); !got.IsPosZero() {
+		t.Errorf("SmartPow(-0, 0.5) = %v; want +0", got)
+	}
+	negInf := mpfr.FromFloat64(math.Inf(-1))
+	if got := mpfr.NewFloat().SmartPow(negInf, mpfr.FromFloat64(0.5)); !got.IsInf() || got.Signbit() {
+		t.Errorf("SmartPow(-Inf, 0.5) = %v; want +Inf", got)
+	}
+}
+
+func TestPowEdgeCases(t *testing.T) {
+	third := mpfr.NewFloat().Quo(mpfr.FromInt(1), mpfr.FromInt(3))
+	tests := []struct {
+		name string
+		x, y *mpfr.Float
+		want float64 // NaN means the result must be NaN
+	}{
+		{"0^0", mpfr.FromInt(0), mpfr.FromInt(0), 1},
+		{"NaN^0", mpfr.FromFloat64(math.NaN()), mpfr.FromInt(0), 1},
+		{"0^-1", mpfr.FromInt(0), mpfr.FromInt(-1), math.Inf(1)},
+		{"2^-3", mpfr.FromInt(2), mpfr.FromInt(-3), 0.125},
+		{"(-2)^-3", mpfr.FromInt(-2), mpfr.FromInt(-3), -0.125},
+		{"(-2)^2", mpfr.FromInt(-2), mpfr.FromInt(2), 4},
+		{"(-1)^0.5", mpfr.FromInt(-1), mpfr.FromFloat64(0.5), math.NaN()},
+		{"(-8)^(1/3)", mpfr.FromInt(-8), third, math.NaN()},
+	}
+
+	for _, tt := range tests {
+		got := mpfr.Pow(tt.x, tt.y, mpfr.RoundToNearest).GetFloat64()
+		if math.IsNaN(tt.want) {
+			if !math.IsNaN(got) {
+				t.Errorf("Pow(%s) = %v; want NaN", tt.name, got)
+			}
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Pow(%s) = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// The real cube root of -8 is available through Cbrt.
+	if got := mpfr.Cbrt(mpfr.FromInt(-8), mpfr.RoundToNearest).GetFloat64(); got != -2 {
+		t.Errorf("Cbrt(-8) = %v; want -2", got)
+	}
+}
+
+func TestPowReal(t *testing.T) {
+	if _, err := mpfr.PowReal(mpfr.FromInt(-1), mpfr.FromFloat64(0.5), mpfr.RoundToNearest); err != mpfr.ErrDomain {
+		t.Errorf("PowReal(-1, 0.5) error = %v; want ErrDomain", err)
+	}
+
+	got, err := mpfr.PowReal(mpfr.FromInt(-2), mpfr.FromInt(3), mpfr.RoundToNearest)
+	if err != nil {
+		t.Fatalf("PowReal(-2, 3) returned error: %v", err)
+	}
+	if got.GetFloat64() != -8 {
+		t.Errorf("PowReal(-2, 3) = %v; want -8", got.GetFloat64())
+	}
+
+	got, err = mpfr.PowReal(mpfr.FromFloat64(math.NaN()), mpfr.FromInt(2), mpfr.RoundToNearest)
+	if err != nil || !got.IsNaN() {
+		t.Errorf("PowReal(NaN, 2) = (%v, %v); want (NaN, nil)", got, err)
+	}
+}
+
 func TestExp(t *testing.T) {
 	x := mpfr.NewFloat().SetFloat64(1.0) // e^1 = e
 
@@ -383,6 +1393,47 @@ func TestAgm(t *testing.T) {
 	}
 }
 
+func TestAGMStep(t *testing.T) {
+	a := mpfr.FromInt(1)
+	b := mpfr.FromInt(9)
+
+	a2, b2 := mpfr.AGMStep(a, b, 128, mpfr.RoundToNearest)
+	if got := a2.GetFloat64(); got != 5.0 {
+		t.Errorf("AGMStep(1, 9) arithmetic mean = %v; want 5", got)
+	}
+	if got := b2.GetFloat64(); got != 3.0 {
+		t.Errorf("AGMStep(1, 9) geometric mean = %v; want 3", got)
+	}
+	if a.GetFloat64() != 1.0 || b.GetFloat64() != 9.0 {
+		t.Errorf("AGMStep modified its inputs: a=%v b=%v", a.GetFloat64(), b.GetFloat64())
+	}
+
+	// Iterating converges to the same value as Agm.
+	for i := 0; i < 8; i++ {
+		a, b = mpfr.AGMStep(a, b, 128, mpfr.RoundToNearest)
+	}
+	want := mpfr.Agm(mpfr.FromInt(1), mpfr.FromInt(9), mpfr.RoundToNearest).GetFloat64()
+	if !almostEqual(a.GetFloat64(), want) || !almostEqual(b.GetFloat64(), want) {
+		t.Errorf("AGMStep iteration = (%v, %v); want %v", a.GetFloat64(), b.GetFloat64(), want)
+	}
+}
+
+func TestAliasedOperands(t *testing.T) {
+	x := mpfr.FromFloat64(1.5)
+	x.Add(x)
+	if got := x.GetFloat64(); got != 3.0 {
+		t.Errorf("x.Add(x) with x=1.5 = %v; want 3", got)
+	}
+	x.Mul(x)
+	if got := x.GetFloat64(); got != 9.0 {
+		t.Errorf("x.Mul(x) with x=3 = %v; want 9", got)
+	}
+	x.Sub(x)
+	if !x.IsZero() {
+		t.Errorf("x.Sub(x) = %v; want 0", x.GetFloat64())
+	}
+}
+
 func TestAsin(t *testing.T) {
 	x := mpfr.NewFloat().SetFloat64(0.0)
 	got := mpfr.NewFloat().Asin(x)
@@ -568,80 +1619,332 @@ func TestCeil(t *testing.T) {
 	}
 }
 
-func TestCmpAbs(t *testing.T) {
-	x := mpfr.NewFloat().SetFloat64(-3.5)
-	y := mpfr.NewFloat().SetFloat64(2.0)
-	res := mpfr.CmpAbs(x, y)
-	// |-3.5|=3.5, |2.0|=2 => 3.5>2 => res=1
-	if res != 1 {
-		t.Errorf("CmpAbs(-3.5, 2.0) = %v; want 1", res)
+func TestRecGamma(t *testing.T) {
+	if got := mpfr.NewFloat().RecGamma(mpfr.FromInt(0)); !got.IsPosZero() {
+		t.Errorf("RecGamma(0) = %v; want +0", got)
+	}
+	if got := mpfr.NewFloat().RecGamma(mpfr.NewFloat().SetZero(-1)); !got.IsNegZero() {
+		t.Errorf("RecGamma(-0) = %v; want -0", got)
+	}
+	for _, n := range []int{-1, -2, -7} {
+		if got := mpfr.NewFloat().RecGamma(mpfr.FromInt(n)); !got.IsPosZero() {
+			t.Errorf("RecGamma(%d) = %v; want 0", n, got)
+		}
 	}
-}
 
-func TestCos(t *testing.T) {
-	x := mpfr.NewFloat().SetFloat64(0.0)
-	got := mpfr.NewFloat().Cos(x)
-	want := 1.0 // cos(0)=1
-	if !almostEqual(got.GetFloat64(), want) {
-		t.Errorf("Cos(0) got %v; want %v", got, want)
+	want := mpfr.NewFloat().Quo(mpfr.FromInt(1), mpfr.FromInt(24))
+	if got := mpfr.RecGamma(mpfr.FromInt(5), mpfr.RoundToNearest); got.Cmp(want) != 0 {
+		t.Errorf("RecGamma(5) = %v; want %v", got, want)
 	}
-	got2 := mpfr.Cos(x, mpfr.RoundToNearest)
-	if !almostEqual(got2.GetFloat64(), want) {
-		t.Errorf("Cos(0) got %v; want %v", got2.GetFloat64(), want)
+	// In place: 1/Γ(1/2) = 1/√π.
+	half := mpfr.NewFloatWithPrec(100).SetFloat64(0.5).RecGamma()
+	wantHalf := mpfr.NewFloatWithPrec(100).RecSqrt(mpfr.Pi(200, mpfr.RoundToNearest))
+	if !half.EqualULP(wantHalf, 1) {
+		t.Errorf("RecGamma(0.5) = %v; want %v", half, wantHalf)
 	}
-	got3 := x.Cos()
-	if !almostEqual(got3.GetFloat64(), want) {
-		t.Errorf("Cos(0) got %v; want %v", got3.GetFloat64(), want)
+	// Between the poles the reciprocal is small but nonzero and changes sign.
+	if got := mpfr.NewFloat().RecGamma(mpfr.FromFloat64(-1.5)); got.Sign() <= 0 {
+		t.Errorf("RecGamma(-1.5) = %v; want positive", got)
+	}
+	if got := mpfr.NewFloat().RecGamma(mpfr.FromFloat64(math.Inf(1))); !got.IsPosZero() {
+		t.Errorf("RecGamma(+Inf) = %v; want +0", got)
+	}
+	if got := mpfr.NewFloat().RecGamma(mpfr.FromFloat64(math.Inf(-1))); !got.IsNaN() {
+		t.Errorf("RecGamma(-Inf) = %v; want NaN", got)
 	}
 }
 
-func TestCosh(t *testing.T) {
-	x := mpfr.NewFloat().SetFloat64(0.0)
-	got := mpfr.NewFloat().Cosh(x)
-	want := 1.0 // cosh(0)=1
-	if !almostEqual(got.GetFloat64(), want) {
-		t.Errorf("Cosh(0) got %v; want %v", got, want)
+func TestBesselI(t *testing.T) {
+	if got := mpfr.NewFloat().BesselI(0, mpfr.FromInt(0)).GetFloat64(); got != 1 {
+		t.Errorf("I0(0) = %v; want 1", got)
 	}
-	got2 := mpfr.Cosh(x, mpfr.RoundToNearest)
-	if !almostEqual(got2.GetFloat64(), want) {
-		t.Errorf("Cosh(0) got %v; want %v", got2, want)
+	if got := mpfr.NewFloat().BesselI(3, mpfr.FromInt(0)); !got.IsZero() {
+		t.Errorf("I3(0) = %v; want 0", got)
 	}
-	got3 := x.Cosh()
-	if !almostEqual(got3.GetFloat64(), want) {
-		t.Errorf("Cosh(0) got %v; want %v", got3.GetFloat64(), want)
+
+	tests := []struct {
+		n    int
+		x    float64
+		want string
+	}{
+		// Reference values from the series summed in exact rational arithmetic.
+		{0, 1, "1.266065877752008335598244625214717537607670311354962206808135331213575"},
+		{1, 1, "0.5651591039924850272076960276098633073288996216210920094802944894792556"},
+		{2, -3, "2.245212440929951154625478385634265057701514459678491497669000156488985"},
+		{1, -3, "-3.953370217402609396478635740580581287584221595091259177585845489515362"},
+		{-1, 3, "3.953370217402609396478635740580581287584221595091259177585845489515362"},
+		{0, 50, "293255378384933632665.4675079456853858051295754348464637687510971500105"},
+	}
+	for _, tc := range tests {
+		got := mpfr.NewFloatWithPrec(200).BesselI(tc.n, mpfr.FromFloat64(tc.x))
+		if want := mpfr.MustParse(tc.want, 200); !got.EqualULP(want, 1) {
+			t.Errorf("I%d(%v) = %v; want %v", tc.n, tc.x, got, tc.want)
+		}
+	}
+
+	// The recurrence I(n-1) - I(n+1) = (2n/x)·I(n) holds to working precision.
+	x := mpfr.FromFloat64(7.5)
+	const n = 4
+	lhs := mpfr.NewFloatWithPrec(300).BesselI(n-1, x)
+	lhs.Sub(mpfr.NewFloatWithPrec(300).BesselI(n+1, x))
+	rhs := mpfr.NewFloatWithPrec(300).BesselI(n, x)
+	rhs.Mul(mpfr.FromInt(2 * n)).Div(x)
+	if bits := mpfr.CorrectBits(lhs, rhs); bits < 290 {
+		t.Errorf("Bessel I recurrence holds to %d bits; want at least 290", bits)
+	}
+	if got := mpfr.BesselI(1, mpfr.FromFloat64(math.Inf(-1)), mpfr.RoundToNearest); !got.IsInf() || !got.Signbit() {
+		t.Errorf("I1(-Inf) = %v; want -Inf", got)
 	}
 }
 
-func TestCot(t *testing.T) {
-	// cot(pi/4) = 1
-	val := math.Pi / 4
-	x := mpfr.NewFloat().SetFloat64(val)
-	got := mpfr.NewFloat().Cot(x)
-	want := 1.0
-	if math.Abs(got.GetFloat64()-want) > 1e-7 {
-		t.Errorf("Cot(pi/4) got %v; want %v", got, want)
+func TestModGo(t *testing.T) {
+	if got := mpfr.NewFloat().ModGo(mpfr.FromInt(-5), mpfr.FromInt(3)).GetFloat64(); got != -2 {
+		t.Errorf("ModGo(-5, 3) = %v; want -2", got)
 	}
-	got2 := mpfr.Cot(x, mpfr.RoundToNearest)
-	if !almostEqual(got2.GetFloat64(), want) {
-		t.Errorf("Cot(pi/4) got %v; want %v", got2.GetFloat64(), want)
+	if got := mpfr.NewFloat().Remainder(mpfr.FromInt(-5), mpfr.FromInt(3)).GetFloat64(); got != 1 {
+		t.Errorf("Remainder(-5, 3) = %v; want 1", got)
 	}
-	got3 := x.Cot()
-	if !almostEqual(got3.GetFloat64(), want) {
-		t.Errorf("Cot(pi/4) got %v; want %v", got3.GetFloat64(), want)
+
+	inf, nan := math.Inf(1), math.NaN()
+	vals := []float64{-5, 5, 3, -3, 0.75, 1e300, -1e-300, 6, math.Copysign(0, -1), 0, inf, -inf, nan}
+	for _, a := range vals {
+		for _, b := range vals {
+			want := math.Mod(a, b)
+			got := mpfr.NewFloat().ModGo(mpfr.FromFloat64(a), mpfr.FromFloat64(b))
+			g := got.GetFloat64()
+			if math.IsNaN(want) != got.IsNaN() || (!math.IsNaN(want) && (g != want || math.Signbit(g) != math.Signbit(want))) {
+				t.Errorf("ModGo(%v, %v) = %v; want math.Mod = %v", a, b, g, want)
+			}
+		}
 	}
 }
 
-func TestCoth(t *testing.T) {
-	// coth(1) ~ 1.313035285
-	x := mpfr.NewFloat().SetFloat64(1.0)
-	got := mpfr.NewFloat().Coth(x)
-	want := 1.3130352854993312
-	diff := math.Abs(got.GetFloat64() - want)
-	if diff > 1e-7 {
-		t.Errorf("Coth(1) got %v; want ~1.313035285", got)
+func TestDivMod(t *testing.T) {
+	// 10^40 / 7: the quotient is far beyond int64 but comes back exact.
+	x := mpfr.MustParse("1e40", 200)
+	q, r := mpfr.NewFloat().DivMod(x, mpfr.FromInt(7))
+	if want := mpfr.MustParse("1428571428571428571428571428571428571428", 200); q.Cmp(want) != 0 {
+		t.Errorf("DivMod(1e40, 7) quotient = %v; want %v", q, want)
 	}
-	got2 := mpfr.Coth(x, mpfr.RoundToNearest)
-	if !almostEqual(got2.GetFloat64(), want) {
+	if got := r.GetFloat64(); got != 4 {
+		t.Errorf("DivMod(1e40, 7) remainder = %v; want 4", got)
+	}
+
+	// The float64 nearest 1e40, with a negative dividend: q·y + r reconstructs x.
+	xf := mpfr.FromFloat64(-1e40)
+	q, r = mpfr.DivMod(xf, mpfr.FromInt(7), mpfr.RoundToNearest)
+	if want := mpfr.MustParse("-1428571428571428614826575489571952412965", 200); q.Cmp(want) != 0 {
+		t.Errorf("DivMod(-1e40, 7) quotient = %v; want %v", q, want)
+	}
+	if got := r.GetFloat64(); got != 3 {
+		t.Errorf("DivMod(-1e40, 7) remainder = %v; want 3", got)
+	}
+	back := mpfr.NewFloatWithPrec(300).Fmma(q, mpfr.FromInt(7), r, mpfr.FromInt(1))
+	if back.Cmp(xf) != 0 {
+		t.Errorf("q·7 + r = %v; want %v", back, xf)
+	}
+
+	tests := []struct{ x, y, q, r float64 }{
+		{7, 2, 3, 1},
+		{-7, 2, -4, 1},
+		{7, -2, -3, 1},
+		{-7, -2, 4, 1},
+		{-0.5, 1e10, -1, 1e10 - 0.5},
+		{6, 3, 2, 0},
+		{0.25, 0.75, 0, 0.25},
+	}
+	for _, tc := range tests {
+		q, r := mpfr.NewFloat().DivMod(mpfr.FromFloat64(tc.x), mpfr.FromFloat64(tc.y))
+		if q.GetFloat64() != tc.q || r.GetFloat64() != tc.r || r.Signbit() {
+			t.Errorf("DivMod(%v, %v) = (%v, %v); want (%v, %v)", tc.x, tc.y, q, r, tc.q, tc.r)
+		}
+	}
+
+	for _, y := range []float64{0, math.Inf(1), math.NaN()} {
+		q, r := mpfr.NewFloat().DivMod(mpfr.FromInt(3), mpfr.FromFloat64(y))
+		if !q.IsNaN() || !r.IsNaN() {
+			t.Errorf("DivMod(3, %v) = (%v, %v); want NaN", y, q, r)
+		}
+	}
+}
+
+func TestDistToInt(t *testing.T) {
+	// The subtraction is exact, so it matches float64 arithmetic bit for bit.
+	tests := []struct{ x, nearest float64 }{
+		{2.3, 2},
+		{2.7, 3},
+		{-2.3, -2},
+		// Ties go to the even neighbour.
+		{2.5, 2},
+		{3.5, 4},
+		{-2.5, -2},
+		{7, 7},
+		{1e300, 1e300},
+		{0.25, 0},
+	}
+	for _, tc := range tests {
+		x := mpfr.FromFloat64(tc.x)
+		got := x.DistToInt()
+		if g, want := got.GetFloat64(), tc.x-tc.nearest; g != want {
+			t.Errorf("DistToInt(%v) = %v; want %v", tc.x, g, want)
+		}
+		if x.GetFloat64() != tc.x {
+			t.Errorf("DistToInt modified its receiver: %v", x)
+		}
+	}
+	if got := mpfr.FromFloat64(math.Inf(1)).DistToInt(); !got.IsNaN() {
+		t.Errorf("DistToInt(+Inf) = %v; want NaN", got)
+	}
+}
+
+func TestSort(t *testing.T) {
+	nan := mpfr.FromFloat64(math.NaN())
+	negNaN := mpfr.NewFloat().Neg(nan)
+	posInf := mpfr.FromFloat64(math.Inf(1))
+	negInf := mpfr.FromFloat64(math.Inf(-1))
+	posZero := mpfr.NewFloat().SetZero(1)
+	negZero := mpfr.NewFloat().SetZero(-1)
+	two1, two2 := mpfr.FromInt(2), mpfr.FromInt(2)
+
+	xs := []*mpfr.Float{nan, two1, posZero, negInf, mpfr.FromInt(-3), negZero, posInf, two2, negNaN, mpfr.FromFloat64(-0.5)}
+	mpfr.Sort(xs)
+	want := "-NaN -Inf -3 -0.5 -0 +0 2 2 +Inf +NaN"
+	if got := describeSorted(xs); got != want {
+		t.Errorf("Sort = %s; want %s", got, want)
+	}
+
+	xs = []*mpfr.Float{two1, negZero, two2, posZero}
+	mpfr.SortStable(xs)
+	if xs[2] != two1 || xs[3] != two2 {
+		t.Error("SortStable reordered equal elements")
+	}
+	if got := describeSorted(xs); got != "-0 +0 2 2" {
+		t.Errorf("SortStable = %s; want -0 +0 2 2", got)
+	}
+}
+
+// describeSorted renders xs with explicit signs on zeros, infinities and NaNs.
+func describeSorted(xs []*mpfr.Float) string {
+	parts := make([]string, len(xs))
+	for i, x := range xs {
+		sign := "+"
+		if x.Signbit() {
+			sign = "-"
+		}
+		switch {
+		case x.IsNaN():
+			parts[i] = sign + "NaN"
+		case x.IsInf():
+			parts[i] = sign + "Inf"
+		case x.IsZero():
+			parts[i] = sign + "0"
+		default:
+			parts[i] = strconv.FormatFloat(x.GetFloat64(), 'g', -1, 64)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestMulAddAssign(t *testing.T) {
+	// (x-1)^3 expanded as x^3 - 3x^2 + 3x - 1 and evaluated by Horner's rule at
+	// x = 1 + 2^-10 with 24-bit precision: the exact result is 2^-30, but the last
+	// product 1 + 2^-30 rounds to 1 before the -1 is added.
+	const prec = 24
+	x := mpfr.NewFloatWithPrec(prec).SetFloat64(1 + 1.0/1024)
+	coeffs := []float64{-3, 3, -1}
+
+	fused := mpfr.NewFloatWithPrec(prec).SetFloat64(1)
+	split := mpfr.NewFloatWithPrec(prec).SetFloat64(1)
+	for _, c := range coeffs {
+		cf := mpfr.NewFloatWithPrec(prec).SetFloat64(c)
+		fused.MulAddAssign(x, cf)
+		split.Mul(x)
+		split.Add(cf)
+	}
+
+	want := math.Ldexp(1, -30)
+	if got := fused.GetFloat64(); got != want {
+		t.Errorf("MulAddAssign Horner = %v; want %v", got, want)
+	}
+	if got := split.GetFloat64(); got == want {
+		t.Errorf("separate Mul and Add unexpectedly gave the exact %v", got)
+	}
+}
+
+func TestCmpAbs(t *testing.T) {
+	x := mpfr.NewFloat().SetFloat64(-3.5)
+	y := mpfr.NewFloat().SetFloat64(2.0)
+	res := mpfr.CmpAbs(x, y)
+	// |-3.5|=3.5, |2.0|=2 => 3.5>2 => res=1
+	if res != 1 {
+		t.Errorf("CmpAbs(-3.5, 2.0) = %v; want 1", res)
+	}
+}
+
+func TestCos(t *testing.T) {
+	x := mpfr.NewFloat().SetFloat64(0.0)
+	got := mpfr.NewFloat().Cos(x)
+	want := 1.0 // cos(0)=1
+	if !almostEqual(got.GetFloat64(), want) {
+		t.Errorf("Cos(0) got %v; want %v", got, want)
+	}
+	got2 := mpfr.Cos(x, mpfr.RoundToNearest)
+	if !almostEqual(got2.GetFloat64(), want) {
+		t.Errorf("Cos(0) got %v; want %v", got2.GetFloat64(), want)
+	}
+	got3 := x.Cos()
+	if !almostEqual(got3.GetFloat64(), want) {
+		t.Errorf("Cos(0) got %v; want %v", got3.GetFloat64(), want)
+	}
+}
+
+func TestCosh(t *testing.T) {
+	x := mpfr.NewFloat().SetFloat64(0.0)
+	got := mpfr.NewFloat().Cosh(x)
+	want := 1.0 // cosh(0)=1
+	if !almostEqual(got.GetFloat64(), want) {
+		t.Errorf("Cosh(0) got %v; want %v", got, want)
+	}
+	got2 := mpfr.Cosh(x, mpfr.RoundToNearest)
+	if !almostEqual(got2.GetFloat64(), want) {
+		t.Errorf("Cosh(0) got %v; want %v", got2, want)
+	}
+	got3 := x.Cosh()
+	if !almostEqual(got3.GetFloat64(), want) {
+		t.Errorf("Cosh(0) got %v; want %v", got3.GetFloat64(), want)
+	}
+}
+
+func TestCot(t *testing.T) {
+	// cot(pi/4) = 1
+	val := math.Pi / 4
+	x := mpfr.NewFloat().SetFloat64(val)
+	got := mpfr.NewFloat().Cot(x)
+	want := 1.0
+	if math.Abs(got.GetFloat64()-want) > 1e-7 {
+		t.Errorf("Cot(pi/4) got %v; want %v", got, want)
+	}
+	got2 := mpfr.Cot(x, mpfr.RoundToNearest)
+	if !almostEqual(got2.GetFloat64(), want) {
+		t.Errorf("Cot(pi/4) got %v; want %v", got2.GetFloat64(), want)
+	}
+	got3 := x.Cot()
+	if !almostEqual(got3.GetFloat64(), want) {
+		t.Errorf("Cot(pi/4) got %v; want %v", got3.GetFloat64(), want)
+	}
+}
+
+func TestCoth(t *testing.T) {
+	// coth(1) ~ 1.313035285
+	x := mpfr.NewFloat().SetFloat64(1.0)
+	got := mpfr.NewFloat().Coth(x)
+	want := 1.3130352854993312
+	diff := math.Abs(got.GetFloat64() - want)
+	if diff > 1e-7 {
+		t.Errorf("Coth(1) got %v; want ~1.313035285", got)
+	}
+	got2 := mpfr.Coth(x, mpfr.RoundToNearest)
+	if !almostEqual(got2.GetFloat64(), want) {
 		t.Errorf("Coth(1) got %v; want ~1.313035285", got2.GetFloat64())
 	}
 	got3 := x.Coth()
@@ -669,6 +1972,48 @@ func TestCsc(t *testing.T) {
 	}
 }
 
+func TestTrigReduced(t *testing.T) {
+	x := mpfr.NewFloat().SetFloat64(1e18)
+
+	// Reference: tan(1e18) at 53 bits from a 1000-bit reduction.
+	want := mpfr.NewFloat().TanReduced(x, 1000)
+	if got := mpfr.NewFloat().TanReduced(x, 128); got.Cmp(want) != 0 {
+		t.Errorf("TanReduced(1e18, 128) = %v; want %v", got.GetFloat64(), want.GetFloat64())
+	}
+	if got := mpfr.NewFloat().Tan(x); got.Cmp(want) != 0 {
+		t.Errorf("Tan(1e18) = %v; TanReduced(1e18, 1000) = %v", got.GetFloat64(), want.GetFloat64())
+	}
+	// Reducing with a 53-bit π leaves an error of order 1e18 · 2^-53 in the argument.
+	naive := mpfr.NewFloat().Remainder(x, mpfr.Pi(53, mpfr.RoundToNearest))
+	if naive.Tan().Cmp(want) == 0 {
+		t.Error("tan of a 53-bit reduction of 1e18 unexpectedly matches the reference")
+	}
+
+	for name, tc := range map[string]struct {
+		reduced func(f *mpfr.Float) *mpfr.Float
+		direct  func(f, x *mpfr.Float) *mpfr.Float
+	}{
+		"Cot": {func(f *mpfr.Float) *mpfr.Float { return f.CotReduced(x, 128) }, func(f, x *mpfr.Float) *mpfr.Float { return f.Cot(x) }},
+		"Sec": {func(f *mpfr.Float) *mpfr.Float { return f.SecReduced(x, 128) }, func(f, x *mpfr.Float) *mpfr.Float { return f.Sec(x) }},
+		"Csc": {func(f *mpfr.Float) *mpfr.Float { return f.CscReduced(x, 128) }, func(f, x *mpfr.Float) *mpfr.Float { return f.Csc(x) }},
+	} {
+		if got, want := tc.reduced(mpfr.NewFloat()), tc.direct(mpfr.NewFloat(), x); got.Cmp(want) != 0 {
+			t.Errorf("%sReduced(1e18) = %v; want %v", name, got.GetFloat64(), want.GetFloat64())
+		}
+	}
+
+	small := mpfr.NewFloat().SetFloat64(0.5)
+	if got := mpfr.NewFloat().TanReduced(small, 64).GetFloat64(); !almostEqual(got, math.Tan(0.5)) {
+		t.Errorf("TanReduced(0.5) = %v; want %v", got, math.Tan(0.5))
+	}
+	if got := mpfr.NewFloat().SecReduced(mpfr.FromInt(0), 64).GetFloat64(); got != 1 {
+		t.Errorf("SecReduced(0) = %v; want 1", got)
+	}
+	if got := mpfr.NewFloat().TanReduced(mpfr.FromFloat64(math.Inf(1)), 64); !got.IsNaN() {
+		t.Errorf("TanReduced(+Inf) = %v; want NaN", got.GetFloat64())
+	}
+}
+
 func TestCsch(t *testing.T) {
 	// csch(1) ~ 0.850918128
 	x := mpfr.NewFloat().SetFloat64(1.0)
@@ -702,6 +2047,76 @@ func TestExp10(t *testing.T) {
 	}
 }
 
+func TestExp10Log10RoundTrip(t *testing.T) {
+	for n := -30; n <= 30; n++ {
+		p := mpfr.NewFloatWithPrec(200).Exp10(mpfr.FromInt(n))
+		got := mpfr.NewFloatWithPrec(200).Log10(p)
+		if got.Cmp(mpfr.FromInt(n)) != 0 {
+			t.Errorf("Log10(Exp10(%d)) = %v; want %d", n, got, n)
+		}
+	}
+}
+
+func TestExp10m1(t *testing.T) {
+	x := mpfr.NewFloat().SetFloat64(-1e-10)
+	got := mpfr.NewFloat().Exp10m1(x)
+
+	// Reference: 10^x - 1 evaluated at 1000 bits, where the cancellation is harmless.
+	ref := mpfr.NewFloatWithPrec(1000).Exp10(x)
+	ref.Sub(mpfr.FromInt(1))
+	want := ref.GetFloat64()
+	if got.GetFloat64() != want {
+		t.Errorf("Exp10m1(-1e-10) = %v; want %v", got, want)
+	}
+
+	// The naive double-precision difference is off in the seventh significant digit.
+	naive := mpfr.NewFloat().Exp10(x).Sub(mpfr.FromInt(1)).GetFloat64()
+	if math.Abs(naive-want) < 1e-7*math.Abs(want) {
+		t.Errorf("naive 10^x - 1 = %v is unexpectedly close to %v", naive, want)
+	}
+
+	if got := mpfr.NewFloat().SetFloat64(2).Exp10m1().GetFloat64(); got != 99 {
+		t.Errorf("Exp10m1(2) = %v; want 99", got)
+	}
+	if got := mpfr.NewFloat().SetFloat64(math.Inf(-1)).Exp10m1().GetFloat64(); got != -1 {
+		t.Errorf("Exp10m1(-Inf) = %v; want -1", got)
+	}
+}
+
+func TestExpm1mx(t *testing.T) {
+	// Reference: e^x - 1 - x at 4000 bits, where the cancellation is harmless even
+	// for x = 1e-300.
+	ref := func(x *mpfr.Float) *mpfr.Float {
+		r := mpfr.NewFloatWithPrec(4000).Exp(x)
+		r.Sub(mpfr.FromInt(1), x)
+		r.SetPrec(53)
+		return r
+	}
+	for _, v := range []float64{1e-10, -1e-10, 1e-300, 0.3, -0.49, 0.5, -0.5, 1, -3, 20} {
+		x := mpfr.NewFloatWithPrec(53).SetFloat64(v)
+		got := mpfr.NewFloatWithPrec(53).Expm1mx(x)
+		if want := ref(x); !got.EqualULP(want, 1) {
+			t.Errorf("Expm1mx(%v) = %v; want %v", v, got, want)
+		}
+	}
+
+	// Directly, Expm1(x) - x is off in the sixth significant digit of x²/2 = 5e-21.
+	x := mpfr.NewFloatWithPrec(53).SetFloat64(1e-10)
+	naive := mpfr.NewFloatWithPrec(53).Expm1(x)
+	naive.Sub(x)
+	want := ref(x).GetFloat64()
+	if math.Abs(naive.GetFloat64()-want) < 1e-7*want {
+		t.Errorf("naive Expm1(x) - x = %v is unexpectedly close to %v", naive.GetFloat64(), want)
+	}
+
+	if got := mpfr.Expm1mx(mpfr.FromFloat64(math.Inf(-1)), mpfr.RoundToNearest); !got.IsInf() || got.Sign() < 0 {
+		t.Errorf("Expm1mx(-Inf) = %v; want +Inf", got)
+	}
+	if got := mpfr.NewFloat().Expm1mx(mpfr.NewFloat().SetZero(-1)); !got.IsPosZero() {
+		t.Errorf("Expm1mx(-0) = %v; want +0", got)
+	}
+}
+
 func TestExp2(t *testing.T) {
 	x := mpfr.NewFloat().SetFloat64(3.0)
 	got := mpfr.NewFloat().Exp2(x)
@@ -734,6 +2149,208 @@ func TestFloor(t *testing.T) {
 	}
 }
 
+func TestWillBeExact(t *testing.T) {
+	third := mpfr.NewFloat().Quo(mpfr.FromInt(1), mpfr.FromInt(3))
+	if !mpfr.WillAddBeExact(mpfr.FromFloat64(1.5), mpfr.FromFloat64(2.25), 53) {
+		t.Error("WillAddBeExact(1.5, 2.25, 53) = false; want true")
+	}
+	if mpfr.WillAddBeExact(third, mpfr.FromInt(1), 53) {
+		t.Error("WillAddBeExact(1/3, 1, 53) = true; want false")
+	}
+	if !mpfr.WillMulBeExact(mpfr.FromFloat64(1.5), mpfr.FromFloat64(2.25), 53) {
+		t.Error("WillMulBeExact(1.5, 2.25, 53) = false; want true")
+	}
+	if mpfr.WillMulBeExact(third, mpfr.FromInt(3), 53) {
+		t.Error("WillMulBeExact(1/3, 3, 53) = true; want false")
+	}
+	if !mpfr.WillAddBeExact(third, mpfr.NewFloat().Neg(third), 1) {
+		t.Error("WillAddBeExact(1/3, -1/3, 1) = false; want true")
+	}
+
+	// Cross-check against a single correctly rounded operation at prec bits.
+	one, zero := mpfr.FromInt(1), mpfr.FromInt(0)
+	vals := []float64{1, 3, -3, 0.75, 1.5, 1 << 40, 1.0 / 1024, 1 + 1.0/(1<<30), -(1 + 1.0/(1<<30)), 0, 5e-300, 1.0 / 3}
+	for _, prec := range []uint{2, 5, 24, 31, 53} {
+		for _, a := range vals {
+			for _, b := range vals {
+				x, y := mpfr.FromFloat64(a), mpfr.FromFloat64(b)
+				sumP := mpfr.NewFloatWithPrec(prec).Fmma(x, one, y, one)
+				sumX := mpfr.NewFloatWithPrec(2200).Fmma(x, one, y, one)
+				if want := sumP.Cmp(sumX) == 0; mpfr.WillAddBeExact(x, y, prec) != want {
+					t.Errorf("WillAddBeExact(%v, %v, %d) = %v; want %v", a, b, prec, !want, want)
+				}
+				prodP := mpfr.NewFloatWithPrec(prec).Fmma(x, y, zero, zero)
+				prodX := mpfr.NewFloatWithPrec(2200).Fmma(x, y, zero, zero)
+				if want := prodP.Cmp(prodX) == 0; mpfr.WillMulBeExact(x, y, prec) != want {
+					t.Errorf("WillMulBeExact(%v, %v, %d) = %v; want %v", a, b, prec, !want, want)
+				}
+			}
+		}
+	}
+}
+
+func TestLimbCount(t *testing.T) {
+	lb := mpfr.LimbBits()
+	if lb != 32 && lb != 64 {
+		t.Fatalf("LimbBits() = %d; want 32 or 64", lb)
+	}
+	tests := []struct {
+		prec uint
+		want int
+	}{
+		{1, 1},
+		{uint(lb), 1},
+		{uint(lb) + 1, 2},
+		{2 * uint(lb), 2},
+		{2*uint(lb) + 1, 3},
+		{10 * uint(lb), 10},
+	}
+	for _, tc := range tests {
+		if got := mpfr.NewFloatWithPrec(tc.prec).LimbCount(); got != tc.want {
+			t.Errorf("LimbCount() at %d bits = %d; want %d", tc.prec, got, tc.want)
+		}
+	}
+
+	// The count follows the precision, not the value.
+	f := mpfr.NewFloatWithPrec(uint(lb))
+	f.SetPrec(uint(lb) + 1)
+	if got := f.LimbCount(); got != 2 {
+		t.Errorf("LimbCount() after SetPrec(%d) = %d; want 2", lb+1, got)
+	}
+}
+
+func TestEpsilon(t *testing.T) {
+	eps := mpfr.Epsilon(53, mpfr.RoundToNearest)
+	if got, want := eps.GetFloat64(), math.Nextafter(1, 2)-1; got != want {
+		t.Errorf("Epsilon(53) = %v; want %v", got, want)
+	}
+
+	for _, prec := range []uint{2, 24, 53, 200} {
+		one := mpfr.NewFloatWithPrec(prec).SetFloat64(1)
+		next := mpfr.NewFloatWithPrec(prec).SetFloat64(1)
+		next.NextAbove()
+		if one.Add(one.Eps()).Cmp(next) != 0 {
+			t.Errorf("prec %d: 1 + Eps() = %v; want %v", prec, one, next)
+		}
+		if e := mpfr.Epsilon(prec, mpfr.RoundUp); e.GetPrec() != prec || e.RoundingMode != mpfr.RoundUp {
+			t.Errorf("Epsilon(%d, RoundUp) has precision %d and mode %v", prec, e.GetPrec(), e.RoundingMode)
+		}
+	}
+}
+
+func TestMonotone(t *testing.T) {
+	floats := func(vs ...float64) []*mpfr.Float {
+		xs := make([]*mpfr.Float, len(vs))
+		for i, v := range vs {
+			xs[i] = mpfr.FromFloat64(v)
+		}
+		return xs
+	}
+	values := func(xs []*mpfr.Float) []float64 {
+		vs := make([]float64, len(xs))
+		for i, x := range xs {
+			vs[i] = x.GetFloat64()
+		}
+		return vs
+	}
+
+	xs := floats(1, 2, 2, 1.5, 3, 4)
+	if got := mpfr.EnsureMonotone(xs, true); !slices.Equal(got, []int{3}) {
+		t.Errorf("EnsureMonotone(increasing) = %v; want [3]", got)
+	}
+	if got := mpfr.EnsureMonotone(xs, false); !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("EnsureMonotone(decreasing) = %v; want [1 2 3 4 5]", got)
+	}
+	mpfr.ClampMonotone(xs, true)
+	if got, want := values(xs), []float64{1, 2, 2, 2, 3, 4}; !slices.Equal(got, want) {
+		t.Errorf("ClampMonotone(increasing) = %v; want %v", got, want)
+	}
+	if got := mpfr.EnsureMonotone(xs, true); got != nil {
+		t.Errorf("EnsureMonotone after ClampMonotone = %v; want nil", got)
+	}
+
+	ys := floats(5, 4, 4.5, 1)
+	mpfr.ClampMonotone(ys, false)
+	if got, want := values(ys), []float64{5, 4, 4, 1}; !slices.Equal(got, want) {
+		t.Errorf("ClampMonotone(decreasing) = %v; want %v", got, want)
+	}
+
+	// A lower-precision entry cannot hold the previous value exactly, so it is
+	// clamped to the next representable value above it.
+	third := mpfr.NewFloatWithPrec(100).Quo(mpfr.FromInt(1), mpfr.FromInt(3))
+	low := mpfr.NewFloatWithPrec(10).SetFloat64(0.25)
+	zs := []*mpfr.Float{third, low}
+	mpfr.ClampMonotone(zs, true)
+	want := mpfr.NewFloat().SetPrecRnd(third, 10, mpfr.RoundUp)
+	if low.GetPrec() != 10 || low.Cmp(third) <= 0 || low.Cmp(want) != 0 {
+		t.Errorf("ClampMonotone to 10 bits gave %v; want %v, the 10-bit value just above 1/3", low.GetFloat64(), want.GetFloat64())
+	}
+
+	// Clamping the 10-bit entry rounds it above 1/3, past the 53-bit entry that
+	// follows, so ClampMonotone changes an index EnsureMonotone did not report.
+	mixed := []*mpfr.Float{
+		mpfr.NewFloatWithPrec(100).Quo(mpfr.FromInt(1), mpfr.FromInt(3)),
+		mpfr.NewFloatWithPrec(10).SetFloat64(0.25),
+		mpfr.NewFloatWithPrec(53).SetFloat64(0.3334),
+	}
+	if got := mpfr.EnsureMonotone(mixed, true); !slices.Equal(got, []int{1}) {
+		t.Errorf("EnsureMonotone(mixed precisions) = %v; want [1]", got)
+	}
+	mpfr.ClampMonotone(mixed, true)
+	if mixed[2].GetFloat64() == 0.3334 || mixed[2].Cmp(mixed[1]) != 0 {
+		t.Errorf("ClampMonotone(mixed precisions)[2] = %v; want %v, carried from index 1", mixed[2].GetFloat64(), mixed[1].GetFloat64())
+	}
+	if got := mpfr.EnsureMonotone(mixed, true); got != nil {
+		t.Errorf("EnsureMonotone after ClampMonotone(mixed precisions) = %v; want nil", got)
+	}
+
+	ns := floats(1, math.NaN(), 0.5, 2)
+	if got := mpfr.EnsureMonotone(ns, true); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("EnsureMonotone with NaN = %v; want [1 2]", got)
+	}
+	mpfr.ClampMonotone(ns, true)
+	if !ns[1].IsNaN() || ns[2].GetFloat64() != 1 {
+		t.Errorf("ClampMonotone with NaN = %v; want [1 NaN 1 2]", values(ns))
+	}
+}
+
+func TestRoundingChanged(t *testing.T) {
+	ops := []struct {
+		name string
+		fn   func(f, x *mpfr.Float) bool
+	}{
+		{"FloorChanged", (*mpfr.Float).FloorChanged},
+		{"CeilChanged", (*mpfr.Float).CeilChanged},
+		{"RoundChanged", (*mpfr.Float).RoundChanged},
+		{"TruncChanged", (*mpfr.Float).TruncChanged},
+	}
+	for _, op := range ops {
+		for _, tt := range []struct {
+			in      float64
+			changed bool
+		}{
+			{3.0, false},
+			{-3.0, false},
+			{3.2, true},
+			{-3.5, true},
+			{math.Inf(1), false},
+		} {
+			f := mpfr.NewFloat()
+			if got := op.fn(f, mpfr.NewFloat().SetFloat64(tt.in)); got != tt.changed {
+				t.Errorf("%s(%v) changed = %v; want %v", op.name, tt.in, got, tt.changed)
+			}
+		}
+	}
+
+	f := mpfr.NewFloat()
+	if !f.FloorChanged(mpfr.NewFloat().SetFloat64(3.2)) || f.GetFloat64() != 3 {
+		t.Errorf("FloorChanged(3.2) set f = %v; want 3", f)
+	}
+	if !f.CeilChanged(mpfr.NewFloat().SetFloat64(3.2)) || f.GetFloat64() != 4 {
+		t.Errorf("CeilChanged(3.2) set f = %v; want 4", f)
+	}
+}
+
 func TestFitsAll(t *testing.T) {
 	var smallVal float64 = 123
 	var bigVal float64 = 1e20
@@ -781,6 +2398,31 @@ func TestSetPrec(t *testing.T) {
 	}
 }
 
+func TestEnsurePrec(t *testing.T) {
+	// 0.1 at 53 bits is not the decimal 0.1, so a decimal round trip would change it.
+	x := mpfr.NewFloat().SetFloat64(0.1)
+	orig := mpfr.NewFloatWithPrec(53).Copy(x)
+	x.EnsurePrec(256)
+	if x.GetPrec() != 256 {
+		t.Errorf("EnsurePrec(256) left precision %d", x.GetPrec())
+	}
+	if x.Cmp(orig) != 0 {
+		t.Errorf("EnsurePrec(256) changed the value to %v; want %v", x, orig)
+	}
+
+	y := mpfr.Pi(256, mpfr.RoundToNearest)
+	want := y.String()
+	y.EnsurePrec(10)
+	if y.GetPrec() != 256 || y.String() != want {
+		t.Errorf("EnsurePrec(10) on a 256-bit Float changed it to %v (prec %d)", y, y.GetPrec())
+	}
+
+	inf := mpfr.NewFloat().SetFloat64(math.Inf(-1)).EnsurePrec(100)
+	if !inf.IsInf() || !inf.Signbit() {
+		t.Errorf("EnsurePrec(100) on -Inf = %v", inf)
+	}
+}
+
 func TestFromInt(t *testing.T) {
 	f := mpfr.FromInt(-42)
 	if got := f.GetFloat64(); got != -42.0 {
@@ -809,6 +2451,36 @@ func TestFromFloat64(t *testing.T) {
 	}
 }
 
+func TestFloat64Slices(t *testing.T) {
+	vs := []float64{0, -1.5, math.Pi, 1e-310, math.MaxFloat64, math.Inf(-1)}
+	xs := mpfr.FromFloat64Slice(vs, 100)
+	for i, x := range xs {
+		if got := x.GetPrec(); got != 100 {
+			t.Errorf("FromFloat64Slice element %d precision = %v; want 100", i, got)
+		}
+	}
+
+	got := mpfr.ToFloat64Slice(xs)
+	for i := range vs {
+		if got[i] != vs[i] {
+			t.Errorf("round trip element %d = %v; want %v", i, got[i], vs[i])
+		}
+	}
+	if xs[2].GetFloat64() != math.Pi {
+		t.Error("ToFloat64Slice cleared its input")
+	}
+
+	huge, err := mpfr.Parse("1e400", 64)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	negHuge := mpfr.NewFloatWithPrec(64).Neg(huge)
+	got = mpfr.ToFloat64Slice([]*mpfr.Float{huge, negHuge})
+	if !math.IsInf(got[0], 1) || !math.IsInf(got[1], -1) {
+		t.Errorf("ToFloat64Slice(±1e400) = %v; want [+Inf -Inf]", got)
+	}
+}
+
 func TestFromBigInt(t *testing.T) {
 	bi := big.NewInt(-1234567890123456789)
 	f := mpfr.FromBigInt(bi)
@@ -825,6 +2497,35 @@ func TestFromBigFloat(t *testing.T) {
 	}
 }
 
+func TestRndBigMapping(t *testing.T) {
+	for _, r := range []mpfr.Rnd{mpfr.RoundToNearest, mpfr.RoundToward0, mpfr.RoundUp, mpfr.RoundDown, mpfr.RoundAway} {
+		if got := mpfr.RndFromBig(r.ToBig()); got != r {
+			t.Errorf("RndFromBig(%v.ToBig()) = %v; want %v", r, got, r)
+		}
+	}
+	for _, m := range []big.RoundingMode{big.ToNearestEven, big.ToZero, big.AwayFromZero, big.ToNegativeInf, big.ToPositiveInf} {
+		if got := mpfr.RndFromBig(m).ToBig(); got != m {
+			t.Errorf("RndFromBig(%v).ToBig() = %v; want %v", m, got, m)
+		}
+	}
+	if got := mpfr.RndFromBig(big.ToNearestAway); got != mpfr.RoundToNearest {
+		t.Errorf("RndFromBig(ToNearestAway) = %v; want RoundToNearest", got)
+	}
+
+	// FromBigFloat rounds 1/3 at 100 bits down to 53 bits in the big.Float's direction.
+	third := func(m big.RoundingMode) *big.Float {
+		return new(big.Float).SetPrec(100).SetMode(m).Quo(big.NewFloat(1), big.NewFloat(3))
+	}
+	up := mpfr.FromBigFloat(third(big.ToPositiveInf))
+	if up.RoundingMode != mpfr.RoundUp || up.GetFloat64() <= 1.0/3 {
+		t.Errorf("FromBigFloat(1/3, ToPositiveInf) = %v with mode %v; want above 1/3 with RoundUp", up, up.RoundingMode)
+	}
+	down := mpfr.FromBigFloat(third(big.ToZero))
+	if down.RoundingMode != mpfr.RoundToward0 || down.GetFloat64() != 1.0/3 {
+		t.Errorf("FromBigFloat(1/3, ToZero) = %v with mode %v; want 1/3 with RoundToward0", down, down.RoundingMode)
+	}
+}
+
 func TestSetInt(t *testing.T) {
 	f := mpfr.NewFloat()
 	f.SetInt(-42)
@@ -841,6 +2542,31 @@ func TestSetInt64(t *testing.T) {
 	}
 }
 
+func TestSetFrac64(t *testing.T) {
+	third := mpfr.NewFloatWithPrec(200).SetFrac64(1, 3)
+	want := mpfr.NewFloatWithPrec(200).SetInt(1)
+	want.Div(mpfr.FromInt(3))
+	if third.Cmp(want) != 0 {
+		t.Errorf("SetFrac64(1, 3) = %v; want %v", third, want)
+	}
+
+	if got := mpfr.NewFloatWithPrec(8).SetFrac64(3, 8).GetFloat64(); got != 0.375 {
+		t.Errorf("SetFrac64(3, 8) = %v; want 0.375", got)
+	}
+	if got := mpfr.NewFloat().SetFrac64(-6, 4).GetFloat64(); got != -1.5 {
+		t.Errorf("SetFrac64(-6, 4) = %v; want -1.5", got)
+	}
+
+	// Operands beyond 32 bits must not be rounded before the division.
+	near1 := mpfr.NewFloatWithPrec(64).SetFrac64(math.MaxInt64, math.MaxInt64-1)
+	if near1.Cmp(mpfr.FromInt(1)) <= 0 {
+		t.Errorf("SetFrac64(MaxInt64, MaxInt64-1) = %v; want > 1", near1)
+	}
+	if !mpfr.NewFloat().SetFrac64(1, 0).IsInf() {
+		t.Error("SetFrac64(1, 0) is not Inf")
+	}
+}
+
 func TestSetUint64(t *testing.T) {
 	f := mpfr.NewFloat()
 	f.SetUint64(18446744073709551615)
@@ -893,6 +2619,37 @@ func TestUint64(t *testing.T) {
 	}
 }
 
+func TestSaturatingConversions(t *testing.T) {
+	tests := []struct {
+		in      float64
+		wantI64 int64
+		wantU64 uint64
+	}{
+		{1e30, math.MaxInt64, math.MaxUint64},
+		{-1e30, math.MinInt64, 0},
+		{math.Inf(1), math.MaxInt64, math.MaxUint64},
+		{math.Inf(-1), math.MinInt64, 0},
+		{-42, -42, 0},
+		{42, 42, 42},
+		{1e19, math.MaxInt64, 1e19},
+	}
+	for _, tt := range tests {
+		x := mpfr.NewFloat().SetFloat64(tt.in)
+		if got := x.Int64Saturating(); got != tt.wantI64 {
+			t.Errorf("Int64Saturating(%v) = %d; want %d", tt.in, got, tt.wantI64)
+		}
+		if got := x.Uint64Saturating(); got != tt.wantU64 {
+			t.Errorf("Uint64Saturating(%v) = %d; want %d", tt.in, got, tt.wantU64)
+		}
+	}
+
+	x := mpfr.NewFloat().SetFloat64(2.5)
+	x.SetRoundMode(mpfr.RoundUp)
+	if got := x.Int64Saturating(); got != 3 {
+		t.Errorf("Int64Saturating(2.5) with RoundUp = %d; want 3", got)
+	}
+}
+
 func TestFloat64(t *testing.T) {
 	f := mpfr.FromFloat64(math.Pi)
 	got := f.Float64()
@@ -1013,3 +2770,2358 @@ func TestMin(t *testing.T) {
 		t.Errorf("Min(1.0, 2.0, 3.0, 4.0, 5.0) = %v; want 1.0", got4.GetFloat64())
 	}
 }
+
+func TestResultPrecMode(t *testing.T) {
+	defer mpfr.SetResultPrecMode(mpfr.GetResultPrecMode())
+
+	x := mpfr.NewFloatWithPrec(200).SetFloat64(2)
+	y := mpfr.NewFloatWithPrec(120).SetFloat64(3)
+	def := mpfr.GetDefaultPrec()
+
+	if got := mpfr.Hypot(x, y, mpfr.RoundToNearest).GetPrec(); got != def {
+		t.Errorf("PrecDefault: Hypot precision = %d; want %d", got, def)
+	}
+
+	mpfr.SetResultPrecMode(mpfr.PrecMaxOperand)
+	sum := mpfr.Fmma(x, mpfr.FromInt(1), y, mpfr.FromInt(1), mpfr.RoundToNearest)
+	if sum.GetPrec() != 200 || sum.GetFloat64() != 5 {
+		t.Errorf("PrecMaxOperand: 2 + 3 = %v at %d bits; want 5 at 200 bits", sum, sum.GetPrec())
+	}
+	root := mpfr.Sqrt(x, mpfr.RoundToNearest)
+	if root.GetPrec() != 200 {
+		t.Errorf("PrecMaxOperand: Sqrt precision = %d; want 200", root.GetPrec())
+	}
+	want := mpfr.NewFloatWithPrec(200).SetFloat64(2).Sqrt()
+	if root.Cmp(want) != 0 {
+		t.Errorf("PrecMaxOperand: Sqrt(2) = %v; want %v", root, want)
+	}
+
+	mpfr.SetResultPrecMode(mpfr.PrecFixed(80))
+	if got := mpfr.Quo(x, y, mpfr.RoundToNearest).GetPrec(); got != 80 {
+		t.Errorf("PrecFixed(80): Quo precision = %d; want 80", got)
+	}
+	if got := mpfr.GetResultPrecMode(); got != mpfr.PrecFixed(80) {
+		t.Errorf("GetResultPrecMode() = %v; want PrecFixed(80)", got)
+	}
+
+	// Out-of-range precisions are clamped instead of aborting in mpfr_set_prec.
+	// (A Float of MPFR_PREC_MAX bits is too large to allocate here.)
+	if mpfr.PrecFixed(math.MaxUint) != mpfr.PrecFixed(math.MaxUint-1) {
+		t.Error("PrecFixed(MaxUint) and PrecFixed(MaxUint-1) differ; want both clamped to MPFR_PREC_MAX")
+	}
+
+	// Sum sizes its result by the mode: 1 + 2^-150 needs 151 bits, so it is exact
+	// under PrecMaxOperand with 200-bit operands and rounds to 1 at the default
+	// precision. NewFloat().Add is unaffected by the mode and always rounds.
+	a := mpfr.NewFloatWithPrec(200).SetFloat64(1)
+	b := mpfr.Pow2(-150, 200)
+	exact := mpfr.NewFloatWithPrec(200).Add(a, b)
+	mpfr.SetResultPrecMode(mpfr.PrecMaxOperand)
+	if got := mpfr.Sum(a, b, mpfr.RoundToNearest); got.GetPrec() != 200 || got.Cmp(exact) != 0 {
+		t.Errorf("PrecMaxOperand: Sum of two 200-bit Floats = %v at %d bits; want 1 + 2^-150 at 200 bits", got, got.GetPrec())
+	}
+	if got := mpfr.NewFloat().Add(a, b); got.GetPrec() != def || got.Cmp(a) != 0 {
+		t.Errorf("PrecMaxOperand: NewFloat().Add = %v at %d bits; want 1 at %d bits", got, got.GetPrec(), def)
+	}
+	mpfr.SetResultPrecMode(mpfr.PrecDefault)
+	if got := mpfr.Sum(a, b, mpfr.RoundToNearest); got.GetPrec() != def || got.Cmp(a) != 0 {
+		t.Errorf("PrecDefault: Sum of two 200-bit Floats = %v at %d bits; want 1 at %d bits", got, got.GetPrec(), def)
+	}
+}
+
+func TestWithRound(t *testing.T) {
+	f := mpfr.NewFloatWithPrec(10)
+	f.SetRoundMode(mpfr.RoundDown)
+	third := mpfr.NewFloatWithPrec(100).Quo(mpfr.FromInt(1), mpfr.FromInt(3))
+	f.WithRound(mpfr.RoundUp, func() {
+		f.Copy(third)
+	})
+	if f.RoundingMode != mpfr.RoundDown {
+		t.Errorf("RoundingMode after WithRound = %v; want RoundDown", f.RoundingMode)
+	}
+	if f.Cmp(third) <= 0 {
+		t.Errorf("Copy inside WithRound(RoundUp) = %v; want above 1/3", f)
+	}
+
+	func() {
+		defer func() { _ = recover() }()
+		f.WithRound(mpfr.RoundAway, func() { panic("boom") })
+	}()
+	if f.RoundingMode != mpfr.RoundDown {
+		t.Errorf("RoundingMode after panicking WithRound = %v; want RoundDown", f.RoundingMode)
+	}
+}
+
+func TestWithRounding(t *testing.T) {
+	// Package-level Add rounds with its rnd argument but leaves x's mode alone.
+	x := mpfr.NewFloatWithPrec(10)
+	x.SetRoundMode(mpfr.RoundDown)
+	x.SetInt(1)
+	tiny := mpfr.Pow2(-20, 53)
+	if got := mpfr.Add(x, tiny, mpfr.RoundUp); got.GetFloat64() != 1+0x1p-9 {
+		t.Errorf("Add(1, 2^-20, RoundUp) at 10 bits = %v; want 1+2^-9", got.GetFloat64())
+	}
+	if x.RoundingMode != mpfr.RoundDown {
+		t.Errorf("x.RoundingMode after Add(x, y, RoundUp) = %v; want RoundDown", x.RoundingMode)
+	}
+
+	for name, op := range map[string]func(x, y *mpfr.Float, rnd mpfr.Rnd) *mpfr.Float{
+		"Sub": mpfr.Sub, "Mul": mpfr.Mul, "Div": mpfr.Div, "MaxFloat": mpfr.MaxFloat, "MinFloat": mpfr.MinFloat,
+	} {
+		x := mpfr.FromInt(3)
+		x.SetRoundMode(mpfr.RoundToward0)
+		op(x, mpfr.FromInt(7), mpfr.RoundAway)
+		if x.RoundingMode != mpfr.RoundToward0 {
+			t.Errorf("x.RoundingMode after %s(x, y, RoundAway) = %v; want RoundToward0", name, x.RoundingMode)
+		}
+	}
+
+	// A fresh Float keeps its default mode, not rnd, once the call returns.
+	var fresh mpfr.Float
+	n := mpfr.WithRounding(&fresh, mpfr.RoundUp, func() int {
+		if fresh.RoundingMode != mpfr.RoundUp {
+			t.Errorf("RoundingMode inside WithRounding = %v; want RoundUp", fresh.RoundingMode)
+		}
+		return 42
+	})
+	if n != 42 || fresh.RoundingMode != mpfr.GetDefaultRound() {
+		t.Errorf("WithRounding = %d, mode %v; want 42, %v", n, fresh.RoundingMode, mpfr.GetDefaultRound())
+	}
+}
+
+func TestWithDefaultRound(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	outer := mpfr.GetDefaultRound()
+	mpfr.WithDefaultRound(mpfr.RoundToward0, func() {
+		if got := mpfr.NewFloat().RoundingMode; got != mpfr.RoundToward0 {
+			t.Errorf("NewFloat inside WithDefaultRound has mode %v; want RoundToward0", got)
+		}
+	})
+	if got := mpfr.GetDefaultRound(); got != outer {
+		t.Errorf("GetDefaultRound() after WithDefaultRound = %v; want %v", got, outer)
+	}
+
+	func() {
+		defer func() { _ = recover() }()
+		mpfr.WithDefaultRound(mpfr.RoundUp, func() { panic("boom") })
+	}()
+	if got := mpfr.GetDefaultRound(); got != outer {
+		t.Errorf("GetDefaultRound() after panicking WithDefaultRound = %v; want %v", got, outer)
+	}
+}
+
+func TestSetDefaultPrecClamps(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer mpfr.SaveState().Restore()
+
+	mpfr.SetDefaultPrec(0)
+	if got := mpfr.GetDefaultPrec(); got != 1 {
+		t.Errorf("GetDefaultPrec() after SetDefaultPrec(0) = %d; want 1", got)
+	}
+	mpfr.SetDefaultPrec(math.MaxUint)
+	if got := mpfr.GetDefaultPrec(); got == 0 || got == math.MaxUint {
+		t.Errorf("GetDefaultPrec() after SetDefaultPrec(MaxUint) = %d; want MPFR's maximum", got)
+	}
+	mpfr.SetDefaultPrec(200)
+	if got := mpfr.GetDefaultPrec(); got != 200 {
+		t.Errorf("GetDefaultPrec() after SetDefaultPrec(200) = %d; want 200", got)
+	}
+}
+
+func TestSaveStateRestore(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	mpfr.ClearFlags(mpfr.FlagsAll)
+	outerPrec := mpfr.GetDefaultPrec()
+	outerRnd := mpfr.GetDefaultRound()
+	outerEmin, outerEmax := mpfr.GetEmin(), mpfr.GetEmax()
+
+	func() {
+		defer mpfr.SaveState().Restore()
+		mpfr.SetDefaultPrec(outerPrec * 4)
+		mpfr.SetDefaultRound(mpfr.RoundUp)
+		if err := mpfr.SetEmax(1000); err != nil {
+			t.Fatalf("SetEmax(1000) returned error: %v", err)
+		}
+		mpfr.SetFlags(mpfr.FlagOverflow | mpfr.FlagInexact)
+		if mpfr.GetDefaultPrec() != outerPrec*4 {
+			t.Errorf("GetDefaultPrec() = %v inside block; want %v", mpfr.GetDefaultPrec(), outerPrec*4)
+		}
+		if mpfr.GetFlags()&mpfr.FlagOverflow == 0 {
+			t.Error("FlagOverflow not raised inside block")
+		}
+	}()
+
+	if got := mpfr.GetDefaultPrec(); got != outerPrec {
+		t.Errorf("GetDefaultPrec() = %v after Restore; want %v", got, outerPrec)
+	}
+	if got := mpfr.GetDefaultRound(); got != outerRnd {
+		t.Errorf("GetDefaultRound() = %v after Restore; want %v", got, outerRnd)
+	}
+	if mpfr.GetEmin() != outerEmin || mpfr.GetEmax() != outerEmax {
+		t.Errorf("exponent range = [%v, %v] after Restore; want [%v, %v]",
+			mpfr.GetEmin(), mpfr.GetEmax(), outerEmin, outerEmax)
+	}
+	if got := mpfr.GetFlags(); got != 0 {
+		t.Errorf("GetFlags() = %v after Restore; want 0", got)
+	}
+}
+
+func TestRangeChecked(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	huge := mpfr.NewFloat().SetFloat64(1)
+	huge.MulPow10(30)
+	negHuge := mpfr.NewFloat().Neg(huge)
+	tiny := mpfr.NewFloat().Exp(mpfr.FromInt(-500_000_000))
+
+	// A stale overflow from earlier work must neither leak into nor be cleared by the checks.
+	mpfr.ClearFlags(mpfr.FlagsAll)
+	mpfr.SetFlags(mpfr.FlagOverflow)
+
+	f := mpfr.NewFloat()
+	if over, under := f.AddChecked(mpfr.FromInt(1), mpfr.FromInt(2)); over || under {
+		t.Errorf("AddChecked(1, 2) = (%v, %v); want (false, false)", over, under)
+	}
+	if f.GetFloat64() != 3 {
+		t.Errorf("AddChecked(1, 2) stored %v; want 3", f)
+	}
+	if over, under := f.SubChecked(f, f); over || under {
+		t.Errorf("SubChecked(f, f) = (%v, %v); want (false, false)", over, under)
+	}
+	if over, _ := f.ExpChecked(huge); !over || !f.IsInf() {
+		t.Errorf("ExpChecked(1e30) = overflow %v, value %v; want true, +Inf", over, f)
+	}
+	if _, under := f.ExpChecked(negHuge); !under || !f.IsZero() {
+		t.Errorf("ExpChecked(-1e30) = underflow %v, value %v; want true, 0", under, f)
+	}
+	if _, under := f.MulChecked(tiny, tiny); !under {
+		t.Error("MulChecked(tiny, tiny) did not report underflow")
+	}
+	if over, under := f.QuoChecked(mpfr.FromInt(1), tiny); over || under {
+		t.Errorf("QuoChecked(1, tiny) = (%v, %v); want (false, false)", over, under)
+	}
+
+	if got := mpfr.GetFlags(); got != mpfr.FlagOverflow {
+		t.Errorf("GetFlags() = %v after checked ops; want only FlagOverflow", got)
+	}
+	mpfr.ClearFlags(mpfr.FlagsAll)
+}
+
+func TestTraps(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer mpfr.SetNaNTrap(nil)
+	defer mpfr.SetOverflowTrap(nil)
+
+	var nans, overflows int
+	mpfr.SetNaNTrap(func() { nans++ })
+	mpfr.SetOverflowTrap(func() { overflows++ })
+
+	inf, negInf := mpfr.FromFloat64(math.Inf(1)), mpfr.FromFloat64(math.Inf(-1))
+	f := mpfr.NewFloat()
+	f.AddChecked(inf, negInf)
+	if nans != 1 || overflows != 0 || !f.IsNaN() {
+		t.Errorf("AddChecked(+Inf, -Inf): %d NaN and %d overflow traps, f = %v; want 1, 0, NaN", nans, overflows, f)
+	}
+	f.AddChecked(mpfr.FromInt(1), mpfr.FromInt(2))
+	f.Add(inf, negInf)
+	if nans != 1 {
+		t.Errorf("NaN trap fired %d times; want once, not for a clean AddChecked or a plain Add", nans)
+	}
+	if err := f.SqrtChecked(mpfr.FromInt(-1)); err == nil || nans != 2 {
+		t.Errorf("SqrtChecked(-1) = %v after %d NaN traps; want ErrDomain and 2", err, nans)
+	}
+
+	huge := mpfr.Pow2(mpfr.GetEmax()-1, 53)
+	if over, _ := f.MulChecked(huge, huge); !over || overflows != 1 {
+		t.Errorf("MulChecked(huge, huge) overflow = %v after %d traps; want true and 1", over, overflows)
+	}
+
+	// A panicking hook escapes to the caller, and the caller's flags are restored.
+	mpfr.ClearFlags(mpfr.FlagsAll)
+	mpfr.SetNaNTrap(func() { panic("NaN") })
+	func() {
+		defer func() {
+			if r := recover(); r != "NaN" {
+				t.Errorf("recover() = %v; want the hook's panic", r)
+			}
+		}()
+		f.SubChecked(inf, inf)
+		t.Errorf("SubChecked(+Inf, +Inf) returned despite the panicking trap")
+	}()
+	if flags := mpfr.GetFlags(); flags&mpfr.FlagNaN != 0 {
+		t.Errorf("flags after a trapped operation = %v; want NaN flag lowered", flags)
+	}
+
+	mpfr.SetNaNTrap(nil)
+	mpfr.SetOverflowTrap(nil)
+	f.AddChecked(inf, negInf)
+	f.MulChecked(huge, huge)
+	if overflows != 1 {
+		t.Errorf("overflow trap fired after removal")
+	}
+}
+
+func TestDecomposeCompose(t *testing.T) {
+	negZero := mpfr.NewFloat().Neg(mpfr.FromInt(0))
+	posInf := mpfr.FromFloat64(math.Inf(1))
+	negInf := mpfr.FromFloat64(math.Inf(-1))
+	nan := mpfr.FromFloat64(math.NaN())
+	third := mpfr.NewFloatWithPrec(100).Quo(mpfr.FromInt(-1), mpfr.FromInt(3))
+
+	tests := []struct {
+		name     string
+		x        *mpfr.Float
+		wantNeg  bool
+		wantKind mpfr.Kind
+	}{
+		{"+0", mpfr.FromInt(0), false, mpfr.KindZero},
+		{"-0", negZero, true, mpfr.KindZero},
+		{"6.5", mpfr.FromFloat64(6.5), false, mpfr.KindFinite},
+		{"-1/3", third, true, mpfr.KindFinite},
+		{"+Inf", posInf, false, mpfr.KindInf},
+		{"-Inf", negInf, true, mpfr.KindInf},
+		{"NaN", nan, false, mpfr.KindNaN},
+	}
+
+	for _, tt := range tests {
+		neg, m, exp, kind := tt.x.Decompose()
+		if neg != tt.wantNeg || kind != tt.wantKind {
+			t.Errorf("Decompose(%s) = (neg=%v, kind=%v); want (neg=%v, kind=%v)", tt.name, neg, kind, tt.wantNeg, tt.wantKind)
+		}
+		if m != nil && m.Sign() < 0 {
+			t.Errorf("Decompose(%s) mantissa = %v; want non-negative", tt.name, m)
+		}
+
+		var y *mpfr.Float
+		if kind == mpfr.KindInf || kind == mpfr.KindNaN {
+			y = mpfr.ComposeSpecial(neg, kind, 100)
+		} else {
+			y = mpfr.Compose(neg, m, exp, 100)
+		}
+		switch kind {
+		case mpfr.KindNaN:
+			if !y.IsNaN() {
+				t.Errorf("Compose(Decompose(%s)) = %v; want NaN", tt.name, y)
+			}
+		default:
+			if y.Cmp(tt.x) != 0 || y.Signbit() != tt.x.Signbit() {
+				t.Errorf("Compose(Decompose(%s)) = %v; want %v", tt.name, y, tt.x)
+			}
+		}
+	}
+
+	// 6.5 = 13 * 2^-1 with a 53-bit mantissa.
+	_, m, exp, _ := mpfr.FromFloat64(6.5).Decompose()
+	got := new(big.Float).SetMantExp(new(big.Float).SetInt(m), exp)
+	if f, _ := got.Float64(); f != 6.5 {
+		t.Errorf("Decompose(6.5) = %v * 2^%v = %v; want 6.5", m, exp, f)
+	}
+
+	for _, neg := range []bool{false, true} {
+		if z := mpfr.ComposeSpecial(neg, mpfr.KindZero, 53); !z.IsZero() || z.Signbit() != neg {
+			t.Errorf("ComposeSpecial(%v, KindZero) = %v; want a zero with signbit %v", neg, z, neg)
+		}
+		if z := mpfr.Compose(neg, nil, 0, 53); !z.IsZero() || z.Signbit() != neg {
+			t.Errorf("Compose(%v, nil, 0) = %v; want a zero with signbit %v", neg, z, neg)
+		}
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("ComposeSpecial(KindFinite) did not panic")
+			}
+		}()
+		mpfr.ComposeSpecial(false, mpfr.KindFinite, 53)
+	}()
+}
+
+func TestScientificParts(t *testing.T) {
+	tests := []struct {
+		x        float64
+		base     int
+		mantissa float64
+		exp      int
+	}{
+		{12345, 10, 1.2345, 4},
+		{-0.00123, 10, -1.23, -3},
+		{1000, 10, 1, 3},
+		{999.5, 10, 9.995, 2},
+		// The double nearest 0.1 is slightly above it; its mantissa rounds to 1.
+		{0.1, 10, 1, -1},
+		{1e300, 10, 1, 300},
+		{12, 2, 1.5, 3},
+		{255, 16, 15.9375, 1},
+		{1.0 / 64, 8, 1, -2},
+	}
+	for _, tc := range tests {
+		x := mpfr.NewFloatWithPrec(53).SetFloat64(tc.x)
+		m, e := x.ScientificParts(tc.base)
+		if m.GetFloat64() != tc.mantissa || e != tc.exp {
+			t.Errorf("ScientificParts(%v, %d) = (%v, %d); want (%v, %d)", tc.x, tc.base, m.GetFloat64(), e, tc.mantissa, tc.exp)
+		}
+		// m · base^e reassembles x.
+		back := mpfr.NewFloatWithPrec(200).Copy(m)
+		for i := 0; i < e; i++ {
+			back.Mul(mpfr.FromInt(tc.base))
+		}
+		for i := 0; i > e; i-- {
+			back.Quo(back, mpfr.FromInt(tc.base))
+		}
+		if rel := math.Abs(back.GetFloat64()-tc.x) / math.Abs(tc.x); rel > 1e-15 {
+			t.Errorf("ScientificParts(%v, %d) reassembles to %v", tc.x, tc.base, back.GetFloat64())
+		}
+	}
+
+	// 100 - 2⁻¹³ is below 100, but at 20 bits its tenth rounds up to 10 itself,
+	// which moves to the next exponent.
+	x := mpfr.NewFloatWithPrec(20).SetFloat64(100 - 1.0/8192)
+	x.SetRoundMode(mpfr.RoundUp)
+	if m, e := x.ScientificParts(10); m.GetFloat64() != 1 || e != 2 {
+		t.Errorf("ScientificParts(100 - 2⁻¹³) = (%v, %d); want (1, 2)", m, e)
+	}
+	x.SetRoundMode(mpfr.RoundToNearest)
+	if m, e := x.ScientificParts(10); m.GetFloat64() >= 10 || e != 1 {
+		t.Errorf("ScientificParts(100 - 2⁻¹³) to nearest = (%v, %d); want (9.99998…, 1)", m, e)
+	}
+
+	if m, e := mpfr.NewFloat().SetZero(-1).ScientificParts(10); !m.IsNegZero() || e != 0 {
+		t.Errorf("ScientificParts(-0) = (%v, %d); want (-0, 0)", m, e)
+	}
+	if m, e := mpfr.FromFloat64(math.Inf(1)).ScientificParts(10); !m.IsInf() || e != 0 {
+		t.Errorf("ScientificParts(+Inf) = (%v, %d); want (+Inf, 0)", m, e)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("ScientificParts(1) did not panic")
+		}
+	}()
+	mpfr.FromInt(5).ScientificParts(1)
+}
+
+func TestFloorLogB(t *testing.T) {
+	for _, tc := range []struct {
+		x    string
+		base uint
+		want int
+	}{
+		{"1000", 10, 3},
+		{"999", 10, 2},
+		{"1001", 10, 3},
+		{"8", 2, 3},
+		{"7.99", 2, 2},
+		{"1", 7, 0},
+		{"0.001", 10, -3},
+		{"0.00099", 10, -4},
+		{"1e300", 10, 300},
+		{"243", 3, 5},
+		{"242", 3, 4},
+	} {
+		// 2000 bits keep decimal inputs like 0.001 just above or below the power as written.
+		x := mpfr.MustParse(tc.x, 2000)
+		if got := x.FloorLogB(tc.base); got != tc.want {
+			t.Errorf("FloorLogB(%s, %d) = %d; want %d", tc.x, tc.base, got, tc.want)
+		}
+	}
+
+	// Exact powers of ten far beyond float64 range.
+	p := mpfr.NewFloatWithPrec(4000)
+	p.SetInt64(10)
+	p.Pow(p, mpfr.FromInt(1000))
+	if got := p.FloorLogB(10); got != 1000 {
+		t.Errorf("FloorLogB(10^1000, 10) = %d; want 1000", got)
+	}
+	p.Sub(mpfr.FromInt(1))
+	if got := p.FloorLogB(10); got != 999 {
+		t.Errorf("FloorLogB(10^1000-1, 10) = %d; want 999", got)
+	}
+
+	for _, v := range []float64{0, -5, math.NaN()} {
+		if got := mpfr.FromFloat64(v).FloorLogB(10); got != math.MinInt {
+			t.Errorf("FloorLogB(%v, 10) = %d; want math.MinInt", v, got)
+		}
+	}
+	if got := mpfr.FromFloat64(math.Inf(1)).FloorLogB(10); got != math.MaxInt {
+		t.Errorf("FloorLogB(+Inf, 10) = %d; want math.MaxInt", got)
+	}
+}
+
+func TestIsInteger(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want bool
+	}{
+		{4.0, true},
+		{-4.0, true},
+		{0, true},
+		{1e300, true},
+		{4.5, false},
+		{1e-300, false},
+		{math.Inf(1), false},
+		{math.Inf(-1), false},
+		{math.NaN(), false},
+	}
+	for _, tt := range tests {
+		if got := mpfr.NewFloat().SetFloat64(tt.in).IsInteger(); got != tt.want {
+			t.Errorf("IsInteger(%v) = %v; want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestIsPowerOfTwo(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want bool
+	}{
+		{0.25, true},
+		{1, true},
+		{8, true},
+		{-8, true},
+		{0x1p-1074, true},
+		{0x1p1023, true},
+		{3, false},
+		{0, false},
+		{0.1, false},
+		{6, false},
+		{math.Inf(1), false},
+		{math.NaN(), false},
+	}
+	for _, tt := range tests {
+		if got := mpfr.NewFloat().SetFloat64(tt.in).IsPowerOfTwo(); got != tt.want {
+			t.Errorf("IsPowerOfTwo(%v) = %v; want %v", tt.in, got, tt.want)
+		}
+	}
+	// 2^100000 is far outside float64 range.
+	if !mpfr.Pow2(100000, 53).IsPowerOfTwo() {
+		t.Error("IsPowerOfTwo(2^100000) = false; want true")
+	}
+}
+
+func TestSnapToZero(t *testing.T) {
+	threshold := mpfr.NewFloat().SetFloat64(1e-30)
+	if got := mpfr.NewFloat().SetFloat64(1e-40).SnapToZero(threshold); !got.IsPosZero() {
+		t.Errorf("SnapToZero(1e-40) = %v; want +0", got.GetFloat64())
+	}
+	if got := mpfr.NewFloat().SetFloat64(-1e-40).SnapToZero(threshold); !got.IsPosZero() {
+		t.Errorf("SnapToZero(-1e-40) = %v; want +0", got.GetFloat64())
+	}
+	if got := mpfr.NewFloat().SetFloat64(1e-20).SnapToZero(threshold).GetFloat64(); got != 1e-20 {
+		t.Errorf("SnapToZero(1e-20) = %v; want 1e-20", got)
+	}
+	if got := mpfr.NewFloat().SetFloat64(1e-30).SnapToZero(threshold); !got.IsZero() {
+		t.Errorf("SnapToZero(1e-30) = %v; want 0 at the threshold", got.GetFloat64())
+	}
+	if got := mpfr.NewFloat().SetFloat64(math.NaN()).SnapToZero(threshold); !got.IsNaN() {
+		t.Errorf("SnapToZero(NaN) = %v; want NaN", got.GetFloat64())
+	}
+	if got := mpfr.FromInt(1).SnapToZero(mpfr.FromFloat64(math.NaN())).GetFloat64(); got != 1 {
+		t.Errorf("SnapToZero(1) with NaN threshold = %v; want 1", got)
+	}
+
+	for _, neg := range []bool{false, true} {
+		x := 1e-40
+		if neg {
+			x = -x
+		}
+		if got := mpfr.NewFloat().SetFloat64(x).SnapToZeroSigned(threshold); !got.IsZero() || got.Signbit() != neg {
+			t.Errorf("SnapToZeroSigned(%v) = %v (signbit %v); want a zero with signbit %v", x, got.GetFloat64(), got.Signbit(), neg)
+		}
+		if got := mpfr.NewFloat().SetFloat64(1e3 * x).SnapToZeroSigned(mpfr.FromFloat64(1e-40)).GetFloat64(); got != 1e3*x {
+			t.Errorf("SnapToZeroSigned(%v) = %v; want it unchanged above the threshold", 1e3*x, got)
+		}
+	}
+}
+
+func TestFlushSubnormals(t *testing.T) {
+	defer mpfr.SaveState().Restore()
+	if err := mpfr.SetEmin(-100); err != nil {
+		t.Fatalf("SetEmin(-100): %v", err)
+	}
+
+	// At 53 bits exponents below -100 + 52 are subnormal, so 2^-49 = 0.5·2^-48 is
+	// normal and 2^-50 is not.
+	if got := mpfr.Pow2(-49, 53).FlushSubnormals().GetFloat64(); got != 0x1p-49 {
+		t.Errorf("FlushSubnormals(2^-49) = %v; want 2^-49", got)
+	}
+	if got := mpfr.Pow2(-50, 53).FlushSubnormals(); !got.IsPosZero() {
+		t.Errorf("FlushSubnormals(2^-50) = %v; want +0", got.GetFloat64())
+	}
+	if got := mpfr.Pow2(-60, 53).Neg().FlushSubnormals(); !got.IsNegZero() {
+		t.Errorf("FlushSubnormals(-2^-60) = %v; want -0", got.GetFloat64())
+	}
+	// With one bit of precision every value down to the smallest exponent is normal.
+	if got := mpfr.Pow2(-60, 1).FlushSubnormals().GetFloat64(); got != 0x1p-60 {
+		t.Errorf("FlushSubnormals(2^-60) at 1 bit = %v; want 2^-60", got)
+	}
+	if got := mpfr.FromInt(3).FlushSubnormals().GetFloat64(); got != 3 {
+		t.Errorf("FlushSubnormals(3) = %v; want 3", got)
+	}
+}
+
+func TestSignPredicates(t *testing.T) {
+	tests := []struct {
+		name             string
+		x                *mpfr.Float
+		sign             int
+		pos, neg, nonNeg bool
+	}{
+		{"-Inf", mpfr.FromFloat64(math.Inf(-1)), -1, false, true, false},
+		{"-2.5", mpfr.FromFloat64(-2.5), -1, false, true, false},
+		{"-0", mpfr.FromFloat64(math.Copysign(0, -1)), 0, false, false, true},
+		{"+0", mpfr.FromFloat64(0), 0, false, false, true},
+		{"2.5", mpfr.FromFloat64(2.5), 1, true, false, true},
+		{"+Inf", mpfr.FromFloat64(math.Inf(1)), 1, true, false, true},
+		{"NaN", mpfr.FromFloat64(math.NaN()), 0, false, false, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.x.Sign(); got != tt.sign {
+			t.Errorf("Sign(%s) = %v; want %v", tt.name, got, tt.sign)
+		}
+		if got := tt.x.IsPositive(); got != tt.pos {
+			t.Errorf("IsPositive(%s) = %v; want %v", tt.name, got, tt.pos)
+		}
+		if got := tt.x.IsNegative(); got != tt.neg {
+			t.Errorf("IsNegative(%s) = %v; want %v", tt.name, got, tt.neg)
+		}
+		if got := tt.x.IsNonNegative(); got != tt.nonNeg {
+			t.Errorf("IsNonNegative(%s) = %v; want %v", tt.name, got, tt.nonNeg)
+		}
+	}
+}
+
+func TestSameSign(t *testing.T) {
+	f := mpfr.FromFloat64
+	negZero := math.Copysign(0, -1)
+	tests := []struct {
+		x, y float64
+		want bool
+	}{
+		{-3, -0.1, true},
+		{-3, 2, false},
+		{2, 1e-300, true},
+		{math.Inf(1), 5, true},
+		{math.Inf(-1), -5, true},
+		// Zeros and NaN have no sign to share, even with each other.
+		{0, 0, false},
+		{negZero, negZero, false},
+		{negZero, -1, false},
+		{0, 1, false},
+		{math.NaN(), 1, false},
+		{math.NaN(), math.NaN(), false},
+	}
+	for _, tc := range tests {
+		if got := mpfr.SameSign(f(tc.x), f(tc.y)); got != tc.want {
+			t.Errorf("SameSign(%v, %v) = %v; want %v", tc.x, tc.y, got, tc.want)
+		}
+		if got := mpfr.SameSign(f(tc.y), f(tc.x)); got != tc.want {
+			t.Errorf("SameSign(%v, %v) = %v; want %v", tc.y, tc.x, got, tc.want)
+		}
+	}
+
+	// The product of these underflows to zero, but the signs still agree.
+	tiny := mpfr.NewFloat().SetPow2(-1 << 29)
+	if !mpfr.SameSign(tiny, tiny) {
+		t.Errorf("SameSign(2^-2²⁹, 2^-2²⁹) = false; want true")
+	}
+}
+
+func TestSignedZero(t *testing.T) {
+	neg := mpfr.NewFloat().SetZero(-1)
+	if !neg.IsNegZero() || neg.IsPosZero() {
+		t.Errorf("SetZero(-1): IsNegZero = %v, IsPosZero = %v; want true, false", neg.IsNegZero(), neg.IsPosZero())
+	}
+	pos := mpfr.NewFloat().SetFloat64(0)
+	if !pos.IsPosZero() || pos.IsNegZero() {
+		t.Errorf("SetFloat64(0): IsPosZero = %v, IsNegZero = %v; want true, false", pos.IsPosZero(), pos.IsNegZero())
+	}
+	if neg.Cmp(pos) != 0 {
+		t.Errorf("Cmp(-0, +0) = %d; want 0", neg.Cmp(pos))
+	}
+	if !mpfr.NewFloat().SetFloat64(math.Copysign(0, -1)).IsNegZero() {
+		t.Error("SetFloat64(-0).IsNegZero() = false; want true")
+	}
+	for _, v := range []float64{1, -1, math.Inf(-1), math.NaN()} {
+		x := mpfr.NewFloat().SetFloat64(v)
+		if x.IsNegZero() || x.IsPosZero() {
+			t.Errorf("%v: IsNegZero = %v, IsPosZero = %v; want false, false", v, x.IsNegZero(), x.IsPosZero())
+		}
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	x := mpfr.NewFloatWithPrec(53).SetFloat64(1.5)
+	below, above := x.Neighbors()
+
+	if got := x.GetFloat64(); got != 1.5 {
+		t.Errorf("Neighbors modified the receiver: got %v; want 1.5", got)
+	}
+	if got, want := below.GetFloat64(), math.Nextafter(1.5, 0); got != want {
+		t.Errorf("Neighbors(1.5) below = %v; want %v", got, want)
+	}
+	if got, want := above.GetFloat64(), math.Nextafter(1.5, 2); got != want {
+		t.Errorf("Neighbors(1.5) above = %v; want %v", got, want)
+	}
+
+	// For a 53-bit value in [1, 2) one ulp is 2^-52.
+	gap := mpfr.NewFloatWithPrec(53).Copy(above).Sub(below)
+	if got, want := gap.GetFloat64(), 2*math.Ldexp(1, -52); got != want {
+		t.Errorf("above - below = %v; want 2 ulp = %v", got, want)
+	}
+}
+
+func TestEqualULP(t *testing.T) {
+	for _, prec := range []uint{2, 24, 53, 300} {
+		x := mpfr.NewFloatWithPrec(prec).SetFloat64(1)
+		x.NextBelow() // straddle the binade boundary at 1
+		next := mpfr.NewFloatWithPrec(prec).Copy(x).NextAbove()
+		twoAway := mpfr.NewFloatWithPrec(prec).Copy(next).NextAbove()
+
+		if !x.EqualULP(x, 0) {
+			t.Errorf("prec %d: x not within 0 ULP of itself", prec)
+		}
+		if !x.EqualULP(next, 1) || !next.EqualULP(x, 1) {
+			t.Errorf("prec %d: adjacent values %v, %v not within 1 ULP", prec, x, next)
+		}
+		if x.EqualULP(next, 0) {
+			t.Errorf("prec %d: adjacent values within 0 ULP", prec)
+		}
+		if x.EqualULP(twoAway, 1) {
+			t.Errorf("prec %d: %v and %v within 1 ULP", prec, x, twoAway)
+		}
+		if !x.EqualULP(twoAway, 2) {
+			t.Errorf("prec %d: %v and %v not within 2 ULP", prec, x, twoAway)
+		}
+	}
+
+	zero, negZero := mpfr.NewFloat().SetZero(1), mpfr.NewFloat().SetZero(-1)
+	tiny := mpfr.NewFloat().SetZero(1).NextAbove()
+	negTiny := mpfr.NewFloat().Neg(tiny)
+	if !zero.EqualULP(negZero, 0) {
+		t.Error("+0 and -0 not within 0 ULP")
+	}
+	if !zero.EqualULP(tiny, 1) || !negZero.EqualULP(negTiny, 1) {
+		t.Error("smallest nonzero values not within 1 ULP of zero")
+	}
+	if tiny.EqualULP(negTiny, 1) || !tiny.EqualULP(negTiny, 2) {
+		t.Error("±smallest values should be exactly 2 ULP apart")
+	}
+
+	nan, inf := mpfr.NewFloat().SetFloat64(math.NaN()), mpfr.NewFloat().SetFloat64(math.Inf(1))
+	if nan.EqualULP(nan, 100) || !inf.EqualULP(inf, 0) || inf.EqualULP(mpfr.FromInt(1), 1<<30) {
+		t.Error("NaN or Inf handled incorrectly")
+	}
+}
+
+func TestRemquo64(t *testing.T) {
+	tests := []struct {
+		x, y  float64
+		wantQ int64
+		wantR float64
+	}{
+		{10, 3, 3, 1},
+		{-10, 3, -3, -1},
+		{10, -3, -3, 1},
+		{11, 2, 6, -1}, // 5.5 rounds to the even quotient 6
+		{9, 2, 4, 1},   // 4.5 rounds to the even quotient 4
+	}
+
+	for _, tt := range tests {
+		q, r := mpfr.NewFloat().Remquo64(mpfr.FromFloat64(tt.x), mpfr.FromFloat64(tt.y))
+		if q != tt.wantQ || r.GetFloat64() != tt.wantR {
+			t.Errorf("Remquo64(%v, %v) = (%v, %v); want (%v, %v)", tt.x, tt.y, q, r.GetFloat64(), tt.wantQ, tt.wantR)
+		}
+	}
+
+	// Only the low bits of a huge quotient survive: n = 2^70 + 5 leaves 5.
+	x := mpfr.NewFloatWithPrec(128)
+	if err := x.SetString("1180591620717411303429", 10); err != nil { // 2^70 + 5
+		t.Fatalf("SetString returned error: %v", err)
+	}
+	q, r := mpfr.NewFloatWithPrec(128).Remquo64(x, mpfr.FromInt(1))
+	if q != 5 || !r.IsZero() {
+		t.Errorf("Remquo64(2^70+5, 1) = (%v, %v); want (5, 0)", q, r.GetFloat64())
+	}
+}
+
+func TestFmmaFmms(t *testing.T) {
+	a, b := mpfr.FromInt(2), mpfr.FromInt(3)
+	c, d := mpfr.FromInt(4), mpfr.FromInt(5)
+	if got := mpfr.Fmma(a, b, c, d, mpfr.RoundToNearest).GetFloat64(); got != 26 {
+		t.Errorf("Fmma(2, 3, 4, 5) = %v; want 26", got)
+	}
+	if got := mpfr.Fmms(a, b, c, d, mpfr.RoundToNearest).GetFloat64(); got != -14 {
+		t.Errorf("Fmms(2, 3, 4, 5) = %v; want -14", got)
+	}
+	if a.GetFloat64() != 2 || d.GetFloat64() != 5 {
+		t.Error("Fmma/Fmms modified their operands")
+	}
+}
+
+func TestCross2(t *testing.T) {
+	tests := []struct {
+		name           string
+		ax, ay, bx, by float64
+		want           float64
+	}{
+		{"perpendicular", 1, 0, 0, 1, 1},
+		{"perpendicular reversed", 0, 1, 1, 0, -1},
+		{"parallel", 2, 4, 1, 2, 0},
+		{"anti-parallel", 2, 4, -1, -2, 0},
+		// float64 evaluation of these products cancels to 0; the exact answer is 2^-104.
+		{"near parallel", 1 + 0x1p-52, 1, 1, 1 - 0x1p-52, -0x1p-104},
+	}
+
+	for _, tt := range tests {
+		got := mpfr.Cross2(mpfr.FromFloat64(tt.ax), mpfr.FromFloat64(tt.ay),
+			mpfr.FromFloat64(tt.bx), mpfr.FromFloat64(tt.by), mpfr.RoundToNearest)
+		if got.GetFloat64() != tt.want {
+			t.Errorf("Cross2(%s) = %v; want %v", tt.name, got.GetFloat64(), tt.want)
+		}
+	}
+}
+
+func TestTwoSumTwoProduct(t *testing.T) {
+	wide := func(x *mpfr.Float) *mpfr.Float { return mpfr.NewFloatWithPrec(4000).Copy(x) }
+	pairs := [][2]*mpfr.Float{
+		{mpfr.FromInt(1), mpfr.FromFloat64(0x1p-60)},
+		{mpfr.FromFloat64(0.1), mpfr.FromFloat64(0.2)},
+		{mpfr.FromFloat64(1e300), mpfr.FromFloat64(-1e-300)},
+		{mpfr.FromFloat64(1 + 0x1p-52), mpfr.FromFloat64(1 - 0x1p-52)},
+		{mpfr.FromFloat64(-3.75), mpfr.FromFloat64(3.75)},
+		{mpfr.MustParse("3.14159265358979323846264338327950288", 120), mpfr.FromFloat64(2.718281828459045)},
+	}
+	for _, pr := range pairs {
+		a, b := pr[0], pr[1]
+
+		s, err := mpfr.TwoSum(a, b)
+		if got, want := wide(s).Add(err), wide(a).Add(b); got.Cmp(want) != 0 {
+			t.Errorf("TwoSum(%v, %v): s + err != a + b", a.GetFloat64(), b.GetFloat64())
+		}
+		if want := mpfr.NewFloatWithPrec(s.GetPrec()).Copy(a).Add(b); s.Cmp(want) != 0 {
+			t.Errorf("TwoSum(%v, %v): s is not the rounded sum", a.GetFloat64(), b.GetFloat64())
+		}
+
+		p, perr := mpfr.TwoProduct(a, b)
+		if got, want := wide(p).Add(perr), wide(a).Mul(b); got.Cmp(want) != 0 {
+			t.Errorf("TwoProduct(%v, %v): p + err != a * b", a.GetFloat64(), b.GetFloat64())
+		}
+		if want := mpfr.NewFloatWithPrec(p.GetPrec()).Copy(a).Mul(b); p.Cmp(want) != 0 {
+			t.Errorf("TwoProduct(%v, %v): p is not the rounded product", a.GetFloat64(), b.GetFloat64())
+		}
+	}
+
+	// 1 + 2^-60 rounds to 1 at 53 bits; the lost part comes back as the error term.
+	s, err := mpfr.TwoSum(mpfr.FromInt(1), mpfr.FromFloat64(0x1p-60))
+	if s.GetFloat64() != 1 || err.GetFloat64() != 0x1p-60 {
+		t.Errorf("TwoSum(1, 2^-60) = (%v, %v); want (1, 2^-60)", s.GetFloat64(), err.GetFloat64())
+	}
+	// (1 + 2^-52)^2 = 1 + 2^-51 + 2^-104.
+	x := mpfr.FromFloat64(1 + 0x1p-52)
+	p, err := mpfr.TwoProduct(x, x)
+	if p.GetFloat64() != 1+0x1p-51 || err.GetFloat64() != 0x1p-104 {
+		t.Errorf("TwoProduct(1+2^-52, 1+2^-52) = (%v, %v); want (1+2^-51, 2^-104)", p.GetFloat64(), err.GetFloat64())
+	}
+
+	if _, err := mpfr.TwoSum(mpfr.FromFloat64(math.Inf(1)), mpfr.FromInt(1)); !err.IsNaN() {
+		t.Errorf("TwoSum(+Inf, 1) err = %v; want NaN", err.GetFloat64())
+	}
+}
+
+func TestOrient2D(t *testing.T) {
+	tests := []struct {
+		name                   string
+		ax, ay, bx, by, cx, cy float64
+		want                   int
+	}{
+		{"counter-clockwise", 0, 0, 1, 0, 0, 1, 1},
+		{"clockwise", 0, 0, 0, 1, 1, 0, -1},
+		{"collinear", 0.5, 0.5, 12, 12, 24, 24, 0},
+		{"collinear far apart", -1e300, -1e300, 1e-300, 1e-300, 1e300, 1e300, 0},
+		// float64 evaluation of the determinant rounds these to 0 and -1 respectively.
+		{"near collinear, float64 says 0", 0.5, 0.5000000000000001, 12, 12, 24, 24, 1},
+		{"near collinear, float64 says -1", 0.5000000000000046, 0.5000000000000053, 12, 12, 24, 24, 1},
+	}
+
+	for _, tt := range tests {
+		if got := mpfr.Orient2D(tt.ax, tt.ay, tt.bx, tt.by, tt.cx, tt.cy); got != tt.want {
+			t.Errorf("Orient2D(%s) = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSinhCoshTanh(t *testing.T) {
+	for _, v := range []float64{0, 1, 20} {
+		x := mpfr.FromFloat64(v)
+		sinh, cosh, tanh := mpfr.NewFloat().SinhCoshTanh(x)
+
+		if got, want := sinh.GetFloat64(), math.Sinh(v); math.Abs(got-want) > 1e-15*math.Max(1, math.Abs(want)) {
+			t.Errorf("SinhCoshTanh(%v) sinh = %v; want %v", v, got, want)
+		}
+		if got, want := cosh.GetFloat64(), mpfr.Cosh(x, mpfr.RoundToNearest).GetFloat64(); got != want {
+			t.Errorf("SinhCoshTanh(%v) cosh = %v; want %v", v, got, want)
+		}
+		if got, want := tanh.GetFloat64(), mpfr.Tanh(x, mpfr.RoundToNearest).GetFloat64(); !almostEqual(got, want) {
+			t.Errorf("SinhCoshTanh(%v) tanh = %v; want %v", v, got, want)
+		}
+	}
+
+	// cosh overflows long before tanh stops being representable.
+	huge := mpfr.NewFloat()
+	if err := huge.SetString("1e100", 10); err != nil {
+		t.Fatalf("SetString returned error: %v", err)
+	}
+	_, _, tanh := mpfr.NewFloat().SinhCoshTanh(huge)
+	if got := tanh.GetFloat64(); got != 1 {
+		t.Errorf("SinhCoshTanh(1e100) tanh = %v; want 1", got)
+	}
+}
+
+func TestSigmoid(t *testing.T) {
+	if got := mpfr.Sigmoid(mpfr.FromInt(0), mpfr.RoundToNearest).GetFloat64(); got != 0.5 {
+		t.Errorf("Sigmoid(0) = %v; want 0.5", got)
+	}
+	if got := mpfr.Sigmoid(mpfr.FromInt(1000), mpfr.RoundToNearest).GetFloat64(); got != 1 {
+		t.Errorf("Sigmoid(1000) = %v; want 1", got)
+	}
+
+	// e^-1000 is far below float64 range but well inside MPFR's exponent range.
+	tiny := mpfr.Exp(mpfr.FromInt(-1000), mpfr.RoundToNearest)
+	low := mpfr.Sigmoid(mpfr.FromInt(-1000), mpfr.RoundToNearest)
+	if low.IsNaN() || low.Sign() <= 0 || !low.EqualULP(tiny, 1) {
+		t.Errorf("Sigmoid(-1000) = %v; want about e^-1000", low.CompactString())
+	}
+
+	for _, v := range []float64{-30, -2.5, -0.1, 0.1, 2.5, 30} {
+		want := 1 / (1 + math.Exp(-v))
+		if got := mpfr.Sigmoid(mpfr.FromFloat64(v), mpfr.RoundToNearest).GetFloat64(); !almostEqual(got, want) {
+			t.Errorf("Sigmoid(%v) = %v; want %v", v, got, want)
+		}
+		wantLog := math.Log(want)
+		if got := mpfr.LogSigmoid(mpfr.FromFloat64(v), mpfr.RoundToNearest).GetFloat64(); math.Abs(got-wantLog) > 1e-14*math.Max(1, math.Abs(wantLog)) {
+			t.Errorf("LogSigmoid(%v) = %v; want %v", v, got, wantLog)
+		}
+	}
+
+	if got := mpfr.LogSigmoid(mpfr.FromInt(-1000), mpfr.RoundToNearest).GetFloat64(); got != -1000 {
+		t.Errorf("LogSigmoid(-1000) = %v; want -1000", got)
+	}
+	hi := mpfr.LogSigmoid(mpfr.FromInt(1000), mpfr.RoundToNearest)
+	if !hi.Signbit() || !hi.Neg(hi).EqualULP(tiny, 1) {
+		t.Errorf("LogSigmoid(1000) = %v; want about -e^-1000", hi.CompactString())
+	}
+
+	posInf, negInf := mpfr.FromFloat64(math.Inf(1)), mpfr.FromFloat64(math.Inf(-1))
+	if got := mpfr.Sigmoid(posInf, mpfr.RoundToNearest).GetFloat64(); got != 1 {
+		t.Errorf("Sigmoid(+Inf) = %v; want 1", got)
+	}
+	if got := mpfr.Sigmoid(negInf, mpfr.RoundToNearest).GetFloat64(); got != 0 {
+		t.Errorf("Sigmoid(-Inf) = %v; want 0", got)
+	}
+	if got := mpfr.LogSigmoid(negInf, mpfr.RoundToNearest).GetFloat64(); !math.IsInf(got, -1) {
+		t.Errorf("LogSigmoid(-Inf) = %v; want -Inf", got)
+	}
+	if !mpfr.Sigmoid(mpfr.FromFloat64(math.NaN()), mpfr.RoundToNearest).IsNaN() {
+		t.Error("Sigmoid(NaN) is not NaN")
+	}
+}
+
+func TestFutureValue(t *testing.T) {
+	const periods = 1000000
+
+	rate := mpfr.NewFloatWithPrec(200)
+	if err := rate.SetString("1e-9", 10); err != nil {
+		t.Fatalf("SetString returned error: %v", err)
+	}
+	got := mpfr.FutureValue(mpfr.FromInt(1000), rate, periods, 200, mpfr.RoundToNearest)
+
+	// Reference: 1000 * (1 + 1e-9)^1e6 with the base formed exactly at 2000 bits.
+	base := mpfr.NewFloatWithPrec(2000)
+	if err := base.SetString("1.000000001", 10); err != nil {
+		t.Fatalf("SetString returned error: %v", err)
+	}
+	ref := mpfr.NewFloatWithPrec(2000).Pow(base, mpfr.FromInt(periods))
+	ref.Mul(mpfr.FromInt(1000))
+
+	if g, r := got.StringN(50), ref.StringN(50); g != r {
+		t.Errorf("FutureValue(1000, 1e-9, 1e6) = %s; want %s", g, r)
+	}
+
+	// Naive float64 evaluation loses about ten digits to the rounding of 1 + 1e-9.
+	naive := 1000 * math.Pow(1+1e-9, periods)
+	refF := ref.GetFloat64()
+	if math.Abs(naive-refF)/refF < 1e-12 {
+		t.Errorf("naive float64 result %v unexpectedly matches reference %v", naive, refF)
+	}
+
+	if got := mpfr.FutureValue(mpfr.FromInt(5), rate, 0, 53, mpfr.RoundToNearest).GetFloat64(); got != 5 {
+		t.Errorf("FutureValue(5, r, 0) = %v; want 5", got)
+	}
+}
+
+func TestToBigFloat(t *testing.T) {
+	x := mpfr.NewFloatWithPrec(256).Quo(mpfr.FromInt(1), mpfr.FromInt(3))
+
+	z, acc := x.ToBigFloat(256)
+	if acc != big.Exact {
+		t.Errorf("ToBigFloat(256) accuracy = %v; want Exact", acc)
+	}
+	if back := mpfr.NewFloatWithPrec(256).SetBigFloat(z); back.Cmp(x) != 0 {
+		t.Errorf("ToBigFloat(256) = %v; want %v", z.Text('g', 80), x)
+	}
+	if z.Prec() != 256 {
+		t.Errorf("ToBigFloat(256) precision = %v; want 256", z.Prec())
+	}
+
+	// 1/3 at 53 bits rounds down to nearest, and up under RoundUp.
+	z, acc = x.ToBigFloat(53)
+	if acc != big.Below {
+		t.Errorf("ToBigFloat(53) of 1/3 accuracy = %v; want Below", acc)
+	}
+	if f, _ := z.Float64(); f != 1.0/3 {
+		t.Errorf("ToBigFloat(53) of 1/3 = %v; want %v", f, 1.0/3)
+	}
+	x.SetRoundMode(mpfr.RoundUp)
+	if _, acc = x.ToBigFloat(53); acc != big.Above {
+		t.Errorf("ToBigFloat(53) of 1/3 with RoundUp accuracy = %v; want Above", acc)
+	}
+
+	if z, _ := mpfr.FromFloat64(math.Inf(-1)).ToBigFloat(53); !z.IsInf() || !z.Signbit() {
+		t.Errorf("ToBigFloat(-Inf) = %v; want -Inf", z)
+	}
+	if z, _ := mpfr.FromFloat64(math.NaN()).ToBigFloat(53); z != nil {
+		t.Errorf("ToBigFloat(NaN) = %v; want nil", z)
+	}
+}
+
+func TestInPlaceUnary(t *testing.T) {
+	for _, v := range []float64{-2.5, 0, 2, 1e-300, 12345.678} {
+		if got, want := mpfr.FromFloat64(v).AbsInPlace(), mpfr.FromFloat64(v).Abs(); got.Cmp(want) != 0 {
+			t.Errorf("AbsInPlace(%v) = %v; want %v", v, got, want)
+		}
+		if got, want := mpfr.FromFloat64(v).NegInPlace(), mpfr.FromFloat64(v).Neg(); got.Cmp(want) != 0 {
+			t.Errorf("NegInPlace(%v) = %v; want %v", v, got, want)
+		}
+		if v >= 0 {
+			if got, want := mpfr.FromFloat64(v).SqrtInPlace(), mpfr.FromFloat64(v).Sqrt(); got.Cmp(want) != 0 {
+				t.Errorf("SqrtInPlace(%v) = %v; want %v", v, got, want)
+			}
+		}
+	}
+}
+
+func BenchmarkAbsVariadic(b *testing.B) {
+	x := mpfr.FromFloat64(-1.5)
+	f := mpfr.NewFloat()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		f.Abs(x)
+	}
+}
+
+func BenchmarkAbsInPlace(b *testing.B) {
+	f := mpfr.FromFloat64(-1.5)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		f.AbsInPlace()
+	}
+}
+
+func BenchmarkSqrtVariadic(b *testing.B) {
+	x := mpfr.FromFloat64(2)
+	f := mpfr.NewFloat()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		f.Sqrt(x)
+	}
+}
+
+func BenchmarkSqrtInPlace(b *testing.B) {
+	f := mpfr.FromFloat64(2)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		f.SqrtInPlace()
+	}
+}
+
+func TestHypotScratch(t *testing.T) {
+	s := mpfr.NewScratch(100, mpfr.RoundToNearest)
+	x, y := mpfr.FromInt(3), mpfr.FromInt(4)
+	r := mpfr.HypotScratch(x, y, s)
+	if r.GetFloat64() != 5 || r.GetPrec() != 100 {
+		t.Errorf("HypotScratch(3, 4) = %v at %d bits; want 5 at 100 bits", r, r.GetPrec())
+	}
+
+	two := mpfr.FromInt(2)
+	want := mpfr.NewFloatWithPrec(100).SetFloat64(8).Sqrt()
+	if r2 := mpfr.HypotScratch(two, two, s); r2 != r || r2.Cmp(want) != 0 {
+		t.Errorf("second HypotScratch = %v (same storage %v); want %v in the same Float", r2, r2 == r, want)
+	}
+
+	s.RoundingMode = mpfr.RoundUp
+	if up := mpfr.HypotScratch(two, two, s); up.Cmp(want) <= 0 {
+		t.Errorf("HypotScratch with RoundUp = %v; want above %v", up, want)
+	}
+}
+
+func TestAbsDiff(t *testing.T) {
+	for _, tt := range []struct{ x, y, want float64 }{
+		{5, 3, 2},
+		{3, 5, 2},
+		{-1.5, 2, 3.5},
+		{2, 2, 0},
+		{math.Inf(1), 1, math.Inf(1)},
+	} {
+		if got := mpfr.AbsDiff(mpfr.FromFloat64(tt.x), mpfr.FromFloat64(tt.y), mpfr.RoundToNearest).GetFloat64(); got != tt.want {
+			t.Errorf("AbsDiff(%v, %v) = %v; want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+	if got := mpfr.AbsDiff(mpfr.FromFloat64(math.Inf(1)), mpfr.FromFloat64(math.Inf(1)), mpfr.RoundToNearest); !got.IsNaN() {
+		t.Errorf("AbsDiff(+Inf, +Inf) = %v; want NaN", got.GetFloat64())
+	}
+
+	// Rounded once, from the larger operand: 1 - 2^-100 rounded down to 53 bits.
+	x, y := mpfr.FromInt(1), mpfr.Pow2(-100, 53)
+	if got := mpfr.AbsDiff(y, x, mpfr.RoundDown); got.Sign() <= 0 || got.GetFloat64() != 1-0x1p-53 {
+		t.Errorf("AbsDiff(2^-100, 1, RoundDown) = %v; want the 53-bit value below 1", got.GetFloat64())
+	}
+}
+
+func TestLogSumExp(t *testing.T) {
+	xs := mpfr.FromFloat64Slice([]float64{1, 2, 3}, 53)
+	want := math.Log(math.Exp(1) + math.Exp(2) + math.Exp(3))
+	if got := mpfr.LogSumExp(xs, mpfr.RoundToNearest).GetFloat64(); !almostEqual(got, want) {
+		t.Errorf("LogSumExp(1, 2, 3) = %v; want %v", got, want)
+	}
+
+	// exp(1000) overflows float64, but the shifted sum does not.
+	huge := mpfr.FromFloat64Slice([]float64{1000, 1000}, 53)
+	if got, want := mpfr.LogSumExp(huge, mpfr.RoundToNearest).GetFloat64(), 1000+math.Ln2; !almostEqual(got, want) {
+		t.Errorf("LogSumExp(1000, 1000) = %v; want %v", got, want)
+	}
+
+	for _, tt := range []struct {
+		name string
+		xs   []float64
+		want float64
+	}{
+		{"empty", nil, math.Inf(-1)},
+		{"all -Inf", []float64{math.Inf(-1), math.Inf(-1)}, math.Inf(-1)},
+		{"+Inf", []float64{1, math.Inf(1)}, math.Inf(1)},
+		{"-Inf term", []float64{math.Inf(-1), 0}, 0},
+	} {
+		if got := mpfr.LogSumExp(mpfr.FromFloat64Slice(tt.xs, 53), mpfr.RoundToNearest).GetFloat64(); got != tt.want {
+			t.Errorf("LogSumExp(%s) = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+	if got := mpfr.LogSumExp(mpfr.FromFloat64Slice([]float64{1, math.NaN()}, 53), mpfr.RoundToNearest); !got.IsNaN() {
+		t.Errorf("LogSumExp with NaN = %v; want NaN", got.GetFloat64())
+	}
+}
+
+func TestScratchHelpers(t *testing.T) {
+	s := mpfr.NewScratch(100, mpfr.RoundToNearest)
+	x, y := mpfr.FromInt(3), mpfr.FromInt(10)
+	if r := mpfr.AbsDiffScratch(x, y, s); r.GetFloat64() != 7 || r.GetPrec() != 100 {
+		t.Errorf("AbsDiffScratch(3, 10) = %v at %d bits; want 7 at 100 bits", r, r.GetPrec())
+	}
+
+	xs := mpfr.FromFloat64Slice([]float64{-1, 0.5, 2}, 100)
+	defer mpfr.SetResultPrecMode(mpfr.GetResultPrecMode())
+	mpfr.SetResultPrecMode(mpfr.PrecMaxOperand)
+	want := mpfr.LogSumExp(xs, mpfr.RoundToNearest) // at 100 bits, like s
+	if r := mpfr.LogSumExpScratch(xs, s); r.Cmp(want) != 0 || r.GetPrec() != 100 {
+		t.Errorf("LogSumExpScratch = %v at %d bits; want %v", r, r.GetPrec(), want)
+	}
+
+	// Once s has been used, the Scratch variants allocate nothing.
+	if n := testing.AllocsPerRun(100, func() { mpfr.LogSumExpScratch(xs, s) }); n != 0 {
+		t.Errorf("LogSumExpScratch allocates %v times per call; want 0", n)
+	}
+	if n := testing.AllocsPerRun(100, func() { mpfr.AbsDiffScratch(x, y, s) }); n != 0 {
+		t.Errorf("AbsDiffScratch allocates %v times per call; want 0", n)
+	}
+}
+
+func BenchmarkHypot(b *testing.B) {
+	x, y := mpfr.FromInt(3), mpfr.FromInt(4)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		mpfr.Hypot(x, y, mpfr.RoundToNearest)
+	}
+}
+
+func BenchmarkHypotScratch(b *testing.B) {
+	x, y := mpfr.FromInt(3), mpfr.FromInt(4)
+	s := mpfr.NewScratch(53, mpfr.RoundToNearest)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		mpfr.HypotScratch(x, y, s)
+	}
+}
+
+func BenchmarkLogSumExp(b *testing.B) {
+	xs := mpfr.FromFloat64Slice([]float64{-1, 0.5, 2, 3}, 53)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		mpfr.LogSumExp(xs, mpfr.RoundToNearest)
+	}
+}
+
+func BenchmarkLogSumExpScratch(b *testing.B) {
+	xs := mpfr.FromFloat64Slice([]float64{-1, 0.5, 2, 3}, 53)
+	s := mpfr.NewScratch(53, mpfr.RoundToNearest)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		mpfr.LogSumExpScratch(xs, s)
+	}
+}
+
+func TestRandStateReproducible(t *testing.T) {
+	draws := func(s *mpfr.RandState) []string {
+		out := make([]string, 1000)
+		f := mpfr.NewFloatWithPrec(128)
+		for i := range out {
+			out[i] = mpfr.UrandomInto(f, s).StringN(40)
+		}
+		return out
+	}
+
+	a, b := mpfr.NewRandState(42), mpfr.NewRandState(0)
+	b.Seed(42)
+	da, db := draws(a), draws(b)
+	for i := range da {
+		if da[i] != db[i] {
+			t.Fatalf("draw %d differs: %s vs %s", i, da[i], db[i])
+		}
+	}
+
+	c := mpfr.NewRandState(7)
+	c.SeedBytes([]byte{0, 42})
+	if dc := draws(c); dc[0] != da[0] || dc[999] != da[999] {
+		t.Errorf("SeedBytes({0, 42}) sequence differs from Seed(42)")
+	}
+
+	a.Seed(43)
+	if d := draws(a); d[0] == da[0] {
+		t.Errorf("Seed(43) produced the same first draw as Seed(42)")
+	}
+
+	f := mpfr.NewFloat()
+	for i := 0; i < 100; i++ {
+		mpfr.UrandomInto(f, b)
+		if f.Sign() < 0 || f.Cmp(mpfr.FromInt(1)) > 0 {
+			t.Fatalf("UrandomInto = %v; want value in [0, 1]", f)
+		}
+	}
+}
+
+func TestPiCache(t *testing.T) {
+	defer mpfr.ClearConstCache()
+
+	a := mpfr.Pi(256, mpfr.RoundToNearest)
+	b := mpfr.Pi(256, mpfr.RoundToNearest)
+	if a == b {
+		t.Fatal("Pi returned the same *Float twice")
+	}
+	if a.Cmp(b) != 0 || a.GetPrec() != 256 {
+		t.Fatalf("Pi(256) = %v, %v; want equal 256-bit values", a, b)
+	}
+	want := a.StringN(70)
+	if !strings.HasPrefix(want, "3.14159265358979323846264338327950288419716939937510") {
+		t.Errorf("Pi(256) = %s", want)
+	}
+
+	a.SetFloat64(0)
+	if got := mpfr.Pi(256, mpfr.RoundToNearest).StringN(70); got != want {
+		t.Errorf("Pi(256) after mutating an earlier result = %s; want %s", got, want)
+	}
+	if b.StringN(70) != want {
+		t.Errorf("mutating one Pi result changed another: %s", b.StringN(70))
+	}
+
+	down, up := mpfr.Pi(64, mpfr.RoundDown), mpfr.Pi(64, mpfr.RoundUp)
+	if down.Cmp(up) >= 0 {
+		t.Errorf("Pi(64, RoundDown) = %v not below Pi(64, RoundUp) = %v", down, up)
+	}
+
+	mpfr.ClearConstCache()
+	if got := mpfr.Pi(256, mpfr.RoundToNearest).StringN(70); got != want {
+		t.Errorf("Pi(256) after ClearConstCache = %s; want %s", got, want)
+	}
+}
+
+func TestConstDigits(t *testing.T) {
+	defer mpfr.ClearConstCache()
+
+	// The first 100 significant digits of π and e; π's 101st digit is 9, so
+	// rounding to nearest and truncation differ in the last place.
+	const (
+		pi100      = "3.141592653589793238462643383279502884197169399375105820974944592307816406286208998628034825342117068"
+		pi100Trunc = "3.141592653589793238462643383279502884197169399375105820974944592307816406286208998628034825342117067"
+		e100       = "2.718281828459045235360287471352662497757247093699959574966967627724076630353547594571382178525166427"
+	)
+	if got := mpfr.PiDigits(100, mpfr.RoundToNearest).StringN(100); got != pi100 {
+		t.Errorf("PiDigits(100) = %s; want %s", got, pi100)
+	}
+	if got := mpfr.PiDigits(100, mpfr.RoundToward0).StringN(100); got != pi100Trunc {
+		t.Errorf("PiDigits(100, RoundToward0) = %s; want %s", got, pi100Trunc)
+	}
+	if got := mpfr.EDigits(100, mpfr.RoundToNearest).StringN(100); got != e100 {
+		t.Errorf("EDigits(100) = %s; want %s", got, e100)
+	}
+
+	for _, d := range []int{1, 15, 17, 100, 1000} {
+		prec := mpfr.PrecForDigits(d)
+		if got := mpfr.PiDigits(d, mpfr.RoundToNearest).GetPrec(); got <= prec {
+			t.Errorf("PiDigits(%d) precision = %d; want more than PrecForDigits = %d", d, got, prec)
+		}
+		if need := uint(math.Ceil(float64(d) * math.Log2(10))); prec <= need {
+			t.Errorf("PrecForDigits(%d) = %d; want more than %d", d, prec, need)
+		}
+	}
+	if got := mpfr.PrecForDigits(0); got != mpfr.PrecForDigits(1) {
+		t.Errorf("PrecForDigits(0) = %d; want PrecForDigits(1)", got)
+	}
+}
+
+func BenchmarkPi(b *testing.B) {
+	for _, prec := range []uint{256, 4096} {
+		b.Run(fmt.Sprintf("cached/%d", prec), func(b *testing.B) {
+			mpfr.Pi(prec, mpfr.RoundToNearest)
+			for i := 0; i < b.N; i++ {
+				mpfr.Pi(prec, mpfr.RoundToNearest)
+			}
+		})
+		b.Run(fmt.Sprintf("uncached/%d", prec), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				mpfr.ClearConstCache()
+				mpfr.Pi(prec, mpfr.RoundToNearest)
+			}
+		})
+	}
+}
+
+func TestSliceFuncs(t *testing.T) {
+	src := mpfr.FromFloat64Slice([]float64{-1, 0, 0.5, 2, 10}, 100)
+	dst := make([]*mpfr.Float, len(src))
+	for i := range dst {
+		dst[i] = mpfr.NewFloatWithPrec(100)
+	}
+	kept := append([]*mpfr.Float(nil), dst...)
+
+	mpfr.ExpSlice(dst, src, mpfr.RoundToNearest)
+	for i, x := range src {
+		want := mpfr.NewFloatWithPrec(100).Exp(x)
+		if dst[i] != kept[i] {
+			t.Errorf("ExpSlice replaced dst[%d]", i)
+		}
+		if dst[i].Cmp(want) != 0 {
+			t.Errorf("ExpSlice[%d] = %v; want %v", i, dst[i], want)
+		}
+	}
+	if allocs := testing.AllocsPerRun(10, func() { mpfr.ExpSlice(dst, src, mpfr.RoundToNearest) }); allocs != 0 {
+		t.Errorf("ExpSlice into a full dst allocated %v times; want 0", allocs)
+	}
+
+	// nil elements are allocated; src can also be the destination.
+	logs := make([]*mpfr.Float, len(src))
+	mpfr.LogSlice(logs, dst, mpfr.RoundToNearest)
+	for i, x := range src {
+		if logs[i] == nil || !logs[i].EqualULP(x, 1) {
+			t.Errorf("LogSlice(ExpSlice)[%d] = %v; want %v", i, logs[i], x)
+		}
+	}
+	mpfr.PowSlice(dst, dst, mpfr.FromInt(2), mpfr.RoundToNearest)
+	if got, want := dst[3].GetFloat64(), math.Exp(4); math.Abs(got-want) > 1e-12*want {
+		t.Errorf("PowSlice in place: (e²)² = %v; want %v", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("ExpSlice with mismatched lengths did not panic")
+		}
+	}()
+	mpfr.ExpSlice(dst[:2], src, mpfr.RoundToNearest)
+}
+
+func TestBinomial(t *testing.T) {
+	if got := mpfr.Binomial(52, 5, 53, mpfr.RoundToNearest).GetFloat64(); got != 2598960 {
+		t.Errorf("Binomial(52, 5) = %v; want 2598960", got)
+	}
+	if got := mpfr.Binomial(5, 7, 53, mpfr.RoundToNearest); !got.IsZero() {
+		t.Errorf("Binomial(5, 7) = %v; want 0", got)
+	}
+	if got := mpfr.Binomial(9, 0, 53, mpfr.RoundToNearest).GetFloat64(); got != 1 {
+		t.Errorf("Binomial(9, 0) = %v; want 1", got)
+	}
+
+	// C(1000, 500) has 995 bits: exact at 1000 bits, correctly rounded at 53.
+	exact := new(big.Int).Binomial(1000, 500)
+	if got, want := mpfr.Binomial(1000, 500, 1000, mpfr.RoundToNearest), mpfr.NewFloatWithPrec(1000).SetBigInt(exact); got.Cmp(want) != 0 {
+		t.Errorf("Binomial(1000, 500) at 1000 bits = %v; want %v", got, want)
+	}
+	want := mpfr.NewFloatWithPrec(53).SetBigInt(exact)
+	if got := mpfr.Binomial(1000, 500, 53, mpfr.RoundToNearest); got.Cmp(want) != 0 {
+		t.Errorf("Binomial(1000, 500) at 53 bits = %v; want %v", got, want)
+	}
+
+	// Past the exact-integer threshold the ln Γ path must round the same way as
+	// the exact integer would; a large prec forces the exact path for comparison.
+	const n, k = 1 << 21, 1 << 20
+	for _, rnd := range []mpfr.Rnd{mpfr.RoundToNearest, mpfr.RoundUp} {
+		ref := mpfr.Binomial(n, k, 1<<22, rnd)
+		ref.SetRoundMode(rnd)
+		ref.SetPrec(100)
+		if got := mpfr.Binomial(n, k, 100, rnd); got.Cmp(ref) != 0 {
+			t.Errorf("Binomial(2²¹, 2²⁰) rnd %v = %v; want %v", rnd, got.StringN(30), ref.StringN(30))
+		}
+	}
+
+	// Far beyond it, consecutive coefficients still have the exact ratio (n-k)/(k+1).
+	const m, j = 400_000_000, 100_000_000
+	c0 := mpfr.Binomial(m, j, 200, mpfr.RoundToNearest)
+	c1 := mpfr.Binomial(m, j+1, 200, mpfr.RoundToNearest)
+	ratio := mpfr.NewFloatWithPrec(150).Quo(c1, c0)
+	wantRatio := mpfr.NewFloatWithPrec(150).SetFrac64(m-j, j+1)
+	if !ratio.EqualULP(wantRatio, 1) {
+		t.Errorf("C(m, j+1)/C(m, j) = %v; want %v", ratio, wantRatio)
+	}
+}
+
+func TestGeometricMean(t *testing.T) {
+	xs := []*mpfr.Float{mpfr.FromInt(1), mpfr.FromInt(10), mpfr.FromInt(100)}
+	got, err := mpfr.GeometricMean(xs, 53, mpfr.RoundToNearest)
+	if err != nil {
+		t.Fatalf("GeometricMean([1 10 100]) returned error: %v", err)
+	}
+	if got.GetFloat64() != 10 {
+		t.Errorf("GeometricMean([1 10 100]) = %v; want 10", got)
+	}
+
+	// The product 1e-300 · 1e300 · 1e300 · 1e-300 · ... would overflow and
+	// underflow float64 many times over, but the mean of the logs is 0.
+	var wide []*mpfr.Float
+	for i := 0; i < 50; i++ {
+		wide = append(wide, mpfr.NewFloat().SetFloat64(1e300), mpfr.NewFloat().SetFloat64(1e-300))
+	}
+	wide = append(wide, mpfr.NewFloat().SetFloat64(1e300))
+	got, err = mpfr.GeometricMean(wide, 53, mpfr.RoundToNearest)
+	if err != nil {
+		t.Fatalf("GeometricMean(wide) returned error: %v", err)
+	}
+	want := math.Pow(10, 300.0/101)
+	if g := got.GetFloat64(); math.Abs(g-want) > 1e-14*want {
+		t.Errorf("GeometricMean(wide) = %v; want %v", g, want)
+	}
+
+	for _, bad := range [][]*mpfr.Float{
+		nil,
+		{mpfr.FromInt(1), mpfr.FromInt(0)},
+		{mpfr.FromInt(-2), mpfr.FromInt(2)},
+		{mpfr.NewFloat().SetFloat64(math.Inf(1))},
+		{mpfr.NewFloat().SetFloat64(math.NaN())},
+	} {
+		if _, err := mpfr.GeometricMean(bad, 53, mpfr.RoundToNearest); !errors.Is(err, mpfr.ErrDomain) {
+			t.Errorf("GeometricMean(%v) error = %v; want ErrDomain", bad, err)
+		}
+	}
+}
+
+func TestSoftmax(t *testing.T) {
+	zeros := []*mpfr.Float{mpfr.FromInt(0), mpfr.FromInt(0), mpfr.FromInt(0)}
+	for i, p := range mpfr.Softmax(zeros, 53, mpfr.RoundToNearest) {
+		if got := p.GetFloat64(); got != 1.0/3 {
+			t.Errorf("Softmax([0 0 0])[%d] = %v; want 1/3", i, got)
+		}
+	}
+
+	// exp(1000) overflows float64; after subtracting the maximum nothing does.
+	ps := mpfr.Softmax([]*mpfr.Float{mpfr.FromInt(1000), mpfr.FromInt(0)}, 53, mpfr.RoundToNearest)
+	sum := mpfr.NewFloatWithPrec(53)
+	for i, p := range ps {
+		if p.IsNaN() || p.IsInf() {
+			t.Fatalf("Softmax([1000 0])[%d] = %v", i, p.CompactString())
+		}
+		sum.Add(p)
+	}
+	if got := sum.GetFloat64(); got != 1 {
+		t.Errorf("sum of Softmax([1000 0]) = %v; want 1", got)
+	}
+	tiny := mpfr.Exp(mpfr.FromInt(-1000), mpfr.RoundToNearest)
+	if ps[1].Sign() <= 0 || !ps[1].EqualULP(tiny, 1) {
+		t.Errorf("Softmax([1000 0])[1] = %v; want about e^-1000", ps[1].CompactString())
+	}
+
+	vs := []float64{-1.5, 0.25, 2, 3}
+	var xs []*mpfr.Float
+	var total float64
+	for _, v := range vs {
+		xs = append(xs, mpfr.FromFloat64(v))
+		total += math.Exp(v)
+	}
+	for i, p := range mpfr.Softmax(xs, 53, mpfr.RoundToNearest) {
+		if got, want := p.GetFloat64(), math.Exp(vs[i])/total; !almostEqual(got, want) {
+			t.Errorf("Softmax(%v)[%d] = %v; want %v", vs, i, got, want)
+		}
+	}
+
+	withInf := []*mpfr.Float{mpfr.FromFloat64(math.Inf(-1)), mpfr.FromInt(2)}
+	ps = mpfr.Softmax(withInf, 53, mpfr.RoundToNearest)
+	if ps[0].GetFloat64() != 0 || ps[1].GetFloat64() != 1 {
+		t.Errorf("Softmax([-Inf 2]) = [%v %v]; want [0 1]", ps[0].GetFloat64(), ps[1].GetFloat64())
+	}
+	for i, p := range mpfr.Softmax([]*mpfr.Float{mpfr.FromInt(1), mpfr.FromFloat64(math.NaN())}, 53, mpfr.RoundToNearest) {
+		if !p.IsNaN() {
+			t.Errorf("Softmax([1 NaN])[%d] = %v; want NaN", i, p.GetFloat64())
+		}
+	}
+	if got := mpfr.Softmax(nil, 53, mpfr.RoundToNearest); len(got) != 0 {
+		t.Errorf("Softmax(nil) has length %d; want 0", len(got))
+	}
+}
+
+func TestTrigDegrees(t *testing.T) {
+	deg := func(v float64) *mpfr.Float { return mpfr.NewFloat().SetFloat64(v) }
+
+	if got := mpfr.NewFloat().SinDeg(deg(30)).GetFloat64(); got != 0.5 {
+		t.Errorf("SinDeg(30) = %v; want 0.5", got)
+	}
+	if got := mpfr.NewFloat().CosDeg(deg(60)).GetFloat64(); got != 0.5 {
+		t.Errorf("CosDeg(60) = %v; want 0.5", got)
+	}
+	if got := mpfr.NewFloat().CosDeg(deg(90)).GetFloat64(); math.Abs(got) > 1e-30 {
+		t.Errorf("CosDeg(90) = %v; want ≈ 0", got)
+	}
+	if got := mpfr.NewFloat().TanDeg(deg(45)).GetFloat64(); got != 1 {
+		t.Errorf("TanDeg(45) = %v; want 1", got)
+	}
+	// Reduction modulo 360 is exact, so a huge angle behaves like its remainder.
+	if got := mpfr.NewFloat().SinDeg(deg(360<<40 + 30)).GetFloat64(); got != 0.5 {
+		t.Errorf("SinDeg(360·2⁴⁰ + 30) = %v; want 0.5", got)
+	}
+
+	if got := mpfr.NewFloat().AsinDeg(deg(0.5)).GetFloat64(); got != 30 {
+		t.Errorf("AsinDeg(0.5) = %v; want 30", got)
+	}
+	if got := mpfr.NewFloat().AcosDeg(deg(-1)).GetFloat64(); got != 180 {
+		t.Errorf("AcosDeg(-1) = %v; want 180", got)
+	}
+	if got := mpfr.NewFloat().AtanDeg(deg(1)).GetFloat64(); got != 45 {
+		t.Errorf("AtanDeg(1) = %v; want 45", got)
+	}
+	if got := mpfr.NewFloat().AtanDeg(deg(math.Inf(-1))).GetFloat64(); got != -90 {
+		t.Errorf("AtanDeg(-Inf) = %v; want -90", got)
+	}
+}
+
+func TestSinCosPi(t *testing.T) {
+	x := func(v float64) *mpfr.Float { return mpfr.NewFloat().SetFloat64(v) }
+
+	if got := mpfr.NewFloat().SinPi(x(1)); !got.IsPosZero() {
+		t.Errorf("SinPi(1) = %v; want +0", got)
+	}
+	if got := mpfr.NewFloat().SinPi(x(-2)); !got.IsNegZero() {
+		t.Errorf("SinPi(-2) = %v; want -0", got)
+	}
+	if got := mpfr.NewFloat().CosPi(x(0.5)); !got.IsPosZero() {
+		t.Errorf("CosPi(0.5) = %v; want +0", got)
+	}
+	if got := mpfr.NewFloat().SinPi(x(0.5)).GetFloat64(); got != 1 {
+		t.Errorf("SinPi(0.5) = %v; want 1", got)
+	}
+	if got := mpfr.CosPi(x(1), mpfr.RoundToNearest).GetFloat64(); got != -1 {
+		t.Errorf("CosPi(1) = %v; want -1", got)
+	}
+	// The naive route rounds π first and misses the zero.
+	halfPi := mpfr.Pi(53, mpfr.RoundToNearest)
+	halfPi.Quo(halfPi, mpfr.FromInt(2))
+	if naive := mpfr.NewFloat().Cos(halfPi); naive.IsZero() {
+		t.Errorf("Cos(π/2) = 0; expected a rounding residue")
+	}
+
+	// sin(π/6) = 1/2, up to the rounding of 1/6 itself.
+	sixth := mpfr.NewFloatWithPrec(200).SetFrac64(1, 6)
+	got := mpfr.NewFloatWithPrec(200).SinPi(sixth)
+	if want := mpfr.NewFloatWithPrec(200).SetFloat64(0.5); !got.EqualULP(want, 1) {
+		t.Errorf("SinPi(1/6) = %v; want 0.5", got)
+	}
+	// Reduction modulo 2 is exact, so a huge argument behaves like its remainder.
+	if got := mpfr.NewFloat().SinPi(x(1 << 60)).GetFloat64(); got != 0 {
+		t.Errorf("SinPi(2⁶⁰) = %v; want 0", got)
+	}
+	big := mpfr.NewFloatWithPrec(80).SetFloat64(1 << 60)
+	big.Add(mpfr.NewFloat().SetFloat64(0.5))
+	if got := mpfr.SinPi(big, mpfr.RoundToNearest).GetFloat64(); got != 1 {
+		t.Errorf("SinPi(2⁶⁰ + 0.5) = %v; want 1", got)
+	}
+
+	if got := mpfr.NewFloat().CosPi(x(math.Inf(1))); !got.IsNaN() {
+		t.Errorf("CosPi(+Inf) = %v; want NaN", got)
+	}
+}
+
+func TestRelError(t *testing.T) {
+	// 22/7 approximates π with relative error ≈ 4.0250e-4.
+	approx := mpfr.NewFloatWithPrec(200).SetFrac64(22, 7)
+	pi := mpfr.Pi(200, mpfr.RoundToNearest)
+	got := approx.RelError(pi, 64)
+	if got.GetPrec() != 64 {
+		t.Errorf("RelError precision = %d; want 64", got.GetPrec())
+	}
+	if g := got.GetFloat64(); math.Abs(g-4.0249943477068e-4) > 1e-15 {
+		t.Errorf("RelError(22/7, π) = %v; want ≈ 4.0249943477068e-4", g)
+	}
+
+	// The error is anchored to the reference: swapping operands changes it.
+	swapped := pi.RelError(approx, 64).GetFloat64()
+	if swapped == got.GetFloat64() {
+		t.Errorf("RelError is symmetric: %v", swapped)
+	}
+
+	// A zero reference falls back to absolute error.
+	x := mpfr.NewFloat().SetFloat64(-1e-20)
+	if g := x.RelError(mpfr.FromInt(0), 53).GetFloat64(); g != 1e-20 {
+		t.Errorf("RelError(-1e-20, 0) = %v; want 1e-20", g)
+	}
+	if g := pi.RelError(pi, 53); !g.IsZero() {
+		t.Errorf("RelError(π, π) = %v; want 0", g)
+	}
+}
+
+func TestCorrectBits(t *testing.T) {
+	exact := mpfr.NewFloatWithPrec(53).SetFloat64(1.5)
+	approx := mpfr.NewFloatWithPrec(53).SetFloat64(1.5)
+	approx.NextAbove()
+	// One ULP of 1.5 is 2^-52, a relative error of 2^-52/1.5.
+	if got := mpfr.CorrectBits(approx, exact); got != 52 {
+		t.Errorf("CorrectBits(1.5 + 1 ULP, 1.5) = %d; want 52", got)
+	}
+	// Rounding modes do not enter into it.
+	approx.SetRoundMode(mpfr.RoundUp)
+	exact.SetRoundMode(mpfr.RoundDown)
+	if got := mpfr.CorrectBits(approx, exact); got != 52 {
+		t.Errorf("CorrectBits with directed rounding modes = %d; want 52", got)
+	}
+
+	tests := []struct {
+		name          string
+		approx, exact float64
+		want          int
+	}{
+		{"equal", 3, 3, math.MaxInt},
+		{"power-of-two error", 1 + 1.0/1024, 1, 10},
+		{"off by 2x", 2, 1, 0},
+		{"exact zero", 1.0 / 1024, 0, 10},
+		{"NaN", math.NaN(), 1, 0},
+		{"Inf", math.Inf(1), 1, 0},
+		{"both Inf", math.Inf(-1), math.Inf(-1), math.MaxInt},
+	}
+	for _, tc := range tests {
+		if got := mpfr.CorrectBits(mpfr.FromFloat64(tc.approx), mpfr.FromFloat64(tc.exact)); got != tc.want {
+			t.Errorf("CorrectBits %s = %d; want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestHasTLS(t *testing.T) {
+	// The answer depends on how MPFR was built; just make sure the query works
+	// and is stable.
+	if mpfr.HasTLS() != mpfr.HasTLS() {
+		t.Error("HasTLS returned different answers")
+	}
+	t.Logf("MPFR built with thread-local storage: %v", mpfr.HasTLS())
+}
+
+func TestSolveQuadratic(t *testing.T) {
+	// x² + 1e8·x + 1 = 0 has roots ≈ -1e8 and ≈ -1e-8. In float64 the textbook
+	// formula computes the small root as a difference of two nearly equal numbers.
+	a, b, c := 1.0, 1e8, 1.0
+	naive := (-b + math.Sqrt(b*b-4*a*c)) / (2 * a)
+
+	ref := mpfr.NewFloatWithPrec(500).SetFloat64(b * b)
+	ref.Sub(mpfr.FromInt(4)).Sqrt()
+	ref.Sub(mpfr.FromFloat64(b)).Half()
+	want := ref.GetFloat64()
+
+	x1, x2, real := mpfr.SolveQuadratic(mpfr.FromFloat64(a), mpfr.FromFloat64(b), mpfr.FromFloat64(c), 53, mpfr.RoundToNearest)
+	if !real {
+		t.Fatal("SolveQuadratic reported complex roots for a positive discriminant")
+	}
+	if got := x2.GetFloat64(); got != want {
+		t.Errorf("small root = %v; want %v", got, want)
+	}
+	if math.Abs(naive-want) < 1e-3*math.Abs(want) {
+		t.Errorf("naive small root %v is unexpectedly accurate", naive)
+	}
+	if got := x1.GetFloat64(); math.Abs(got+1e8) > 1e-7 {
+		t.Errorf("large root = %v; want ≈ -1e8", got)
+	}
+
+	// (x - 2)(x - 3) = x² - 5x + 6.
+	x1, x2, real = mpfr.SolveQuadratic(mpfr.FromInt(1), mpfr.FromInt(-5), mpfr.FromInt(6), 53, mpfr.RoundToNearest)
+	if !real || x1.GetFloat64() != 2 || x2.GetFloat64() != 3 {
+		t.Errorf("SolveQuadratic(1, -5, 6) = %v, %v, %v; want 2, 3, true", x1, x2, real)
+	}
+
+	if x1, x2, real = mpfr.SolveQuadratic(mpfr.FromInt(1), mpfr.FromInt(0), mpfr.FromInt(1), 53, mpfr.RoundToNearest); real || x1 != nil || x2 != nil {
+		t.Errorf("SolveQuadratic(1, 0, 1) = %v, %v, %v; want nil, nil, false", x1, x2, real)
+	}
+
+	x1, x2, real = mpfr.SolveQuadratic(mpfr.FromInt(0), mpfr.FromInt(2), mpfr.FromInt(-3), 53, mpfr.RoundToNearest)
+	if !real || x1.GetFloat64() != 1.5 || x2.GetFloat64() != 1.5 {
+		t.Errorf("SolveQuadratic(0, 2, -3) = %v, %v, %v; want 1.5, 1.5, true", x1, x2, real)
+	}
+}
+
+func TestMeanAccumulator(t *testing.T) {
+	const n = 1000000
+	acc := mpfr.NewMeanAccumulator(53)
+	if !acc.Mean().IsNaN() {
+		t.Errorf("Mean of no values = %v; want NaN", acc.Mean())
+	}
+
+	x := mpfr.NewFloat().SetFloat64(0.1)
+	var sum float64
+	for i := 0; i < n; i++ {
+		acc.Add(x)
+		sum += 0.1
+	}
+	if acc.Count() != n {
+		t.Errorf("Count() = %d; want %d", acc.Count(), n)
+	}
+	if got := acc.Mean().GetFloat64(); got != 0.1 {
+		t.Errorf("Mean() = %v; want 0.1", got)
+	}
+	if sum/n == 0.1 {
+		t.Errorf("float64 running mean did not drift; test is not exercising anything")
+	}
+}
+
+func TestMeanAccumulatorVariance(t *testing.T) {
+	acc := mpfr.NewMeanAccumulator(53)
+	for _, v := range []int{2, 4, 4, 4, 5, 5, 7, 9} {
+		acc.Add(mpfr.FromInt(v))
+		if acc.Count() == 1 && !acc.Variance().IsNaN() {
+			t.Errorf("Variance of one value = %v; want NaN", acc.Variance())
+		}
+	}
+	// Population variance 4, so sample variance 4·8/7.
+	if got, want := acc.Variance().GetFloat64(), 32.0/7; got != want {
+		t.Errorf("Variance() = %v; want %v", got, want)
+	}
+	if got, want := acc.StdDev().GetFloat64(), math.Sqrt(32.0/7); got != want {
+		t.Errorf("StdDev() = %v; want %v", got, want)
+	}
+
+	// Values near 1e9 with unit spread: Σx² ≈ 3e18 has an ulp of 512 in float64,
+	// so the naive sum-of-squares formula cannot see a variance of 1.
+	xs := []float64{1e9 + 1, 1e9 + 2, 1e9 + 3}
+	acc = mpfr.NewMeanAccumulator(53)
+	var sum, sumSq float64
+	for _, v := range xs {
+		acc.Add(mpfr.FromFloat64(v))
+		sum += v
+		sumSq += v * v
+	}
+	naive := (sumSq - sum*sum/3) / 2
+	if got := acc.Variance().GetFloat64(); got != 1 {
+		t.Errorf("Variance(1e9+{1,2,3}) = %v; want 1", got)
+	}
+	if naive == 1 {
+		t.Errorf("naive float64 variance is exact; test is not exercising anything")
+	}
+}
+
+func TestLogProductAccumulator(t *testing.T) {
+	empty := mpfr.NewLogProductAccumulator(53)
+	if got := empty.Result().GetFloat64(); got != 1 {
+		t.Errorf("empty Result() = %v; want 1", got)
+	}
+
+	const n = 10000
+	p := mpfr.NewLogProductAccumulator(53)
+	x := mpfr.MustParse("0.01", 53)
+	naive := 1.0
+	for i := 0; i < n; i++ {
+		p.MulValue(x)
+		naive *= 0.01
+	}
+	if naive != 0 {
+		t.Fatalf("float64 product = %v; expected it to underflow", naive)
+	}
+	if p.Count() != n {
+		t.Errorf("Count() = %d; want %d", p.Count(), n)
+	}
+
+	// ln(x^n) = n·ln x, computed here with plenty of extra precision.
+	want := mpfr.NewFloatWithPrec(200).Log(x)
+	want.Mul(mpfr.FromInt(n))
+	want.SetPrec(53)
+	if got := p.ResultLog(); !got.EqualULP(want, 1) {
+		t.Errorf("ResultLog() = %v; want %v", got, want)
+	}
+	// x is 0.01 rounded to 53 bits, so the product is within n ulps of 1e-20000.
+	prod := p.Result()
+	if rel := prod.RelError(mpfr.MustParse("1e-20000", 200), 64).GetFloat64(); rel > 1e-11 {
+		t.Errorf("Result() = %v; relative error %v from 1e-20000", prod, rel)
+	}
+
+	p.MulValue(mpfr.FromInt(0))
+	if got := p.ResultLog(); !got.IsInf() || got.Sign() > 0 {
+		t.Errorf("ResultLog() after a zero factor = %v; want -Inf", got)
+	}
+	if got := p.Result(); !got.IsZero() {
+		t.Errorf("Result() after a zero factor = %v; want 0", got)
+	}
+}
+
+func TestAccumulatorExact(t *testing.T) {
+	acc := mpfr.NewAccumulator(53)
+	if !acc.Exact() || acc.Result().Sign() != 0 {
+		t.Fatalf("empty Accumulator: Exact = %v, Result = %v", acc.Exact(), acc.Result().GetFloat64())
+	}
+	pairs := [][2]float64{{1, 2}, {0.5, 0.25}, {-3, 4}, {1e10, 1e10}}
+	for _, p := range pairs {
+		if tern := acc.AddProductTernary(mpfr.FromFloat64(p[0]), mpfr.FromFloat64(p[1])); tern != 0 {
+			t.Errorf("AddProductTernary(%v, %v) = %d; want 0", p[0], p[1], tern)
+		}
+	}
+	if !acc.Exact() {
+		t.Error("Exact() = false after exactly representable products")
+	}
+	if got := acc.Result().GetFloat64(); got != 1e20-9.875 {
+		t.Errorf("Result() = %v; want %v", got, 1e20-9.875)
+	}
+
+	// 1/3 · 2^-200 is exact on its own, but the sum would need bits from 2^66 down to
+	// below 2^-250, far more than the working precision holds.
+	if tern := acc.AddProductTernary(mpfr.FromFloat64(1.0/3), mpfr.Pow2(-200, 53)); tern == 0 {
+		t.Error("AddProductTernary(1/3, 2^-200) = 0; want a rounding")
+	}
+	if acc.Exact() {
+		t.Error("Exact() = true after an inexact step")
+	}
+	acc.AddProductTernary(mpfr.FromInt(1), mpfr.FromInt(1))
+	if acc.Exact() {
+		t.Error("Exact() = true again after a later exact step")
+	}
+}
+
+func addOperands() []*mpfr.Float {
+	xs := make([]*mpfr.Float, 100000)
+	for i := range xs {
+		xs[i] = mpfr.NewFloat().SetFloat64(float64(i))
+	}
+	return xs
+}
+
+func BenchmarkAddVariadic(b *testing.B) {
+	xs := addOperands()
+	f := mpfr.NewFloat()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Add(xs...)
+	}
+}
+
+func BenchmarkAddAll(b *testing.B) {
+	xs := addOperands()
+	f := mpfr.NewFloat()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.AddAll(xs)
+	}
+}
+
+func mulOperands() []*mpfr.Float {
+	xs := make([]*mpfr.Float, 100000)
+	for i := range xs {
+		xs[i] = mpfr.NewFloat().SetFloat64(1 + 1/float64(i+1))
+	}
+	return xs
+}
+
+func BenchmarkMulVariadic(b *testing.B) {
+	xs := mulOperands()
+	f := mpfr.NewFloat()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.SetFloat64(1).Mul(xs...)
+	}
+}
+
+func BenchmarkMulAll(b *testing.B) {
+	xs := mulOperands()
+	f := mpfr.NewFloat()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.SetFloat64(1).MulAll(xs)
+	}
+}
+
+func TestBytesBERoundTrip(t *testing.T) {
+	values := []*mpfr.Float{
+		mpfr.FromInt(1),
+		mpfr.FromInt(-3),
+		mpfr.FromFloat64(0.1),
+		mpfr.FromFloat64(-1e300),
+		mpfr.FromFloat64(5e-324), // float64 subnormal
+		mpfr.FromFloat64(-2.2250738585072014e-308),
+		mpfr.Pi(300, mpfr.RoundToNearest),
+		mpfr.NewFloatWithPrec(7).SetFloat64(96),
+		mpfr.NewFloat().SetZero(-1),
+		mpfr.NewFloat().SetZero(1),
+		mpfr.NewFloat().SetFloat64(math.Inf(-1)),
+		mpfr.NewFloat().SetFloat64(math.NaN()),
+	}
+	for _, x := range values {
+		b := x.GetBytesBE()
+		y := mpfr.NewFloat()
+		if err := y.SetBytesBE(b); err != nil {
+			t.Errorf("SetBytesBE(GetBytesBE(%v)) returned error: %v", x, err)
+			continue
+		}
+		if wantPrec := (x.GetPrec() + 7) / 8 * 8; y.GetPrec() != wantPrec {
+			t.Errorf("round trip of %v: precision %d; want %d", x, y.GetPrec(), wantPrec)
+		}
+		switch {
+		case x.IsNaN():
+			if !y.IsNaN() {
+				t.Errorf("round trip of NaN = %v", y)
+			}
+		case x.Cmp(y) != 0 || x.Signbit() != y.Signbit():
+			t.Errorf("round trip of %v = %v", x, y)
+		}
+	}
+
+	// 1 encodes as: positive finite, exponent 1, significand 0x80 followed by zeros.
+	if got := mpfr.NewFloatWithPrec(8).SetFloat64(1).GetBytesBE(); string(got) != "\x02\x02\x80" {
+		t.Errorf("GetBytesBE(1) = % x; want 02 02 80", got)
+	}
+
+	for _, bad := range [][]byte{nil, {0x02}, {0x02, 0x02}, {0x02, 0x02, 0x40}, {0x08, 0x00, 0x00}, {0x04, 0x02, 0x00}} {
+		f := mpfr.FromInt(42)
+		if err := f.SetBytesBE(bad); !errors.Is(err, mpfr.ErrInvalidBytes) {
+			t.Errorf("SetBytesBE(% x) error = %v; want ErrInvalidBytes", bad, err)
+		}
+		if f.GetFloat64() != 42 {
+			t.Errorf("SetBytesBE(% x) modified f to %v", bad, f)
+		}
+	}
+
+	// Exponents beyond 32 bits round-trip while the exponent range allows them and
+	// are rejected with ErrRange once it no longer does.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	var b []byte
+	const e = 1 << 40
+	func() {
+		defer mpfr.SaveState().Restore()
+		if err := mpfr.SetEmax(e + 1); err != nil {
+			t.Skipf("SetEmax(2⁴⁰+1): %v", err)
+		}
+		x := mpfr.NewFloat().SetInt64(-3)
+		x.Scalbn(x, e-1)
+		b = x.GetBytesBE()
+		y := mpfr.NewFloat()
+		if err := y.SetBytesBE(b); err != nil {
+			t.Fatalf("SetBytesBE(-3·2^(2⁴⁰-1)) returned error: %v", err)
+		}
+		if y.Cmp(x) != 0 || !y.IsRegular() {
+			t.Errorf("round trip of -3·2^(2⁴⁰-1) = %v", y)
+		}
+	}()
+	f := mpfr.FromInt(42)
+	if err := f.SetBytesBE(b); !errors.Is(err, mpfr.ErrRange) {
+		t.Errorf("SetBytesBE beyond emax error = %v; want ErrRange", err)
+	}
+	if f.GetFloat64() != 42 {
+		t.Errorf("SetBytesBE beyond emax modified f to %v", f)
+	}
+}
+
+func TestIntegrate(t *testing.T) {
+	const prec = 128
+	tol := mpfr.NewFloatWithPrec(prec).SetFloat64(1e-16)
+
+	sin := func(x *mpfr.Float) *mpfr.Float {
+		// sin(x) = cos(x - π/2)
+		y := mpfr.NewFloatWithPrec(prec).Copy(x)
+		y.Sub(mpfr.Pi(prec, mpfr.RoundToNearest).Half())
+		return y.Cos()
+	}
+	got := mpfr.Integrate(sin, mpfr.FromInt(0), mpfr.Pi(prec, mpfr.RoundToNearest), prec, tol, mpfr.RoundToNearest)
+	if err := got.RelError(mpfr.FromInt(2), 64).GetFloat64(); err > 1e-16 {
+		t.Errorf("∫₀^π sin = %v; relative error %g", got, err)
+	}
+
+	recip := func(x *mpfr.Float) *mpfr.Float {
+		y := mpfr.NewFloatWithPrec(prec).SetFloat64(1)
+		return y.Div(x)
+	}
+	e := mpfr.NewFloatWithPrec(prec).Exp(mpfr.FromInt(1))
+	got, err := mpfr.IntegrateErr(recip, mpfr.FromInt(1), e, prec, tol, mpfr.RoundToNearest)
+	if err != nil {
+		t.Fatalf("IntegrateErr ∫₁^e 1/x returned error: %v", err)
+	}
+	if err := got.RelError(mpfr.FromInt(1), 64).GetFloat64(); err > 1e-16 {
+		t.Errorf("∫₁^e 1/x = %v; relative error %g", got, err)
+	}
+
+	// A tol far below what 53-bit arithmetic can resolve is never met; the
+	// evaluation budget stops the refinement instead of recursing ~2^50 times.
+	cube := func(x *mpfr.Float) *mpfr.Float {
+		y := mpfr.NewFloatWithPrec(53).Copy(x)
+		return y.Mul(x).Mul(x).Exp()
+	}
+	impossible := mpfr.NewFloat().SetPow2(-1000)
+	start := time.Now()
+	got, err = mpfr.IntegrateErr(cube, mpfr.FromInt(0), mpfr.FromInt(1), 53, impossible, mpfr.RoundToNearest)
+	if !errors.Is(err, mpfr.ErrNoConvergence) {
+		t.Errorf("IntegrateErr with tol 2^-1000 at 53 bits: error = %v; want ErrNoConvergence", err)
+	}
+	if plain := mpfr.Integrate(cube, mpfr.FromInt(0), mpfr.FromInt(1), 53, impossible, mpfr.RoundToNearest); plain.Cmp(got) != 0 {
+		t.Errorf("Integrate with tol 2^-1000 = %v; want IntegrateErr's estimate %v", plain, got)
+	}
+	// The budget runs out while the left half is being refined, so the estimate is
+	// only as good as the coarser subintervals on the right.
+	if got == nil || math.Abs(got.GetFloat64()-1.3419044179774489) > 1e-5 {
+		t.Errorf("Integrate with tol 2^-1000 = %v; want the estimate ≈ 1.3419044", got)
+	}
+	if d := time.Since(start); d > 30*time.Second {
+		t.Errorf("Integrate with an impossible tol took %v", d)
+	}
+}
+
+func TestBisect(t *testing.T) {
+	const prec = 200
+	f := func(x *mpfr.Float) *mpfr.Float {
+		y := mpfr.NewFloatWithPrec(prec).Copy(x)
+		return y.Mul(x).Sub(mpfr.FromInt(2))
+	}
+	root, err := mpfr.Bisect(f, mpfr.FromInt(1), mpfr.FromInt(2), prec, 1000, mpfr.RoundToNearest)
+	if err != nil {
+		t.Fatalf("Bisect(x²-2, [1, 2]) returned error: %v", err)
+	}
+	sqrt2 := mpfr.NewFloatWithPrec(prec).SetFloat64(2).Sqrt()
+	if !root.EqualULP(sqrt2, 2) {
+		t.Errorf("Bisect(x²-2, [1, 2]) = %v; want %v", root, sqrt2)
+	}
+
+	// Few iterations still bracket the root to within (hi-lo)/2^k.
+	rough, _ := mpfr.Bisect(f, mpfr.FromInt(1), mpfr.FromInt(2), prec, 10, mpfr.RoundToNearest)
+	if d := rough.Sub(sqrt2).GetFloat64(); math.Abs(d) > math.Ldexp(1, -10) {
+		t.Errorf("10 iterations: error %v exceeds 2^-10", d)
+	}
+
+	if _, err := mpfr.Bisect(f, mpfr.FromInt(2), mpfr.FromInt(3), prec, 100, mpfr.RoundToNearest); !errors.Is(err, mpfr.ErrNotBracketed) {
+		t.Errorf("Bisect(x²-2, [2, 3]) error = %v; want ErrNotBracketed", err)
+	}
+	if root, err := mpfr.Bisect(f, mpfr.FromInt(-1), mpfr.FromInt(0), prec, 100, mpfr.RoundToNearest); !errors.Is(err, mpfr.ErrNotBracketed) {
+		t.Errorf("Bisect(x²-2, [-1, 0]) = %v, %v; want ErrNotBracketed", root, err)
+	}
+}
+
+func TestFixedPoint(t *testing.T) {
+	// x ← cos x converges to the Dottie number, the root of cos x = x.
+	cos := func(x *mpfr.Float) *mpfr.Float { return mpfr.NewFloatWithPrec(100).Cos(x) }
+	tol := mpfr.NewFloat().SetPow2(-90)
+	x, iters, err := mpfr.FixedPoint(cos, mpfr.FromInt(1), 100, 1000, tol, mpfr.RoundToNearest)
+	if err != nil {
+		t.Fatalf("FixedPoint(cos) error: %v", err)
+	}
+	if got := x.GetFloat64(); math.Abs(got-0.7390851332151607) > 1e-15 {
+		t.Errorf("FixedPoint(cos) = %v; want ≈ 0.7390851332151607", got)
+	}
+	if x.GetPrec() != 100 || iters < 2 || iters >= 1000 {
+		t.Errorf("FixedPoint(cos) took %d iterations at %d bits", iters, x.GetPrec())
+	}
+	residual := mpfr.NewFloatWithPrec(100).Cos(x)
+	residual.Sub(x)
+	if math.Abs(residual.GetFloat64()) > 1e-26 {
+		t.Errorf("cos(x) - x = %v at the fixed point", residual.GetFloat64())
+	}
+
+	// x ← 2x + 1 moves away from its fixed point -1.
+	double := func(x *mpfr.Float) *mpfr.Float {
+		return mpfr.NewFloatWithPrec(100).Fma(x, mpfr.FromInt(2), mpfr.FromInt(1))
+	}
+	_, iters, err = mpfr.FixedPoint(double, mpfr.FromInt(0), 100, 50, tol, mpfr.RoundToNearest)
+	if !errors.Is(err, mpfr.ErrNoConvergence) || iters != 50 {
+		t.Errorf("FixedPoint(2x+1) = %d iterations, %v; want 50, ErrNoConvergence", iters, err)
+	}
+
+	nan := func(x *mpfr.Float) *mpfr.Float { return mpfr.NewFloat().Sqrt(mpfr.FromInt(-1)) }
+	if _, iters, err := mpfr.FixedPoint(nan, mpfr.FromInt(0), 100, 50, tol, mpfr.RoundToNearest); !errors.Is(err, mpfr.ErrNoConvergence) || iters != 1 {
+		t.Errorf("FixedPoint(NaN) = %d iterations, %v; want 1, ErrNoConvergence", iters, err)
+	}
+}
+
+func TestNumericalDeriv(t *testing.T) {
+	const prec = 100
+	// sin x written as cos(π/2 - x), evaluated at the precision of its argument.
+	sin := func(x *mpfr.Float) *mpfr.Float {
+		p := x.GetPrec()
+		y := mpfr.NewFloatWithPrec(p).Copy(mpfr.Pi(p, mpfr.RoundToNearest))
+		y.Half().Sub(x)
+		return y.Cos()
+	}
+	cube := func(x *mpfr.Float) *mpfr.Float {
+		y := mpfr.NewFloatWithPrec(x.GetPrec()).Copy(x)
+		return y.Mul(x).Mul(x)
+	}
+	relErr := func(got, want *mpfr.Float) float64 {
+		return got.RelError(want, prec).GetFloat64()
+	}
+
+	one := mpfr.FromInt(1)
+	if got := mpfr.NumericalDeriv(sin, mpfr.FromInt(0), prec, mpfr.RoundToNearest); relErr(got, one) > 1e-27 {
+		t.Errorf("d/dx sin x at 0 = %v; want 1", got.GetFloat64())
+	}
+	twelve := mpfr.FromInt(12)
+	if got := mpfr.NumericalDeriv(cube, mpfr.FromInt(2), prec, mpfr.RoundToNearest); got.GetPrec() != prec || relErr(got, twelve) > 1e-27 {
+		t.Errorf("d/dx x³ at 2 = %v; want 12", got.GetFloat64())
+	}
+
+	// Large arguments scale the step: d/dx x³ at 1e6 is 3e12.
+	want := mpfr.NewFloatWithPrec(prec).SetFloat64(3e12)
+	if got := mpfr.NumericalDeriv(cube, mpfr.FromFloat64(1e6), prec, mpfr.RoundToNearest); relErr(got, want) > 1e-25 {
+		t.Errorf("d/dx x³ at 1e6 = %v; want 3e12", got.GetFloat64())
+	}
+
+	// d/dx e^x at 1 is e.
+	exp := func(x *mpfr.Float) *mpfr.Float { return mpfr.NewFloatWithPrec(x.GetPrec()).Exp(x) }
+	e := mpfr.NewFloatWithPrec(prec).Exp(one)
+	if got := mpfr.NumericalDeriv(exp, one, prec, mpfr.RoundToNearest); relErr(got, e) > 1e-27 {
+		t.Errorf("d/dx e^x at 1 = %v; want e", got.GetFloat64())
+	}
+
+	if got := mpfr.NumericalDeriv(cube, mpfr.FromFloat64(math.NaN()), prec, mpfr.RoundToNearest); !got.IsNaN() {
+		t.Errorf("NumericalDeriv at NaN = %v; want NaN", got.GetFloat64())
+	}
+}
+
+func TestContinuedFraction(t *testing.T) {
+	const prec = 64
+	ones := func(n int) []*mpfr.Float {
+		xs := make([]*mpfr.Float, n)
+		for i := range xs {
+			xs[i] = mpfr.FromInt(1)
+		}
+		return xs
+	}
+
+	// [1; 1, 1, 1, …] converges to the golden ratio (1 + √5)/2.
+	phi := mpfr.ContinuedFraction(ones(100), ones(101), prec, mpfr.RoundToNearest)
+	want := mpfr.NewFloatWithPrec(prec).SetFloat64(5).Sqrt()
+	want.Add(mpfr.FromInt(1)).Half()
+	if !phi.EqualULP(want, 1) {
+		t.Errorf("[1; 1, 1, …] = %v; want %v", phi, want)
+	}
+
+	// e = [2; 1, 2, 1, 1, 4, 1, 1, 6, …].
+	b := []*mpfr.Float{mpfr.FromInt(2)}
+	for k := 1; len(b) < 40; k++ {
+		b = append(b, mpfr.FromInt(1), mpfr.FromInt(2*k), mpfr.FromInt(1))
+	}
+	e := mpfr.ContinuedFraction(ones(len(b)-1), b, prec, mpfr.RoundToNearest)
+	if want := mpfr.NewFloatWithPrec(prec).Exp(mpfr.FromInt(1)); !e.EqualULP(want, 1) {
+		t.Errorf("continued fraction for e = %v; want %v", e, want)
+	}
+
+	// A generalized fraction: 4/π = 1 + 1²/(3 + 2²/(5 + 3²/(7 + …))).
+	var ga, gb []*mpfr.Float
+	gb = append(gb, mpfr.FromInt(1))
+	for k := 1; k <= 60; k++ {
+		ga = append(ga, mpfr.FromInt(k*k))
+		gb = append(gb, mpfr.FromInt(2*k+1))
+	}
+	fourOverPi := mpfr.ContinuedFraction(ga, gb, prec, mpfr.RoundToNearest)
+	wantPi := mpfr.NewFloatWithPrec(prec).SetFloat64(4).Div(mpfr.Pi(prec+10, mpfr.RoundToNearest))
+	if !fourOverPi.EqualULP(wantPi, 2) {
+		t.Errorf("continued fraction for 4/π = %v; want %v", fourOverPi, wantPi)
+	}
+}
+
+func TestClenshawChebyshev(t *testing.T) {
+	const prec = 200
+	const wp = prec + 64
+
+	// exp(x) = I₀(1) + 2·Σ Iₖ(1)·Tₖ(x), with the modified Bessel function
+	// Iₖ(1) = Σₘ 1/(2^(2m+k)·m!·(m+k)!).
+	besselI1 := func(k int) *mpfr.Float {
+		term := mpfr.NewFloatWithPrec(wp).SetFloat64(1)
+		for j := 1; j <= k; j++ {
+			term.Div(mpfr.FromInt(2 * j))
+		}
+		sum := mpfr.NewFloatWithPrec(wp).Copy(term)
+		for m := 1; m < 60; m++ {
+			term.Div(mpfr.FromInt(4*m), mpfr.FromInt(m+k))
+			sum.Add(term)
+		}
+		return sum
+	}
+	coeffs := make([]*mpfr.Float, 45)
+	for k := range coeffs {
+		coeffs[k] = besselI1(k)
+		if k > 0 {
+			coeffs[k].Mul(mpfr.FromInt(2))
+		}
+	}
+
+	for _, v := range []float64{-1, -0.3, 0, 0.5, 0.999} {
+		x := mpfr.FromFloat64(v)
+		got := mpfr.ClenshawChebyshev(coeffs, x, prec, mpfr.RoundToNearest)
+		want := mpfr.NewFloatWithPrec(prec).Exp(x)
+		if !got.EqualULP(want, 4) {
+			t.Errorf("Chebyshev series for exp(%v) = %v; want %v", v, got, want)
+		}
+	}
+
+	if got := mpfr.ClenshawChebyshev(nil, mpfr.FromInt(1), prec, mpfr.RoundToNearest); !got.IsZero() {
+		t.Errorf("empty series = %v; want 0", got)
+	}
+	// T₀ + 2T₁ + 3T₂ at x = 0.5 is 1 + 1 + 3·(2·0.25 - 1) = 0.5.
+	small := []*mpfr.Float{mpfr.FromInt(1), mpfr.FromInt(2), mpfr.FromInt(3)}
+	if got := mpfr.ClenshawChebyshev(small, mpfr.FromFloat64(0.5), 53, mpfr.RoundToNearest); got.GetFloat64() != 0.5 {
+		t.Errorf("T0 + 2T1 + 3T2 at 0.5 = %v; want 0.5", got)
+	}
+}
+
+func TestPolyEvalWithDeriv(t *testing.T) {
+	// 2x² + 3x + 1, lowest degree first.
+	coeffs := []*mpfr.Float{mpfr.FromInt(1), mpfr.FromInt(3), mpfr.FromInt(2)}
+	v, d := mpfr.PolyEvalWithDeriv(coeffs, mpfr.FromInt(10), 53, mpfr.RoundToNearest)
+	if v.GetFloat64() != 231 || d.GetFloat64() != 43 {
+		t.Errorf("2x²+3x+1 at 10 = (%v, %v); want (231, 43)", v, d)
+	}
+
+	v, d = mpfr.PolyEvalWithDeriv(nil, mpfr.FromInt(10), 53, mpfr.RoundToNearest)
+	if !v.IsZero() || !d.IsZero() {
+		t.Errorf("empty polynomial = (%v, %v); want (0, 0)", v, d)
+	}
+
+	// Newton's method on x³ - 2 converges to the cube root of 2.
+	cubic := []*mpfr.Float{mpfr.FromInt(-2), mpfr.FromInt(0), mpfr.FromInt(0), mpfr.FromInt(1)}
+	x := mpfr.NewFloatWithPrec(200).SetFloat64(1)
+	for i := 0; i < 10; i++ {
+		v, d := mpfr.PolyEvalWithDeriv(cubic, x, 200, mpfr.RoundToNearest)
+		x.Sub(mpfr.NewFloatWithPrec(200).Quo(v, d))
+	}
+	if want := mpfr.NewFloatWithPrec(200).Cbrt(mpfr.FromInt(2)); !x.EqualULP(want, 2) {
+		t.Errorf("Newton root of x³-2 = %v; want %v", x, want)
+	}
+}
+
+func TestFloat128(t *testing.T) {
+	if !mpfr.HasFloat128() {
+		if _, _, err := mpfr.FromInt(1).GetFloat128(); !errors.Is(err, mpfr.ErrUnsupported) {
+			t.Errorf("GetFloat128 without binary128 support: err = %v; want ErrUnsupported", err)
+		}
+		if _, err := mpfr.NewFloat().SetFloat128(0x3fff000000000000, 0); !errors.Is(err, mpfr.ErrUnsupported) {
+			t.Errorf("SetFloat128 without binary128 support: err = %v; want ErrUnsupported", err)
+		}
+		t.Skip("MPFR built without binary128 support")
+	}
+
+	pow2 := func(e int) *mpfr.Float { return mpfr.Compose(false, big.NewInt(1), e, 113) }
+	maxSub := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 112), big.NewInt(1))
+
+	tests := []struct {
+		name   string
+		x      *mpfr.Float
+		hi, lo uint64
+	}{
+		{"1", mpfr.FromInt(1), 0x3fff000000000000, 0},
+		{"-2", mpfr.FromInt(-2), 0xc000000000000000, 0},
+		{"pi", mpfr.Pi(200, mpfr.RoundToNearest), 0x4000921fb54442d1, 0x8469898cc51701b8},
+		{"1/3", mpfr.NewFloatWithPrec(200).SetFrac64(1, 3), 0x3ffd555555555555, 0x5555555555555555},
+		{"-0", mpfr.NewFloat().SetZero(-1), 0x8000000000000000, 0},
+		{"+Inf", mpfr.NewFloat().SetFloat64(math.Inf(1)), 0x7fff000000000000, 0},
+		{"min subnormal", pow2(-16494), 0, 1},
+		{"max subnormal", mpfr.Compose(false, maxSub, -16494, 113), 0x0000ffffffffffff, 0xffffffffffffffff},
+		{"min normal", pow2(-16382), 0x0001000000000000, 0},
+		{"overflow", pow2(16384), 0x7fff000000000000, 0},
+		{"underflow", pow2(-16496), 0, 0},
+	}
+	for _, tt := range tests {
+		hi, lo, err := tt.x.GetFloat128()
+		if err != nil {
+			t.Fatalf("GetFloat128(%s): %v", tt.name, err)
+		}
+		if hi != tt.hi || lo != tt.lo {
+			t.Errorf("GetFloat128(%s) = %016x %016x; want %016x %016x", tt.name, hi, lo, tt.hi, tt.lo)
+		}
+		if tt.name == "overflow" || tt.name == "underflow" {
+			continue
+		}
+		back, err := mpfr.NewFloatWithPrec(113).SetFloat128(hi, lo)
+		if err != nil {
+			t.Fatalf("SetFloat128(%s): %v", tt.name, err)
+		}
+		want := mpfr.NewFloat().SetPrecRnd(tt.x, 113, mpfr.RoundToNearest)
+		if back.Cmp(want) != 0 || back.Signbit() != want.Signbit() {
+			t.Errorf("SetFloat128(GetFloat128(%s)) = %v; want %v", tt.name, back, want)
+		}
+	}
+
+	nan := mpfr.NewFloat().SetFloat64(math.NaN())
+	if hi, _, _ := nan.GetFloat128(); hi&0x7fff800000000000 != 0x7fff800000000000 {
+		t.Errorf("GetFloat128(NaN) = %016x; want a quiet NaN", hi)
+	}
+	if g, _ := mpfr.NewFloat().SetFloat128(0x7fff000000000000, 1); !g.IsNaN() {
+		t.Error("SetFloat128 of a signaling NaN pattern is not NaN")
+	}
+
+	// Directed rounding decides between Inf and the largest finite value.
+	huge := pow2(16384)
+	huge.SetRoundMode(mpfr.RoundToward0)
+	if hi, lo, _ := huge.GetFloat128(); hi != 0x7ffeffffffffffff || lo != 0xffffffffffffffff {
+		t.Errorf("GetFloat128(2^16384, RoundToward0) = %016x %016x; want the largest finite value", hi, lo)
+	}
+}
+
+func TestDecimal64(t *testing.T) {
+	if !mpfr.HasDecimal64() {
+		if _, err := mpfr.FromInt(1).GetDecimal64(); !errors.Is(err, mpfr.ErrUnsupported) {
+			t.Errorf("GetDecimal64 without decimal float support: err = %v; want ErrUnsupported", err)
+		}
+		if _, err := mpfr.NewFloat().SetDecimal64(0); !errors.Is(err, mpfr.ErrUnsupported) {
+			t.Errorf("SetDecimal64 without decimal float support: err = %v; want ErrUnsupported", err)
+		}
+		t.Skip("MPFR built without decimal float support")
+	}
+
+	for _, s := range []string{"1", "-2.5", "0.1", "123456789012345e-300", "9.999999999999999e384"} {
+		x := mpfr.MustParse(s, 200)
+		d, err := x.GetDecimal64()
+		if err != nil {
+			t.Fatalf("GetDecimal64(%s): %v", s, err)
+		}
+		back, err := mpfr.NewFloatWithPrec(200).SetDecimal64(d)
+		if err != nil {
+			t.Fatalf("SetDecimal64(%s): %v", s, err)
+		}
+		// Each input has at most 16 significant digits, so it survives decimal64
+		// unchanged and only the binary rounding at 200 bits remains.
+		if back.Cmp(x) != 0 {
+			t.Errorf("SetDecimal64(GetDecimal64(%s)) = %v", s, back)
+		}
+	}
+
+	if runtime.GOARCH == "amd64" {
+		// BID encoding of coefficient 1 with biased exponent 397 (10^-1). GetDecimal64
+		// may pick any member of a value's cohort, so only decoding is pinned down.
+		tenth, _ := mpfr.NewFloatWithPrec(53).SetDecimal64(0x31a0000000000001)
+		if got := tenth.GetFloat64(); got != 0.1 {
+			t.Errorf("SetDecimal64(0.1) = %v; want 0.1", got)
+		}
+	}
+}